@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"test/api/models"
 	"test/pkg/jwt"
+	"test/service"
 	"time"
 )
 
@@ -20,6 +21,7 @@ import (
 // @Param        login body models.UserLoginRequest true "login"
 // @Success      201  {object}  models.UserLoginResponse
 // @Failure      400  {object}  models.Response
+// @Failure      403  {object}  models.Response
 // @Failure      404  {object}  models.Response
 // @Failure      500  {object}  models.Response
 func (h Handler) UserLogin(c *gin.Context) {
@@ -35,7 +37,11 @@ func (h Handler) UserLogin(c *gin.Context) {
 
 	loginResponse, err := h.services.AuthService().UserLogin(ctx, userLogin)
 	if err != nil {
-		handleResponse(c, h.log, "error while admin login", http.StatusBadRequest, err.Error())
+		status := http.StatusBadRequest
+		if errors.Is(err, service.ErrForbidden) {
+			status = http.StatusForbidden
+		}
+		handleResponse(c, h.log, "error while admin login", status, err.Error())
 		return
 	}
 
@@ -58,3 +64,14 @@ func getAuthInfo(c *gin.Context) (models.AuthInfo, error) {
 		UserRole: m["user_role"].(string),
 	}, nil
 }
+
+// getUserID returns the id of the user authenticated by authenticateMiddleware.
+func getUserID(c *gin.Context) (string, bool) {
+	userID, ok := c.Get("user_id")
+	if !ok {
+		return "", false
+	}
+
+	id, ok := userID.(string)
+	return id, ok && id != ""
+}
@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"test/api/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// BlockUser godoc
+// @Router       /user/{id}/block [POST]
+// @Summary      Block user
+// @Description  Block a user as the authenticated user, removing follow relationships both ways
+// @Tags         block
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id path string true "user_id"
+// @Success      200  {object}  models.Block
+// @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) BlockUser(c *gin.Context) {
+	block, ok := h.getCreateBlock(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	resp, err := h.services.Blocks().Block(ctx, block)
+	if err != nil {
+		handleResponse(c, h.log, "error while blocking user", errorStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "", http.StatusOK, resp)
+}
+
+// UnblockUser godoc
+// @Router       /user/{id}/block [DELETE]
+// @Summary      Unblock user
+// @Description  Unblock a user as the authenticated user, unblocking twice has no effect
+// @Tags         block
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id path string true "user_id"
+// @Success      200  {object}  models.Response
+// @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) UnblockUser(c *gin.Context) {
+	block, ok := h.getCreateBlock(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	if err := h.services.Blocks().Unblock(ctx, block); err != nil {
+		handleResponse(c, h.log, "error while unblocking user", errorStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "", http.StatusOK, "user successfully unblocked")
+}
+
+// GetBlockList godoc
+// @Router       /blocks [GET]
+// @Summary      Get blocked users
+// @Description  Get a paginated list of users blocked by the authenticated user
+// @Tags         block
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        page query string false "page"
+// @Param        limit query string false "limit"
+// @Success      200  {object}  models.UserSummariesResponse
+// @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) GetBlockList(c *gin.Context) {
+	request, ok := h.getOwnListRequest(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	resp, err := h.services.Blocks().GetList(ctx, request)
+	if err != nil {
+		handleResponse(c, h.log, "error while getting blocked users", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "", http.StatusOK, resp)
+}
+
+func (h Handler) getCreateBlock(c *gin.Context) (models.CreateBlock, bool) {
+	userID, ok := getUserID(c)
+	if !ok {
+		handleResponse(c, h.log, "unauthorized", http.StatusUnauthorized, "user not authenticated")
+		return models.CreateBlock{}, false
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		handleResponse(c, h.log, "invalid uuid type", http.StatusBadRequest, err.Error())
+		return models.CreateBlock{}, false
+	}
+
+	return models.CreateBlock{
+		UserID:        userID,
+		BlockedUserID: id.String(),
+	}, true
+}
@@ -0,0 +1,319 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"test/api/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// BookmarkTweet godoc
+// @Router       /tweet/{id}/bookmark [PUT]
+// @Summary      Bookmark tweet
+// @Description  Privately save a tweet, optionally into a folder, bookmarking again moves the tweet to the folder
+// @Tags         bookmark
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id path string true "tweet_id"
+// @Param        bookmark body models.CreateBookmark false "bookmark"
+// @Success      200  {object}  models.Bookmark
+// @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) BookmarkTweet(c *gin.Context) {
+	bookmark := models.CreateBookmark{}
+	if err := c.ShouldBindJSON(&bookmark); err != nil && !errors.Is(err, io.EOF) {
+		handleResponse(c, h.log, "error while reading body from client", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	userID, id, ok := h.getAuthorizedID(c)
+	if !ok {
+		return
+	}
+
+	if bookmark.FolderID != nil {
+		if _, err := uuid.Parse(*bookmark.FolderID); err != nil {
+			handleResponse(c, h.log, "invalid uuid type", http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	bookmark.UserID = userID
+	bookmark.TweetID = id
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	resp, err := h.services.Bookmarks().Bookmark(ctx, bookmark)
+	if err != nil {
+		handleResponse(c, h.log, "error while bookmarking tweet", errorStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "", http.StatusOK, resp)
+}
+
+// UnbookmarkTweet godoc
+// @Router       /tweet/{id}/bookmark [DELETE]
+// @Summary      Remove bookmark
+// @Description  Remove a tweet from the authenticated user's bookmarks, removing twice has no effect
+// @Tags         bookmark
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id path string true "tweet_id"
+// @Success      200  {object}  models.Response
+// @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) UnbookmarkTweet(c *gin.Context) {
+	userID, id, ok := h.getAuthorizedID(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	if err := h.services.Bookmarks().Unbookmark(ctx, models.CreateBookmark{UserID: userID, TweetID: id}); err != nil {
+		handleResponse(c, h.log, "error while removing bookmark", errorStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "", http.StatusOK, "bookmark successfully removed")
+}
+
+// GetBookmarks godoc
+// @Router       /bookmarks [GET]
+// @Summary      Get bookmarks
+// @Description  Get the authenticated user's bookmarks, most recent first, pass next_cursor as cursor for the next page
+// @Tags         bookmark
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        cursor query string false "cursor"
+// @Param        limit query string false "limit"
+// @Param        folder_id query string false "folder_id"
+// @Success      200  {object}  models.BookmarksResponse
+// @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) GetBookmarks(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		handleResponse(c, h.log, "unauthorized", http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit < 1 || limit > 100 {
+		handleResponse(c, h.log, "invalid limit", http.StatusBadRequest, "limit must be between 1 and 100")
+		return
+	}
+
+	folderID := c.Query("folder_id")
+	if folderID != "" {
+		if _, err = uuid.Parse(folderID); err != nil {
+			handleResponse(c, h.log, "invalid uuid type", http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	resp, err := h.services.Bookmarks().GetList(ctx, models.GetBookmarksRequest{
+		UserID:   userID,
+		FolderID: folderID,
+		Limit:    limit,
+	}, c.Query("cursor"))
+	if err != nil {
+		handleResponse(c, h.log, "error while getting bookmarks", errorStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "", http.StatusOK, resp)
+}
+
+// CreateBookmarkFolder godoc
+// @Router       /bookmarks/folders [POST]
+// @Summary      Create bookmark folder
+// @Description  Create a named bookmark folder after the user's other folders
+// @Tags         bookmark
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        folder body models.CreateBookmarkFolder true "folder"
+// @Success      200  {object}  models.BookmarkFolder
+// @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) CreateBookmarkFolder(c *gin.Context) {
+	folder := models.CreateBookmarkFolder{}
+	if err := c.ShouldBindJSON(&folder); err != nil {
+		handleResponse(c, h.log, "error while reading body from client", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		handleResponse(c, h.log, "unauthorized", http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+	folder.UserID = userID
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	resp, err := h.services.Bookmarks().CreateFolder(ctx, folder)
+	if err != nil {
+		handleResponse(c, h.log, "error while creating bookmark folder", errorStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "", http.StatusOK, resp)
+}
+
+// GetBookmarkFolders godoc
+// @Router       /bookmarks/folders [GET]
+// @Summary      Get bookmark folders
+// @Description  Get the authenticated user's bookmark folders in order
+// @Tags         bookmark
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Success      200  {object}  models.BookmarkFoldersResponse
+// @Failure      401  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) GetBookmarkFolders(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		handleResponse(c, h.log, "unauthorized", http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	resp, err := h.services.Bookmarks().GetFolders(ctx, userID)
+	if err != nil {
+		handleResponse(c, h.log, "error while getting bookmark folders", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "", http.StatusOK, resp)
+}
+
+// RenameBookmarkFolder godoc
+// @Router       /bookmarks/folder/{id} [PUT]
+// @Summary      Rename bookmark folder
+// @Description  Rename a bookmark folder of the authenticated user
+// @Tags         bookmark
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id path string true "folder_id"
+// @Param        folder body models.UpdateBookmarkFolder true "folder"
+// @Success      200  {object}  models.BookmarkFolder
+// @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) RenameBookmarkFolder(c *gin.Context) {
+	folder := models.UpdateBookmarkFolder{}
+	if err := c.ShouldBindJSON(&folder); err != nil {
+		handleResponse(c, h.log, "error while reading body from client", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	userID, id, ok := h.getAuthorizedID(c)
+	if !ok {
+		return
+	}
+
+	folder.UserID = userID
+	folder.FolderID = id
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	resp, err := h.services.Bookmarks().RenameFolder(ctx, folder)
+	if err != nil {
+		handleResponse(c, h.log, "error while renaming bookmark folder", errorStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "", http.StatusOK, resp)
+}
+
+// DeleteBookmarkFolder godoc
+// @Router       /bookmarks/folder/{id} [DELETE]
+// @Summary      Delete bookmark folder
+// @Description  Delete a bookmark folder, its bookmarks are kept outside of any folder
+// @Tags         bookmark
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id path string true "folder_id"
+// @Success      200  {object}  models.Response
+// @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) DeleteBookmarkFolder(c *gin.Context) {
+	userID, id, ok := h.getAuthorizedID(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	if err := h.services.Bookmarks().DeleteFolder(ctx, models.PrimaryKey{ID: id}, userID); err != nil {
+		handleResponse(c, h.log, "error while deleting bookmark folder", errorStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "", http.StatusOK, "bookmark folder successfully deleted")
+}
+
+// ReorderBookmarkFolders godoc
+// @Router       /bookmarks/folders/order [PUT]
+// @Summary      Reorder bookmark folders
+// @Description  Put the authenticated user's bookmark folders in the given order, every folder must be listed once
+// @Tags         bookmark
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        order body models.ReorderBookmarkFolders true "order"
+// @Success      200  {object}  models.BookmarkFoldersResponse
+// @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) ReorderBookmarkFolders(c *gin.Context) {
+	request := models.ReorderBookmarkFolders{}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		handleResponse(c, h.log, "error while reading body from client", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		handleResponse(c, h.log, "unauthorized", http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+	request.UserID = userID
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	resp, err := h.services.Bookmarks().ReorderFolders(ctx, request)
+	if err != nil {
+		handleResponse(c, h.log, "error while reordering bookmark folders", errorStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "", http.StatusOK, resp)
+}
@@ -0,0 +1,230 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"test/api/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateDraft godoc
+// @Router       /draft [POST]
+// @Summary      Create draft
+// @Description  Save a tweet as a draft only the authenticated user sees. With publish_at, within the coming year, the draft is scheduled and published then
+// @Tags         draft
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        draft body models.CreateDraft true "draft"
+// @Success      201  {object}  models.Draft
+// @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) CreateDraft(c *gin.Context) {
+	draft := models.CreateDraft{}
+	if err := c.ShouldBindJSON(&draft); err != nil {
+		handleResponse(c, h.log, "error while reading body from client", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		handleResponse(c, h.log, "unauthorized", http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+	draft.UserID = userID
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	resp, err := h.services.Drafts().Create(ctx, draft)
+	if err != nil {
+		handleResponse(c, h.log, "error while creating draft", errorStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "draft saved successfully", http.StatusCreated, resp)
+}
+
+// GetDraft godoc
+// @Router       /draft/{id} [GET]
+// @Summary      Get draft
+// @Description  Get a draft of the authenticated user
+// @Tags         draft
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id path string true "draft_id"
+// @Success      200  {object}  models.Draft
+// @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) GetDraft(c *gin.Context) {
+	userID, id, ok := h.getAuthorizedID(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	resp, err := h.services.Drafts().Get(ctx, models.PrimaryKey{ID: id}, userID)
+	if err != nil {
+		handleResponse(c, h.log, "error while getting draft", errorStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "", http.StatusOK, resp)
+}
+
+// GetDrafts godoc
+// @Router       /drafts [GET]
+// @Summary      Get drafts
+// @Description  Get a paginated list of the drafts of the authenticated user. scheduled=true lists the scheduled posts in the order they are published, scheduled=false the other drafts
+// @Tags         draft
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        scheduled query bool false "scheduled"
+// @Param        page query string false "page"
+// @Param        limit query string false "limit"
+// @Success      200  {object}  models.DraftsResponse
+// @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) GetDrafts(c *gin.Context) {
+	request, ok := h.getOwnListRequest(c)
+	if !ok {
+		return
+	}
+
+	var scheduled *bool
+	if value := c.Query("scheduled"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			handleResponse(c, h.log, "error while parsing scheduled", http.StatusBadRequest, err.Error())
+			return
+		}
+		scheduled = &parsed
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	resp, err := h.services.Drafts().GetList(ctx, models.GetDraftsRequest{
+		Page:      request.Page,
+		Limit:     request.Limit,
+		UserID:    request.UserID,
+		Scheduled: scheduled,
+	})
+	if err != nil {
+		handleResponse(c, h.log, "error while getting drafts", errorStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "", http.StatusOK, resp)
+}
+
+// UpdateDraft godoc
+// @Router       /draft/{id} [PUT]
+// @Summary      Update draft
+// @Description  Replace a draft of the authenticated user. Setting publish_at schedules or reschedules it, leaving it out unschedules it. Drafts being published can no longer be edited
+// @Tags         draft
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id path string true "draft_id"
+// @Param        draft body models.UpdateDraft true "draft"
+// @Success      200  {object}  models.Draft
+// @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) UpdateDraft(c *gin.Context) {
+	draft := models.UpdateDraft{}
+	if err := c.ShouldBindJSON(&draft); err != nil {
+		handleResponse(c, h.log, "error while reading body from client", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	userID, id, ok := h.getAuthorizedID(c)
+	if !ok {
+		return
+	}
+	draft.ID = id
+	draft.UserID = userID
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	resp, err := h.services.Drafts().Update(ctx, draft)
+	if err != nil {
+		handleResponse(c, h.log, "error while updating draft", errorStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "draft updated successfully", http.StatusOK, resp)
+}
+
+// DeleteDraft godoc
+// @Router       /draft/{id} [DELETE]
+// @Summary      Delete draft
+// @Description  Delete a draft of the authenticated user, cancelling it if scheduled. Drafts being published can no longer be cancelled
+// @Tags         draft
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id path string true "draft_id"
+// @Success      200  {object}  models.Response
+// @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) DeleteDraft(c *gin.Context) {
+	userID, id, ok := h.getAuthorizedID(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	if err := h.services.Drafts().Delete(ctx, models.PrimaryKey{ID: id}, userID); err != nil {
+		handleResponse(c, h.log, "error while deleting draft", errorStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "", http.StatusOK, "draft successfully deleted")
+}
+
+// PublishDraft godoc
+// @Router       /draft/{id}/publish [POST]
+// @Summary      Publish draft
+// @Description  Publish a draft of the authenticated user right away, scheduled or not. A draft that cannot be published is kept as failed with the reason
+// @Tags         draft
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id path string true "draft_id"
+// @Success      201  {object}  models.Tweet
+// @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      403  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) PublishDraft(c *gin.Context) {
+	userID, id, ok := h.getAuthorizedID(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+	resp, err := h.services.Drafts().Publish(ctx, models.PrimaryKey{ID: id}, userID)
+	if err != nil {
+		handleResponse(c, h.log, "error while publishing draft", errorStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "draft published successfully", http.StatusCreated, resp)
+}
@@ -0,0 +1,205 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"test/api/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// GetIncomingFollowRequests godoc
+// @Router       /follow-requests/incoming [GET]
+// @Summary      Get incoming follow requests
+// @Description  Get pending requests to follow the authenticated user
+// @Tags         follow-request
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        page query string false "page"
+// @Param        limit query string false "limit"
+// @Success      200  {object}  models.FollowRequestsResponse
+// @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) GetIncomingFollowRequests(c *gin.Context) {
+	request, ok := h.getOwnListRequest(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	resp, err := h.services.Followers().GetIncomingRequests(ctx, request)
+	if err != nil {
+		handleResponse(c, h.log, "error while getting incoming follow requests", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "", http.StatusOK, resp)
+}
+
+// GetOutgoingFollowRequests godoc
+// @Router       /follow-requests/outgoing [GET]
+// @Summary      Get outgoing follow requests
+// @Description  Get pending follow requests sent by the authenticated user
+// @Tags         follow-request
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        page query string false "page"
+// @Param        limit query string false "limit"
+// @Success      200  {object}  models.FollowRequestsResponse
+// @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) GetOutgoingFollowRequests(c *gin.Context) {
+	request, ok := h.getOwnListRequest(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	resp, err := h.services.Followers().GetOutgoingRequests(ctx, request)
+	if err != nil {
+		handleResponse(c, h.log, "error while getting outgoing follow requests", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "", http.StatusOK, resp)
+}
+
+// AcceptFollowRequest godoc
+// @Router       /follow-request/{id}/accept [POST]
+// @Summary      Accept follow request
+// @Description  Accept a pending request to follow the authenticated user
+// @Tags         follow-request
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id path string true "follow_request_id"
+// @Success      200  {object}  models.Response
+// @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      403  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) AcceptFollowRequest(c *gin.Context) {
+	userID, key, ok := h.getFollowRequestKey(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	if err := h.services.Followers().AcceptRequest(ctx, key, userID); err != nil {
+		handleResponse(c, h.log, "error while accepting follow request", errorStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "", http.StatusOK, "follow request successfully accepted")
+}
+
+// RejectFollowRequest godoc
+// @Router       /follow-request/{id}/reject [POST]
+// @Summary      Reject follow request
+// @Description  Reject a pending request to follow the authenticated user
+// @Tags         follow-request
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id path string true "follow_request_id"
+// @Success      200  {object}  models.Response
+// @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      403  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) RejectFollowRequest(c *gin.Context) {
+	userID, key, ok := h.getFollowRequestKey(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	if err := h.services.Followers().RejectRequest(ctx, key, userID); err != nil {
+		handleResponse(c, h.log, "error while rejecting follow request", errorStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "", http.StatusOK, "follow request successfully rejected")
+}
+
+// CancelFollowRequest godoc
+// @Router       /follow-request/{id} [DELETE]
+// @Summary      Cancel follow request
+// @Description  Cancel a pending follow request sent by the authenticated user
+// @Tags         follow-request
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id path string true "follow_request_id"
+// @Success      200  {object}  models.Response
+// @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      403  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) CancelFollowRequest(c *gin.Context) {
+	userID, key, ok := h.getFollowRequestKey(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	if err := h.services.Followers().CancelRequest(ctx, key, userID); err != nil {
+		handleResponse(c, h.log, "error while cancelling follow request", errorStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "", http.StatusOK, "follow request successfully cancelled")
+}
+
+// getOwnListRequest reads the pagination of a listing that belongs to the
+// authenticated user, responding with 401 or 400 when it cannot.
+func (h Handler) getOwnListRequest(c *gin.Context) (models.GetListRequest, bool) {
+	userID, ok := getUserID(c)
+	if !ok {
+		handleResponse(c, h.log, "unauthorized", http.StatusUnauthorized, "user not authenticated")
+		return models.GetListRequest{}, false
+	}
+
+	page, limit, err := getPageAndLimit(c)
+	if err != nil {
+		handleResponse(c, h.log, "error while parsing page or limit", http.StatusBadRequest, err.Error())
+		return models.GetListRequest{}, false
+	}
+
+	return models.GetListRequest{
+		Page:     page,
+		Limit:    limit,
+		UserID:   userID,
+		ViewerID: userID,
+	}, true
+}
+
+func (h Handler) getFollowRequestKey(c *gin.Context) (string, models.PrimaryKey, bool) {
+	userID, ok := getUserID(c)
+	if !ok {
+		handleResponse(c, h.log, "unauthorized", http.StatusUnauthorized, "user not authenticated")
+		return "", models.PrimaryKey{}, false
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		handleResponse(c, h.log, "invalid uuid type", http.StatusBadRequest, err.Error())
+		return "", models.PrimaryKey{}, false
+	}
+
+	return userID, models.PrimaryKey{ID: id.String()}, true
+}
@@ -2,23 +2,27 @@ package handler
 
 import (
 	"context"
-	"github.com/gin-gonic/gin"
 	"net/http"
 	"test/api/models"
-	"strconv"
 	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 // CreateFollower godoc
 // @Router       /follower [POST]
 // @Summary      Creates a new follower relationship
-// @Description  Create a new follower relationship between two users
+// @Description  Follow a user as the authenticated user, or file a pending follow request when the followed account is protected
 // @Tags         follower
 // @Accept       json
 // @Produce      json
+// @Security     ApiKeyAuth
 // @Param        follower body models.CreateFollower true "follower"
-// @Success      201  {object}  models.Follower
+// @Success      201  {object}  models.CreateFollowerResponse
 // @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      403  {object}  models.Response
 // @Failure      500  {object}  models.Response
 func (h Handler) CreateFollower(c *gin.Context) {
 	var createFollower models.CreateFollower
@@ -28,109 +32,246 @@ func (h Handler) CreateFollower(c *gin.Context) {
 		return
 	}
 
+	userID, ok := getUserID(c)
+	if !ok {
+		handleResponse(c, h.log, "unauthorized", http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+	createFollower.FollowerUserID = userID
+
+	if _, err := uuid.Parse(createFollower.UserID); err != nil {
+		handleResponse(c, h.log, "invalid uuid type", http.StatusBadRequest, err.Error())
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
 	defer cancel()
 	resp, err := h.services.Followers().Create(ctx, createFollower)
 	if err != nil {
-		handleResponse(c, h.log, "error while creating follower relationship", http.StatusInternalServerError, err.Error())
+		handleResponse(c, h.log, "error while creating follower relationship", errorStatus(err), err.Error())
 		return
 	}
 
 	handleResponse(c, h.log, "", http.StatusCreated, resp)
 }
 
-// GetFollower godoc
-// @Router       /follower/{id} [GET]
-// @Summary      Get follower by ID
-// @Description  Get a follower relationship by its ID
+// DeleteFollower godoc
+// @Router       /follower/{id} [DELETE]
+// @Summary      Delete follower relationship
+// @Description  Delete a follower relationship of the authenticated user by ID, unfollowing the followed user or removing a follower
 // @Tags         follower
 // @Accept       json
 // @Produce      json
+// @Security     ApiKeyAuth
 // @Param        id path string true "follower_id"
-// @Success      200  {object}  models.Follower
+// @Success      200  {object}  models.Response
 // @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      403  {object}  models.Response
 // @Failure      404  {object}  models.Response
 // @Failure      500  {object}  models.Response
-func (h Handler) GetFollower(c *gin.Context) {
-	uid := c.Param("id")
+func (h Handler) DeleteFollower(c *gin.Context) {
+	userID, id, ok := h.getAuthorizedID(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	if err := h.services.Followers().Delete(ctx, models.PrimaryKey{ID: id}, userID); err != nil {
+		handleResponse(c, h.log, "error while deleting follower relationship", errorStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "", http.StatusOK, "follower relationship successfully deleted")
+}
+
+// UnfollowUser godoc
+// @Router       /user/{id}/follow [DELETE]
+// @Summary      Unfollow user
+// @Description  Stop following a user as the authenticated user, unfollowing twice has no effect
+// @Tags         follower
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id path string true "user_id"
+// @Success      200  {object}  models.Response
+// @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) UnfollowUser(c *gin.Context) {
+	userID, id, ok := h.getAuthorizedID(c)
+	if !ok {
+		return
+	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
 	defer cancel()
-	resp, err := h.services.Followers().Get(ctx, uid)
+	if err := h.services.Followers().Unfollow(ctx, models.CreateFollower{
+		UserID:         id,
+		FollowerUserID: userID,
+	}); err != nil {
+		handleResponse(c, h.log, "error while unfollowing user", errorStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "", http.StatusOK, "user successfully unfollowed")
+}
+
+// GetUserFollowers godoc
+// @Router       /user/{id}/followers [GET]
+// @Summary      Get followers of user
+// @Description  Get a paginated list of users following a user. Protected accounts only list them to their followers, and nothing is listed across a block
+// @Tags         follower
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "user_id"
+// @Param        page query string false "page"
+// @Param        limit query string false "limit"
+// @Success      200  {object}  models.UserSummariesResponse
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) GetUserFollowers(c *gin.Context) {
+	request, ok := h.getUserListRequest(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	resp, err := h.services.Followers().GetFollowers(ctx, request)
 	if err != nil {
-		handleResponse(c, h.log, "error while getting follower by id", http.StatusInternalServerError, err.Error())
+		handleResponse(c, h.log, "error while getting followers", errorStatus(err), err.Error())
 		return
 	}
 
 	handleResponse(c, h.log, "", http.StatusOK, resp)
 }
 
-// GetFollowerList godoc
-// @Router       /followers [GET]
-// @Summary      Get list of followers
-// @Description  Get a list of follower relationships
+// GetUserFollowing godoc
+// @Router       /user/{id}/following [GET]
+// @Summary      Get users followed by user
+// @Description  Get a paginated list of users a user follows. Protected accounts only list them to their followers, and nothing is listed across a block
 // @Tags         follower
 // @Accept       json
 // @Produce      json
+// @Param        id path string true "user_id"
 // @Param        page query string false "page"
 // @Param        limit query string false "limit"
-// @Success      200  {object}  models.FollowersResponse
+// @Success      200  {object}  models.UserSummariesResponse
 // @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
 // @Failure      500  {object}  models.Response
-func (h Handler) GetFollowerList(c *gin.Context) {
-	var (
-		page, limit int
-		err         error
-	)
-
-	pageStr := c.DefaultQuery("page", "1")
-	page, err = strconv.Atoi(pageStr)
-	if err != nil {
-		handleResponse(c, h.log, "error while parsing page", http.StatusBadRequest, err.Error())
+func (h Handler) GetUserFollowing(c *gin.Context) {
+	request, ok := h.getUserListRequest(c)
+	if !ok {
 		return
 	}
 
-	limitStr := c.DefaultQuery("limit", "10")
-	limit, err = strconv.Atoi(limitStr)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	resp, err := h.services.Followers().GetFollowing(ctx, request)
 	if err != nil {
-		handleResponse(c, h.log, "error while parsing limit", http.StatusBadRequest, err.Error())
+		handleResponse(c, h.log, "error while getting following", errorStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "", http.StatusOK, resp)
+}
+
+// GetUserMutuals godoc
+// @Router       /user/{id}/mutuals [GET]
+// @Summary      Get mutual followers of user
+// @Description  Get a paginated list of users who follow a user and are followed back. Protected accounts only list them to their followers, and nothing is listed across a block
+// @Tags         follower
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "user_id"
+// @Param        page query string false "page"
+// @Param        limit query string false "limit"
+// @Success      200  {object}  models.UserSummariesResponse
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) GetUserMutuals(c *gin.Context) {
+	request, ok := h.getUserListRequest(c)
+	if !ok {
 		return
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
 	defer cancel()
-	resp, err := h.services.Followers().GetList(ctx, models.GetListRequest{
-		Page:  page,
-		Limit: limit,
-	})
+	resp, err := h.services.Followers().GetMutuals(ctx, request)
 	if err != nil {
-		handleResponse(c, h.log, "error while getting list of followers", http.StatusInternalServerError, err.Error())
+		handleResponse(c, h.log, "error while getting mutuals", errorStatus(err), err.Error())
 		return
 	}
 
 	handleResponse(c, h.log, "", http.StatusOK, resp)
 }
 
-// DeleteFollower godoc
-// @Router       /follower/{id} [DELETE]
-// @Summary      Delete follower relationship
-// @Description  Delete a follower relationship by ID
+// GetRelationship godoc
+// @Router       /relationship [GET]
+// @Summary      Get relationship between users
+// @Description  Get whether the authenticated user follows, is followed by, blocks or mutes target
 // @Tags         follower
 // @Accept       json
 // @Produce      json
-// @Param        id path string true "follower_id"
-// @Success      200  {object}  models.Response
+// @Security     ApiKeyAuth
+// @Param        target query string true "target user_id"
+// @Success      200  {object}  models.Relationship
 // @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
 // @Failure      500  {object}  models.Response
-func (h Handler) DeleteFollower(c *gin.Context) {
-	uid := c.Param("id")
+func (h Handler) GetRelationship(c *gin.Context) {
+	source, ok := getUserID(c)
+	if !ok {
+		handleResponse(c, h.log, "unauthorized", http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	target, err := uuid.Parse(c.Query("target"))
+	if err != nil {
+		handleResponse(c, h.log, "invalid target uuid", http.StatusBadRequest, err.Error())
+		return
+	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
 	defer cancel()
-	if err := h.services.Followers().Delete(ctx, models.PrimaryKey{ID: uid}); err != nil {
-		handleResponse(c, h.log, "error while deleting follower relationship", http.StatusInternalServerError, err.Error())
+	resp, err := h.services.Followers().GetRelationship(ctx, models.RelationshipRequest{
+		SourceID: source,
+		TargetID: target.String(),
+	})
+	if err != nil {
+		handleResponse(c, h.log, "error while getting relationship", http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	handleResponse(c, h.log, "", http.StatusOK, "follower relationship successfully deleted")
+	handleResponse(c, h.log, "", http.StatusOK, resp)
+}
+
+// getUserListRequest reads the user id path parameter and pagination of the
+// follow graph listings, responding with 400 when either is invalid.
+func (h Handler) getUserListRequest(c *gin.Context) (models.GetListRequest, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		handleResponse(c, h.log, "invalid uuid type", http.StatusBadRequest, err.Error())
+		return models.GetListRequest{}, false
+	}
+
+	page, limit, err := getPageAndLimit(c)
+	if err != nil {
+		handleResponse(c, h.log, "error while parsing page or limit", http.StatusBadRequest, err.Error())
+		return models.GetListRequest{}, false
+	}
+
+	viewerID, _ := getUserID(c)
+
+	return models.GetListRequest{
+		Page:     page,
+		Limit:    limit,
+		UserID:   id.String(),
+		ViewerID: viewerID,
+	}, true
 }
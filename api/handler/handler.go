@@ -1,20 +1,28 @@
 package handler
 
 import (
-	"github.com/gin-gonic/gin"
+	"errors"
+	"net/http"
+	"strconv"
 	"test/api/models"
 	"test/pkg/logger"
+	"test/pkg/pubsub"
 	"test/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
 )
 
 type Handler struct {
 	services service.IServiceManager
+	hub      *pubsub.Hub
 	log      logger.ILogger
 }
 
-func New(services service.IServiceManager, log logger.ILogger) Handler {
+func New(services service.IServiceManager, hub *pubsub.Hub, log logger.ILogger) Handler {
 	return Handler{
 		services: services,
+		hub:      hub,
 		log:      log,
 	}
 }
@@ -28,6 +36,12 @@ func handleResponse(c *gin.Context, log logger.ILogger, msg string, statusCode i
 		log.Info("~~~~> OK", logger.String("msg", msg), logger.Any("status", code))
 	case code == 401:
 		resp.Description = "Unauthorized"
+	case code == 403:
+		resp.Description = "Forbidden"
+		log.Error("!!!!! FORBIDDEN", logger.String("msg", msg), logger.Any("status", code))
+	case code == 404:
+		resp.Description = "Not Found"
+		log.Error("!!!!! NOT FOUND", logger.String("msg", msg), logger.Any("status", code))
 	case code < 500:
 		resp.Description = "Bad Request"
 		log.Error("!!!!! BAD REQUEST", logger.String("msg", msg), logger.Any("status", code))
@@ -40,4 +54,45 @@ func handleResponse(c *gin.Context, log logger.ILogger, msg string, statusCode i
 	resp.Data = data
 
 	c.JSON(resp.StatusCode, resp)
-}
\ No newline at end of file
+}
+
+// maxPageLimit caps how many items a page lists, whatever limit is asked for.
+const maxPageLimit = 100
+
+// getPageAndLimit reads the page and limit query parameters, defaulting to
+// the first page of ten items and listing at most maxPageLimit.
+func getPageAndLimit(c *gin.Context) (int, int, error) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil {
+		return 0, 0, err
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if page < 1 || limit < 1 {
+		return 0, 0, errors.New("page and limit must be positive")
+	}
+
+	if limit > maxPageLimit {
+		limit = maxPageLimit
+	}
+
+	return page, limit, nil
+}
+
+// errorStatus maps errors returned by the services to a response status.
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvalid):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, service.ErrNotFound), errors.Is(err, pgx.ErrNoRows):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
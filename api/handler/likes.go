@@ -7,18 +7,22 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 // CreateLike godoc
 // @Router       /like [POST]
 // @Summary      Creates a new like
-// @Description  Create a new like for a tweet
+// @Description  Create a new like for a tweet as the authenticated user
 // @Tags         like
 // @Accept       json
 // @Produce      json
+// @Security     ApiKeyAuth
 // @Param        like body models.CreateLike true "like"
 // @Success      201  {object}  models.Like
 // @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      404  {object}  models.Response
 // @Failure      500  {object}  models.Response
 func (h Handler) CreateLike(c *gin.Context) {
 	var createLike models.CreateLike
@@ -28,11 +32,23 @@ func (h Handler) CreateLike(c *gin.Context) {
 		return
 	}
 
+	if _, err := uuid.Parse(createLike.TweetID); err != nil {
+		handleResponse(c, h.log, "invalid uuid type", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		handleResponse(c, h.log, "unauthorized", http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+	createLike.UserID = userID
+
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
 	defer cancel()
 	resp, err := h.services.Likes().Create(ctx, createLike)
 	if err != nil {
-		handleResponse(c, h.log, "error while creating like", http.StatusInternalServerError, err.Error())
+		handleResponse(c, h.log, "error while creating like", errorStatus(err), err.Error())
 		return
 	}
 
@@ -42,7 +58,7 @@ func (h Handler) CreateLike(c *gin.Context) {
 // GetLike godoc
 // @Router       /like/{id} [GET]
 // @Summary      Get like by ID
-// @Description  Get a like by its ID
+// @Description  Get a like by its ID, if the viewer may see the liker and the liked tweet
 // @Tags         like
 // @Accept       json
 // @Produce      json
@@ -52,13 +68,19 @@ func (h Handler) CreateLike(c *gin.Context) {
 // @Failure      404  {object}  models.Response
 // @Failure      500  {object}  models.Response
 func (h Handler) GetLike(c *gin.Context) {
-	uid := c.Param("id")
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		handleResponse(c, h.log, "invalid uuid type", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	viewerID, _ := getUserID(c)
 
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
 	defer cancel()
-	resp, err := h.services.Likes().Get(ctx, uid)
+	resp, err := h.services.Likes().Get(ctx, id.String(), viewerID)
 	if err != nil {
-		handleResponse(c, h.log, "error while getting like by id", http.StatusInternalServerError, err.Error())
+		handleResponse(c, h.log, "error while getting like by id", errorStatus(err), err.Error())
 		return
 	}
 
@@ -68,23 +90,200 @@ func (h Handler) GetLike(c *gin.Context) {
 // DeleteLike godoc
 // @Router       /like/{id} [DELETE]
 // @Summary      Delete like
-// @Description  Delete a like by ID
+// @Description  Delete a like of the authenticated user by ID
 // @Tags         like
 // @Accept       json
 // @Produce      json
+// @Security     ApiKeyAuth
 // @Param        id path string true "like_id"
 // @Success      200  {object}  models.Response
 // @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      403  {object}  models.Response
+// @Failure      404  {object}  models.Response
 // @Failure      500  {object}  models.Response
 func (h Handler) DeleteLike(c *gin.Context) {
-	uid := c.Param("id")
+	userID, id, ok := h.getAuthorizedID(c)
+	if !ok {
+		return
+	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
 	defer cancel()
-	if err := h.services.Likes().Delete(ctx, models.PrimaryKey{ID: uid}); err != nil {
-		handleResponse(c, h.log, "error while deleting like", http.StatusInternalServerError, err.Error())
+	if err := h.services.Likes().Delete(ctx, models.PrimaryKey{ID: id}, userID); err != nil {
+		handleResponse(c, h.log, "error while deleting like", errorStatus(err), err.Error())
 		return
 	}
 
 	handleResponse(c, h.log, "", http.StatusOK, "like successfully deleted")
 }
+
+// LikeTweet godoc
+// @Router       /tweet/{id}/like [PUT]
+// @Summary      Like tweet
+// @Description  Like a tweet as the authenticated user, liking twice has no effect
+// @Tags         like
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id path string true "tweet_id"
+// @Success      200  {object}  models.Like
+// @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) LikeTweet(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		handleResponse(c, h.log, "unauthorized", http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		handleResponse(c, h.log, "invalid uuid type", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	resp, err := h.services.Likes().Like(ctx, models.CreateLike{
+		TweetID: id.String(),
+		UserID:  userID,
+	})
+	if err != nil {
+		handleResponse(c, h.log, "error while liking tweet", errorStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "", http.StatusOK, resp)
+}
+
+// UnlikeTweet godoc
+// @Router       /tweet/{id}/like [DELETE]
+// @Summary      Unlike tweet
+// @Description  Remove the authenticated user's like from a tweet, unliking twice has no effect
+// @Tags         like
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id path string true "tweet_id"
+// @Success      200  {object}  models.Response
+// @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) UnlikeTweet(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		handleResponse(c, h.log, "unauthorized", http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		handleResponse(c, h.log, "invalid uuid type", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	if err = h.services.Likes().Unlike(ctx, models.CreateLike{
+		TweetID: id.String(),
+		UserID:  userID,
+	}); err != nil {
+		handleResponse(c, h.log, "error while unliking tweet", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "", http.StatusOK, "tweet successfully unliked")
+}
+
+// GetTweetLikes godoc
+// @Router       /tweet/{id}/likes [GET]
+// @Summary      Get users who liked tweet
+// @Description  Get a paginated list of users who liked a tweet
+// @Tags         like
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "tweet_id"
+// @Param        page query string false "page"
+// @Param        limit query string false "limit"
+// @Success      200  {object}  models.UserSummariesResponse
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) GetTweetLikes(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		handleResponse(c, h.log, "invalid uuid type", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	page, limit, err := getPageAndLimit(c)
+	if err != nil {
+		handleResponse(c, h.log, "error while parsing page or limit", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	viewerID, _ := getUserID(c)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	resp, err := h.services.Likes().GetLikingUsers(ctx, models.GetListRequest{
+		Page:     page,
+		Limit:    limit,
+		TweetID:  id.String(),
+		ViewerID: viewerID,
+	})
+	if err != nil {
+		handleResponse(c, h.log, "error while getting users who liked tweet", errorStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "", http.StatusOK, resp)
+}
+
+// GetUserLikes godoc
+// @Router       /user/{id}/likes [GET]
+// @Summary      Get tweets liked by user
+// @Description  Get a paginated list of tweets a user liked. Likes of protected accounts are only listed to their followers, and neither side of a block sees the other's likes
+// @Tags         like
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "user_id"
+// @Param        page query string false "page"
+// @Param        limit query string false "limit"
+// @Success      200  {object}  models.TweetsResponse
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) GetUserLikes(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		handleResponse(c, h.log, "invalid uuid type", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	page, limit, err := getPageAndLimit(c)
+	if err != nil {
+		handleResponse(c, h.log, "error while parsing page or limit", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	viewerID, _ := getUserID(c)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	resp, err := h.services.Likes().GetLikedTweets(ctx, models.GetListRequest{
+		Page:     page,
+		Limit:    limit,
+		UserID:   id.String(),
+		ViewerID: viewerID,
+	})
+	if err != nil {
+		handleResponse(c, h.log, "error while getting tweets liked by user", errorStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "", http.StatusOK, resp)
+}
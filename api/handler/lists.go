@@ -0,0 +1,457 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"test/api/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// CreateList godoc
+// @Router       /list [POST]
+// @Summary      Create list
+// @Description  Create a public or private list of users owned by the authenticated user
+// @Tags         list
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        list body models.CreateList true "list"
+// @Success      200  {object}  models.List
+// @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) CreateList(c *gin.Context) {
+	list := models.CreateList{}
+	if err := c.ShouldBindJSON(&list); err != nil {
+		handleResponse(c, h.log, "error while reading body from client", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		handleResponse(c, h.log, "unauthorized", http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+	list.OwnerUserID = userID
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	resp, err := h.services.Lists().Create(ctx, list)
+	if err != nil {
+		handleResponse(c, h.log, "error while creating list", errorStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "", http.StatusOK, resp)
+}
+
+// GetList godoc
+// @Router       /list/{id} [GET]
+// @Summary      Get list
+// @Description  Get a list, private lists are only visible to their owner
+// @Tags         list
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "list_id"
+// @Success      200  {object}  models.List
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) GetList(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		handleResponse(c, h.log, "invalid uuid type", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	viewerID, _ := getUserID(c)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	resp, err := h.services.Lists().Get(ctx, id.String(), viewerID)
+	if err != nil {
+		handleResponse(c, h.log, "error while getting list", errorStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "", http.StatusOK, resp)
+}
+
+// UpdateList godoc
+// @Router       /list/{id} [PUT]
+// @Summary      Update list
+// @Description  Change the name, description or privacy of a list of the authenticated user
+// @Tags         list
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id path string true "list_id"
+// @Param        list body models.UpdateList true "list"
+// @Success      200  {object}  models.List
+// @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      403  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) UpdateList(c *gin.Context) {
+	list := models.UpdateList{}
+	if err := c.ShouldBindJSON(&list); err != nil {
+		handleResponse(c, h.log, "error while reading body from client", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	userID, id, ok := h.getAuthorizedID(c)
+	if !ok {
+		return
+	}
+
+	list.OwnerUserID = userID
+	list.ListID = id
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	resp, err := h.services.Lists().Update(ctx, list)
+	if err != nil {
+		handleResponse(c, h.log, "error while updating list", errorStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "", http.StatusOK, resp)
+}
+
+// DeleteList godoc
+// @Router       /list/{id} [DELETE]
+// @Summary      Delete list
+// @Description  Delete a list of the authenticated user
+// @Tags         list
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id path string true "list_id"
+// @Success      200  {object}  models.Response
+// @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      403  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) DeleteList(c *gin.Context) {
+	userID, id, ok := h.getAuthorizedID(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	if err := h.services.Lists().Delete(ctx, models.PrimaryKey{ID: id}, userID); err != nil {
+		handleResponse(c, h.log, "error while deleting list", errorStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "", http.StatusOK, "list successfully deleted")
+}
+
+// GetUserLists godoc
+// @Router       /user/{id}/lists [GET]
+// @Summary      Get lists of user
+// @Description  Get a paginated list of the lists a user owns, private lists are only shown to their owner
+// @Tags         list
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "user_id"
+// @Param        page query string false "page"
+// @Param        limit query string false "limit"
+// @Success      200  {object}  models.ListsResponse
+// @Failure      400  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) GetUserLists(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		handleResponse(c, h.log, "invalid uuid type", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	page, limit, err := getPageAndLimit(c)
+	if err != nil {
+		handleResponse(c, h.log, "error while parsing page or limit", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	viewerID, _ := getUserID(c)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	resp, err := h.services.Lists().GetUserLists(ctx, models.GetListRequest{
+		Page:     page,
+		Limit:    limit,
+		UserID:   id.String(),
+		ViewerID: viewerID,
+	})
+	if err != nil {
+		handleResponse(c, h.log, "error while getting user lists", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "", http.StatusOK, resp)
+}
+
+// GetSubscribedLists godoc
+// @Router       /lists/subscriptions [GET]
+// @Summary      Get subscribed lists
+// @Description  Get a paginated list of the lists the authenticated user subscribed to
+// @Tags         list
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        page query string false "page"
+// @Param        limit query string false "limit"
+// @Success      200  {object}  models.ListsResponse
+// @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) GetSubscribedLists(c *gin.Context) {
+	request, ok := h.getOwnListRequest(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	resp, err := h.services.Lists().GetSubscribedLists(ctx, request)
+	if err != nil {
+		handleResponse(c, h.log, "error while getting subscribed lists", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "", http.StatusOK, resp)
+}
+
+// AddListMember godoc
+// @Router       /list/{id}/member/{user_id} [PUT]
+// @Summary      Add list member
+// @Description  Add a user to a list of the authenticated user, adding twice has no effect
+// @Tags         list
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id path string true "list_id"
+// @Param        user_id path string true "user_id"
+// @Success      200  {object}  models.Response
+// @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      403  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) AddListMember(c *gin.Context) {
+	ownerID, member, ok := h.getListMember(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	if err := h.services.Lists().AddMember(ctx, member, ownerID); err != nil {
+		handleResponse(c, h.log, "error while adding list member", errorStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "", http.StatusOK, "user successfully added to list")
+}
+
+// RemoveListMember godoc
+// @Router       /list/{id}/member/{user_id} [DELETE]
+// @Summary      Remove list member
+// @Description  Remove a user from a list of the authenticated user, removing twice has no effect
+// @Tags         list
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id path string true "list_id"
+// @Param        user_id path string true "user_id"
+// @Success      200  {object}  models.Response
+// @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      403  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) RemoveListMember(c *gin.Context) {
+	ownerID, member, ok := h.getListMember(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	if err := h.services.Lists().RemoveMember(ctx, member, ownerID); err != nil {
+		handleResponse(c, h.log, "error while removing list member", errorStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "", http.StatusOK, "user successfully removed from list")
+}
+
+// GetListMembers godoc
+// @Router       /list/{id}/members [GET]
+// @Summary      Get list members
+// @Description  Get a paginated list of the members of a list
+// @Tags         list
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "list_id"
+// @Param        page query string false "page"
+// @Param        limit query string false "limit"
+// @Success      200  {object}  models.UserSummariesResponse
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) GetListMembers(c *gin.Context) {
+	request, ok := h.getListRequest(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	resp, err := h.services.Lists().GetMembers(ctx, request)
+	if err != nil {
+		handleResponse(c, h.log, "error while getting list members", errorStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "", http.StatusOK, resp)
+}
+
+// SubscribeList godoc
+// @Router       /list/{id}/subscribe [PUT]
+// @Summary      Subscribe to list
+// @Description  Subscribe the authenticated user to a list of another user, subscribing twice has no effect
+// @Tags         list
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id path string true "list_id"
+// @Success      200  {object}  models.List
+// @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) SubscribeList(c *gin.Context) {
+	userID, id, ok := h.getAuthorizedID(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	resp, err := h.services.Lists().Subscribe(ctx, models.ListMember{ListID: id, UserID: userID})
+	if err != nil {
+		handleResponse(c, h.log, "error while subscribing to list", errorStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "", http.StatusOK, resp)
+}
+
+// UnsubscribeList godoc
+// @Router       /list/{id}/subscribe [DELETE]
+// @Summary      Unsubscribe from list
+// @Description  Unsubscribe the authenticated user from a list, unsubscribing twice has no effect
+// @Tags         list
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id path string true "list_id"
+// @Success      200  {object}  models.Response
+// @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) UnsubscribeList(c *gin.Context) {
+	userID, id, ok := h.getAuthorizedID(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	if err := h.services.Lists().Unsubscribe(ctx, models.ListMember{ListID: id, UserID: userID}); err != nil {
+		handleResponse(c, h.log, "error while unsubscribing from list", errorStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "", http.StatusOK, "list successfully unsubscribed")
+}
+
+// GetListTimeline godoc
+// @Router       /list/{id}/timeline [GET]
+// @Summary      Get list timeline
+// @Description  Get a paginated list of tweets from the members of a list, most recent first
+// @Tags         list
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "list_id"
+// @Param        page query string false "page"
+// @Param        limit query string false "limit"
+// @Success      200  {object}  models.TweetsResponse
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) GetListTimeline(c *gin.Context) {
+	request, ok := h.getListRequest(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	resp, err := h.services.Lists().GetTimeline(ctx, request)
+	if err != nil {
+		handleResponse(c, h.log, "error while getting list timeline", errorStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "", http.StatusOK, resp)
+}
+
+// getListRequest reads the list id, page and limit of a list listing for the
+// possibly anonymous viewer.
+func (h Handler) getListRequest(c *gin.Context) (models.GetListRequest, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		handleResponse(c, h.log, "invalid uuid type", http.StatusBadRequest, err.Error())
+		return models.GetListRequest{}, false
+	}
+
+	page, limit, err := getPageAndLimit(c)
+	if err != nil {
+		handleResponse(c, h.log, "error while parsing page or limit", http.StatusBadRequest, err.Error())
+		return models.GetListRequest{}, false
+	}
+
+	viewerID, _ := getUserID(c)
+
+	return models.GetListRequest{
+		Page:     page,
+		Limit:    limit,
+		ListID:   id.String(),
+		ViewerID: viewerID,
+	}, true
+}
+
+// getListMember reads the authenticated list owner and the list member from
+// the path.
+func (h Handler) getListMember(c *gin.Context) (string, models.ListMember, bool) {
+	ownerID, id, ok := h.getAuthorizedID(c)
+	if !ok {
+		return "", models.ListMember{}, false
+	}
+
+	userID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		handleResponse(c, h.log, "invalid uuid type", http.StatusBadRequest, err.Error())
+		return "", models.ListMember{}, false
+	}
+
+	return ownerID, models.ListMember{ListID: id, UserID: userID.String()}, true
+}
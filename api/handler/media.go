@@ -0,0 +1,279 @@
+package handler
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"test/api/models"
+	"test/service"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UploadMedia godoc
+// @Router       /media [POST]
+// @Summary      Upload media
+// @Description  Upload an image or a video of up to 15 MB in a multipart form, larger videos are uploaded in chunks. The type is sniffed from the content, images are limited to 5 MB. Images are processed in the background and get their url, variants and blurhash once ready
+// @Tags         media
+// @Accept       multipart/form-data
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        file formData file true "media file"
+// @Success      201  {object}  models.Media
+// @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) UploadMedia(c *gin.Context) {
+	h.uploadMedia(c, models.MediaPurposeTweet, http.StatusCreated)
+}
+
+// uploadMedia stores the file of a multipart form as media for the purpose
+// given, responding with status.
+func (h Handler) uploadMedia(c *gin.Context, purpose string, status int) {
+	userID, ok := getUserID(c)
+	if !ok {
+		handleResponse(c, h.log, "unauthorized", http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	// leaves room for the rest of the form
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, service.MaxMediaUploadSize+1<<20)
+	header, err := c.FormFile("file")
+	if err != nil {
+		handleResponse(c, h.log, "error while reading media file", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		handleResponse(c, h.log, "error while opening media file", http.StatusBadRequest, err.Error())
+		return
+	}
+	defer file.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	media, err := h.services.Media().Upload(ctx, userID, purpose, file, header.Size)
+	if err != nil {
+		handleResponse(c, h.log, "error while uploading media", errorStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "media uploaded successfully", status, media)
+}
+
+// InitMediaUpload godoc
+// @Router       /media/upload [POST]
+// @Summary      Start a chunked media upload
+// @Description  Start uploading media of the given MIME type and size in chunks of up to 5 MB. Videos are limited to 512 MB and images to 5 MB
+// @Tags         media
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        media body models.CreateMedia true "media"
+// @Success      201  {object}  models.Media
+// @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) InitMediaUpload(c *gin.Context) {
+	media := models.CreateMedia{}
+	if err := c.ShouldBindJSON(&media); err != nil {
+		handleResponse(c, h.log, "error while reading body from client", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		handleResponse(c, h.log, "unauthorized", http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+	media.UserID = userID
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	resp, err := h.services.Media().InitUpload(ctx, media)
+	if err != nil {
+		handleResponse(c, h.log, "error while starting media upload", errorStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "media upload started", http.StatusCreated, resp)
+}
+
+// AppendMediaChunk godoc
+// @Router       /media/{id}/chunk [PUT]
+// @Summary      Upload a media chunk
+// @Description  Upload the next chunk of a chunked upload as the raw request body. offset must be the received_size of the media, an interrupted upload resumes from there
+// @Tags         media
+// @Accept       octet-stream
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id path string true "media_id"
+// @Param        offset query int true "offset of the chunk"
+// @Success      200  {object}  models.Media
+// @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) AppendMediaChunk(c *gin.Context) {
+	userID, id, ok := h.getAuthorizedID(c)
+	if !ok {
+		return
+	}
+
+	offset, err := strconv.ParseInt(c.Query("offset"), 10, 64)
+	if err != nil {
+		handleResponse(c, h.log, "error while parsing offset", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, service.MaxMediaChunkSize))
+	if err != nil {
+		handleResponse(c, h.log, "error while reading media chunk", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+	defer cancel()
+	media, err := h.services.Media().AppendChunk(ctx, models.MediaChunk{
+		MediaID: id,
+		UserID:  userID,
+		Offset:  offset,
+	}, data)
+	if err != nil {
+		handleResponse(c, h.log, "error while uploading media chunk", errorStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "", http.StatusOK, media)
+}
+
+// FinalizeMediaUpload godoc
+// @Router       /media/{id}/finalize [POST]
+// @Summary      Finish a chunked media upload
+// @Description  Assemble the uploaded chunks, making the media ready to be attached to a tweet
+// @Tags         media
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id path string true "media_id"
+// @Success      200  {object}  models.Media
+// @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) FinalizeMediaUpload(c *gin.Context) {
+	userID, id, ok := h.getAuthorizedID(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*5)
+	defer cancel()
+	media, err := h.services.Media().FinalizeUpload(ctx, id, userID)
+	if err != nil {
+		handleResponse(c, h.log, "error while finalizing media upload", errorStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "media uploaded successfully", http.StatusOK, media)
+}
+
+// GetMedia godoc
+// @Router       /media/{id} [GET]
+// @Summary      Get media
+// @Description  Get media uploaded by the authenticated user, with how much of it was received
+// @Tags         media
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id path string true "media_id"
+// @Success      200  {object}  models.Media
+// @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) GetMedia(c *gin.Context) {
+	userID, id, ok := h.getAuthorizedID(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	media, err := h.services.Media().Get(ctx, id, userID)
+	if err != nil {
+		handleResponse(c, h.log, "error while getting media", errorStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "", http.StatusOK, media)
+}
+
+// UpdateMediaAltText godoc
+// @Router       /media/{id}/alt-text [PUT]
+// @Summary      Update media alt text
+// @Description  Set the alt text describing an image or a video for screen readers, up to 1000 characters. It can be edited after the media is posted, an empty alt text removes it
+// @Tags         media
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id path string true "media_id"
+// @Param        alt_text body models.UpdateMediaAltText true "alt text"
+// @Success      200  {object}  models.Media
+// @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) UpdateMediaAltText(c *gin.Context) {
+	request := models.UpdateMediaAltText{}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		handleResponse(c, h.log, "error while reading body from client", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	userID, id, ok := h.getAuthorizedID(c)
+	if !ok {
+		return
+	}
+	request.MediaID = id
+	request.UserID = userID
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	media, err := h.services.Media().UpdateAltText(ctx, request)
+	if err != nil {
+		handleResponse(c, h.log, "error while updating media alt text", errorStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "alt text updated successfully", http.StatusOK, media)
+}
+
+// GetMediaFile godoc
+// @Router       /media/files/{key} [GET]
+// @Summary      Get a media file
+// @Description  Serve the content of uploaded media from its url
+// @Tags         media
+// @Produce      octet-stream
+// @Param        key path string true "file key"
+// @Success      200  {file}    file
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) GetMediaFile(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*5)
+	defer cancel()
+	file, mimeType, err := h.services.Media().Open(ctx, c.Param("key"))
+	if err != nil {
+		handleResponse(c, h.log, "error while opening media file", errorStatus(err), err.Error())
+		return
+	}
+	defer file.Close()
+
+	// files never change once stored
+	c.DataFromReader(http.StatusOK, -1, mimeType, file, map[string]string{
+		"Cache-Control": "public, max-age=31536000, immutable",
+	})
+}
@@ -0,0 +1,268 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"test/api/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// CreateConversation godoc
+// @Router       /dm/conversation [POST]
+// @Summary      Create conversation
+// @Description  Start a one to one conversation with a single user or a group conversation with several, starting a one to one conversation again returns the existing one
+// @Tags         dm
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        conversation body models.CreateConversation true "conversation"
+// @Success      201  {object}  models.Conversation
+// @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      403  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) CreateConversation(c *gin.Context) {
+	conversation := models.CreateConversation{}
+	if err := c.ShouldBindJSON(&conversation); err != nil {
+		handleResponse(c, h.log, "error while reading body from client", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		handleResponse(c, h.log, "unauthorized", http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	conversation.CreatorID = userID
+
+	for _, id := range conversation.UserIDs {
+		if _, err := uuid.Parse(id); err != nil {
+			handleResponse(c, h.log, "invalid uuid type", http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	resp, err := h.services.Messages().CreateConversation(ctx, conversation)
+	if err != nil {
+		handleResponse(c, h.log, "error while creating conversation", errorStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "", http.StatusCreated, resp)
+}
+
+// GetConversations godoc
+// @Router       /dm/conversations [GET]
+// @Summary      Get conversations
+// @Description  Get the authenticated user's conversations, most recently active first, with unread counts
+// @Tags         dm
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        page query string false "page"
+// @Param        limit query string false "limit"
+// @Success      200  {object}  models.ConversationsResponse
+// @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) GetConversations(c *gin.Context) {
+	request, ok := h.getOwnListRequest(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	resp, err := h.services.Messages().GetConversations(ctx, request)
+	if err != nil {
+		handleResponse(c, h.log, "error while getting conversations", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "", http.StatusOK, resp)
+}
+
+// GetConversation godoc
+// @Router       /dm/conversation/{id} [GET]
+// @Summary      Get conversation
+// @Description  Get a conversation of the authenticated user
+// @Tags         dm
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id path string true "conversation_id"
+// @Success      200  {object}  models.Conversation
+// @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) GetConversation(c *gin.Context) {
+	userID, id, ok := h.getAuthorizedID(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	resp, err := h.services.Messages().GetConversation(ctx, id, userID)
+	if err != nil {
+		handleResponse(c, h.log, "error while getting conversation", errorStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "", http.StatusOK, resp)
+}
+
+// GetMessages godoc
+// @Router       /dm/conversation/{id}/messages [GET]
+// @Summary      Get messages
+// @Description  Get the messages of a conversation of the authenticated user, newest first, with read receipts
+// @Tags         dm
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id path string true "conversation_id"
+// @Param        page query string false "page"
+// @Param        limit query string false "limit"
+// @Success      200  {object}  models.MessagesResponse
+// @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) GetMessages(c *gin.Context) {
+	userID, id, ok := h.getAuthorizedID(c)
+	if !ok {
+		return
+	}
+
+	page, limit, err := getPageAndLimit(c)
+	if err != nil {
+		handleResponse(c, h.log, "error while parsing page or limit", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	resp, err := h.services.Messages().GetMessages(ctx, models.GetMessagesRequest{
+		ConversationID: id,
+		UserID:         userID,
+		Page:           page,
+		Limit:          limit,
+	})
+	if err != nil {
+		handleResponse(c, h.log, "error while getting messages", errorStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "", http.StatusOK, resp)
+}
+
+// SendMessage godoc
+// @Router       /dm/conversation/{id}/messages [POST]
+// @Summary      Send message
+// @Description  Send a message to a conversation of the authenticated user
+// @Tags         dm
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id path string true "conversation_id"
+// @Param        message body models.CreateMessage true "message"
+// @Success      201  {object}  models.Message
+// @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      403  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) SendMessage(c *gin.Context) {
+	message := models.CreateMessage{}
+	if err := c.ShouldBindJSON(&message); err != nil {
+		handleResponse(c, h.log, "error while reading body from client", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	userID, id, ok := h.getAuthorizedID(c)
+	if !ok {
+		return
+	}
+
+	message.ConversationID = id
+	message.SenderUserID = userID
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	resp, err := h.services.Messages().Send(ctx, message)
+	if err != nil {
+		handleResponse(c, h.log, "error while sending message", errorStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "", http.StatusCreated, resp)
+}
+
+// MarkConversationRead godoc
+// @Router       /dm/conversation/{id}/read [POST]
+// @Summary      Mark conversation read
+// @Description  Mark every message of a conversation as read by the authenticated user
+// @Tags         dm
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id path string true "conversation_id"
+// @Success      200  {object}  models.Response
+// @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) MarkConversationRead(c *gin.Context) {
+	userID, id, ok := h.getAuthorizedID(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	if err := h.services.Messages().MarkRead(ctx, id, userID); err != nil {
+		handleResponse(c, h.log, "error while marking conversation read", errorStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "", http.StatusOK, "conversation marked as read")
+}
+
+// LeaveConversation godoc
+// @Router       /dm/conversation/{id}/leave [POST]
+// @Summary      Leave conversation
+// @Description  Leave a group conversation as the authenticated user
+// @Tags         dm
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id path string true "conversation_id"
+// @Success      200  {object}  models.Response
+// @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      403  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) LeaveConversation(c *gin.Context) {
+	userID, id, ok := h.getAuthorizedID(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	if err := h.services.Messages().Leave(ctx, id, userID); err != nil {
+		handleResponse(c, h.log, "error while leaving conversation", errorStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "", http.StatusOK, "conversation successfully left")
+}
@@ -0,0 +1,284 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"test/api/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// MuteUser godoc
+// @Router       /user/{id}/mute [POST]
+// @Summary      Mute user
+// @Description  Hide a user's tweets from the authenticated user's timeline and mentions, the user can still interact
+// @Tags         mute
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id path string true "user_id"
+// @Success      200  {object}  models.Mute
+// @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) MuteUser(c *gin.Context) {
+	userID, id, ok := h.getAuthorizedID(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	resp, err := h.services.Mutes().MuteUser(ctx, models.CreateMute{UserID: userID, MutedUserID: id})
+	if err != nil {
+		handleResponse(c, h.log, "error while muting user", errorStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "", http.StatusOK, resp)
+}
+
+// UnmuteUser godoc
+// @Router       /user/{id}/mute [DELETE]
+// @Summary      Unmute user
+// @Description  Unmute a user as the authenticated user, unmuting twice has no effect
+// @Tags         mute
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id path string true "user_id"
+// @Success      200  {object}  models.Response
+// @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) UnmuteUser(c *gin.Context) {
+	userID, id, ok := h.getAuthorizedID(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	if err := h.services.Mutes().UnmuteUser(ctx, models.CreateMute{UserID: userID, MutedUserID: id}); err != nil {
+		handleResponse(c, h.log, "error while unmuting user", errorStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "", http.StatusOK, "user successfully unmuted")
+}
+
+// GetMutedUsers godoc
+// @Router       /mutes/users [GET]
+// @Summary      Get muted users
+// @Description  Get a paginated list of users muted by the authenticated user
+// @Tags         mute
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        page query string false "page"
+// @Param        limit query string false "limit"
+// @Success      200  {object}  models.UserSummariesResponse
+// @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) GetMutedUsers(c *gin.Context) {
+	request, ok := h.getOwnListRequest(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	resp, err := h.services.Mutes().GetMutedUsers(ctx, request)
+	if err != nil {
+		handleResponse(c, h.log, "error while getting muted users", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "", http.StatusOK, resp)
+}
+
+// MuteKeyword godoc
+// @Router       /mutes/keywords [POST]
+// @Summary      Mute keyword
+// @Description  Mute a word, phrase or hashtag, optionally until expires_at
+// @Tags         mute
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        keyword body models.CreateMutedKeyword true "keyword"
+// @Success      200  {object}  models.MutedKeyword
+// @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) MuteKeyword(c *gin.Context) {
+	keyword := models.CreateMutedKeyword{}
+	if err := c.ShouldBindJSON(&keyword); err != nil {
+		handleResponse(c, h.log, "error while reading body from client", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		handleResponse(c, h.log, "unauthorized", http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	keyword.UserID = userID
+
+	if keyword.ExpiresAt != nil && !keyword.ExpiresAt.After(time.Now()) {
+		handleResponse(c, h.log, "invalid expires_at", http.StatusBadRequest, "expires_at must be in the future")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	resp, err := h.services.Mutes().MuteKeyword(ctx, keyword)
+	if err != nil {
+		handleResponse(c, h.log, "error while muting keyword", errorStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "", http.StatusOK, resp)
+}
+
+// GetMutedKeywords godoc
+// @Router       /mutes/keywords [GET]
+// @Summary      Get muted keywords
+// @Description  Get the unexpired keywords muted by the authenticated user
+// @Tags         mute
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        page query string false "page"
+// @Param        limit query string false "limit"
+// @Success      200  {object}  models.MutedKeywordsResponse
+// @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) GetMutedKeywords(c *gin.Context) {
+	request, ok := h.getOwnListRequest(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	resp, err := h.services.Mutes().GetMutedKeywords(ctx, request)
+	if err != nil {
+		handleResponse(c, h.log, "error while getting muted keywords", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "", http.StatusOK, resp)
+}
+
+// UnmuteKeyword godoc
+// @Router       /mutes/keyword/{id} [DELETE]
+// @Summary      Unmute keyword
+// @Description  Unmute a keyword muted by the authenticated user
+// @Tags         mute
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id path string true "muted_keyword_id"
+// @Success      200  {object}  models.Response
+// @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) UnmuteKeyword(c *gin.Context) {
+	userID, id, ok := h.getAuthorizedID(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	if err := h.services.Mutes().UnmuteKeyword(ctx, models.PrimaryKey{ID: id}, userID); err != nil {
+		handleResponse(c, h.log, "error while unmuting keyword", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "", http.StatusOK, "keyword successfully unmuted")
+}
+
+// MuteConversation godoc
+// @Router       /tweet/{id}/mute [POST]
+// @Summary      Mute conversation
+// @Description  Stop mentions and notifications from the conversation the tweet belongs to
+// @Tags         mute
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id path string true "tweet_id"
+// @Success      200  {object}  models.MutedConversation
+// @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) MuteConversation(c *gin.Context) {
+	userID, id, ok := h.getAuthorizedID(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	resp, err := h.services.Mutes().MuteConversation(ctx, id, userID)
+	if err != nil {
+		handleResponse(c, h.log, "error while muting conversation", errorStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "", http.StatusOK, resp)
+}
+
+// UnmuteConversation godoc
+// @Router       /tweet/{id}/mute [DELETE]
+// @Summary      Unmute conversation
+// @Description  Unmute the conversation the tweet belongs to
+// @Tags         mute
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id path string true "tweet_id"
+// @Success      200  {object}  models.Response
+// @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) UnmuteConversation(c *gin.Context) {
+	userID, id, ok := h.getAuthorizedID(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	if err := h.services.Mutes().UnmuteConversation(ctx, id, userID); err != nil {
+		handleResponse(c, h.log, "error while unmuting conversation", errorStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "", http.StatusOK, "conversation successfully unmuted")
+}
+
+// getAuthorizedID reads the authenticated user and the uuid in the id path
+// parameter, responding with 401 or 400 when it cannot.
+func (h Handler) getAuthorizedID(c *gin.Context) (string, string, bool) {
+	userID, ok := getUserID(c)
+	if !ok {
+		handleResponse(c, h.log, "unauthorized", http.StatusUnauthorized, "user not authenticated")
+		return "", "", false
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		handleResponse(c, h.log, "invalid uuid type", http.StatusBadRequest, err.Error())
+		return "", "", false
+	}
+
+	return userID, id.String(), true
+}
@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"test/api/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetNotifications godoc
+// @Router       /notifications [GET]
+// @Summary      Get notifications
+// @Description  Get the authenticated user's notifications grouped by type and tweet, with the unread count
+// @Tags         notification
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        page query string false "page"
+// @Param        limit query string false "limit"
+// @Success      200  {object}  models.NotificationsResponse
+// @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) GetNotifications(c *gin.Context) {
+	request, ok := h.getOwnListRequest(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	resp, err := h.services.Notifications().GetList(ctx, request)
+	if err != nil {
+		handleResponse(c, h.log, "error while getting notifications", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "", http.StatusOK, resp)
+}
+
+// MarkNotificationsRead godoc
+// @Router       /notifications/read [POST]
+// @Summary      Mark notifications read
+// @Description  Mark the authenticated user's notifications created up to before, or all of them, as read
+// @Tags         notification
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        request body models.MarkNotificationsRead false "request"
+// @Success      200  {object}  models.Response
+// @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) MarkNotificationsRead(c *gin.Context) {
+	request := models.MarkNotificationsRead{}
+	if err := c.ShouldBindJSON(&request); err != nil && !errors.Is(err, io.EOF) {
+		handleResponse(c, h.log, "error while reading body from client", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		handleResponse(c, h.log, "unauthorized", http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	request.UserID = userID
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	if err := h.services.Notifications().MarkRead(ctx, request); err != nil {
+		handleResponse(c, h.log, "error while marking notifications read", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "", http.StatusOK, "notifications marked as read")
+}
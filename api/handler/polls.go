@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"test/api/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// VotePoll godoc
+// @Router       /tweet/{id}/poll/vote [POST]
+// @Summary      Vote in a poll
+// @Description  Vote for an option of the poll of a tweet as the authenticated user. Users vote once and not in their own polls, results are shown once voted
+// @Tags         poll
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id path string true "tweet_id"
+// @Param        vote body models.CreatePollVote true "vote"
+// @Success      200  {object}  models.Poll
+// @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      403  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) VotePoll(c *gin.Context) {
+	vote := models.CreatePollVote{}
+	if err := c.ShouldBindJSON(&vote); err != nil {
+		handleResponse(c, h.log, "error while reading body from client", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	userID, id, ok := h.getAuthorizedID(c)
+	if !ok {
+		return
+	}
+	vote.TweetID = id
+	vote.UserID = userID
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	poll, err := h.services.Polls().Vote(ctx, vote)
+	if err != nil {
+		handleResponse(c, h.log, "error while voting in poll", errorStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "vote recorded successfully", http.StatusOK, poll)
+}
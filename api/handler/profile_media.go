@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"test/api/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// UploadAvatar godoc
+// @Router       /user/me/avatar [PUT]
+// @Summary      Upload avatar
+// @Description  Upload an image of up to 5 MB as the avatar of the authenticated user. It is cropped to a square and replaces the current avatar once processed
+// @Tags         user
+// @Accept       multipart/form-data
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        file formData file true "image"
+// @Success      202  {object}  models.Media
+// @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) UploadAvatar(c *gin.Context) {
+	h.uploadMedia(c, models.MediaPurposeAvatar, http.StatusAccepted)
+}
+
+// UploadBanner godoc
+// @Router       /user/me/banner [PUT]
+// @Summary      Upload banner
+// @Description  Upload an image of up to 5 MB as the banner of the authenticated user. It is cropped to three times as wide as high and replaces the current banner once processed
+// @Tags         user
+// @Accept       multipart/form-data
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        file formData file true "image"
+// @Success      202  {object}  models.Media
+// @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) UploadBanner(c *gin.Context) {
+	h.uploadMedia(c, models.MediaPurposeBanner, http.StatusAccepted)
+}
+
+// DeleteAvatar godoc
+// @Router       /user/me/avatar [DELETE]
+// @Summary      Delete avatar
+// @Description  Remove the avatar of the authenticated user
+// @Tags         user
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Success      200  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) DeleteAvatar(c *gin.Context) {
+	h.removeProfileMedia(c, models.MediaPurposeAvatar)
+}
+
+// DeleteBanner godoc
+// @Router       /user/me/banner [DELETE]
+// @Summary      Delete banner
+// @Description  Remove the banner of the authenticated user
+// @Tags         user
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Success      200  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) DeleteBanner(c *gin.Context) {
+	h.removeProfileMedia(c, models.MediaPurposeBanner)
+}
+
+// GetAvatar godoc
+// @Router       /user/{id}/avatar [GET]
+// @Summary      Get avatar
+// @Description  Redirect to the current avatar of the user, of size mini (48px), normal (96px), bigger (200px), large (400px) or the original when size is omitted
+// @Tags         user
+// @Param        id path string true "user_id"
+// @Param        size query string false "size"
+// @Success      302
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) GetAvatar(c *gin.Context) {
+	h.redirectToProfileMedia(c, models.MediaPurposeAvatar)
+}
+
+// GetBanner godoc
+// @Router       /user/{id}/banner [GET]
+// @Summary      Get banner
+// @Description  Redirect to the current banner of the user, of size small (600px wide), medium (1500px wide) or the original when size is omitted
+// @Tags         user
+// @Param        id path string true "user_id"
+// @Param        size query string false "size"
+// @Success      302
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) GetBanner(c *gin.Context) {
+	h.redirectToProfileMedia(c, models.MediaPurposeBanner)
+}
+
+func (h Handler) removeProfileMedia(c *gin.Context, purpose string) {
+	userID, ok := getUserID(c)
+	if !ok {
+		handleResponse(c, h.log, "unauthorized", http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	if err := h.services.Media().RemoveProfileMedia(ctx, userID, purpose); err != nil {
+		handleResponse(c, h.log, "error while removing "+purpose, errorStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, purpose+" removed successfully", http.StatusOK, nil)
+}
+
+// redirectToProfileMedia redirects the stable url of an avatar or banner to
+// the file of the current one, briefly cached as it changes when replaced.
+func (h Handler) redirectToProfileMedia(c *gin.Context, purpose string) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		handleResponse(c, h.log, "invalid uuid type", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	url, err := h.services.Media().GetProfileMediaURL(ctx, id.String(), purpose, c.Query("size"))
+	if err != nil {
+		handleResponse(c, h.log, "error while getting "+purpose, errorStatus(err), err.Error())
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=300")
+	c.Redirect(http.StatusFound, url)
+}
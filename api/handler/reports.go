@@ -0,0 +1,275 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"test/api/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// CreateReport godoc
+// @Router       /report [POST]
+// @Summary      Report abuse
+// @Description  Report a tweet or user the authenticated user can see, or a direct message of one of their conversations, to the moderators. target_type is tweet, user or message and reason one of spam, harassment, hate, violence, self_harm, impersonation or other
+// @Tags         report
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        report body models.CreateReport true "report"
+// @Success      201  {object}  models.Report
+// @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) CreateReport(c *gin.Context) {
+	report := models.CreateReport{}
+	if err := c.ShouldBindJSON(&report); err != nil {
+		handleResponse(c, h.log, "error while reading body from client", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if _, err := uuid.Parse(report.TargetID); err != nil {
+		handleResponse(c, h.log, "invalid uuid type", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		handleResponse(c, h.log, "unauthorized", http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+	report.ReporterUserID = userID
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	resp, err := h.services.Reports().Create(ctx, report)
+	if err != nil {
+		handleResponse(c, h.log, "error while creating report", errorStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "report submitted successfully", http.StatusCreated, resp)
+}
+
+// GetReports godoc
+// @Router       /moderation/reports [GET]
+// @Summary      Get moderation queue
+// @Description  Get a paginated list of reports, oldest first, optionally only the open, claimed or resolved ones. Moderators only
+// @Tags         moderation
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        state query string false "state"
+// @Param        page query string false "page"
+// @Param        limit query string false "limit"
+// @Success      200  {object}  models.ReportsResponse
+// @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      403  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) GetReports(c *gin.Context) {
+	request, ok := h.getOwnListRequest(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	resp, err := h.services.Reports().GetList(ctx, request.UserID, models.GetReportsRequest{
+		Page:  request.Page,
+		Limit: request.Limit,
+		State: c.Query("state"),
+	})
+	if err != nil {
+		handleResponse(c, h.log, "error while getting reports", errorStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "", http.StatusOK, resp)
+}
+
+// GetReport godoc
+// @Router       /moderation/report/{id} [GET]
+// @Summary      Get report
+// @Description  Get a report with a copy of the reported content. Moderators only
+// @Tags         moderation
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id path string true "report_id"
+// @Success      200  {object}  models.Report
+// @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      403  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) GetReport(c *gin.Context) {
+	userID, id, ok := h.getAuthorizedID(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	resp, err := h.services.Reports().Get(ctx, models.PrimaryKey{ID: id}, userID)
+	if err != nil {
+		handleResponse(c, h.log, "error while getting report", errorStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "", http.StatusOK, resp)
+}
+
+// ClaimReport godoc
+// @Router       /moderation/report/{id}/claim [POST]
+// @Summary      Claim report
+// @Description  Assign a report to the authenticated moderator, who then resolves it. Reports claimed by another moderator can be taken over once their claim is an hour old. Moderators only
+// @Tags         moderation
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id path string true "report_id"
+// @Success      200  {object}  models.Report
+// @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      403  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) ClaimReport(c *gin.Context) {
+	userID, id, ok := h.getAuthorizedID(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	resp, err := h.services.Reports().Claim(ctx, models.PrimaryKey{ID: id}, userID)
+	if err != nil {
+		handleResponse(c, h.log, "error while claiming report", errorStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "report claimed successfully", http.StatusOK, resp)
+}
+
+// ClaimNextReport godoc
+// @Router       /moderation/reports/claim [POST]
+// @Summary      Claim next report
+// @Description  Assign the oldest report waiting for review to the authenticated moderator. Moderators only
+// @Tags         moderation
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Success      200  {object}  models.Report
+// @Failure      401  {object}  models.Response
+// @Failure      403  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) ClaimNextReport(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		handleResponse(c, h.log, "unauthorized", http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	resp, err := h.services.Reports().ClaimNext(ctx, userID)
+	if err != nil {
+		handleResponse(c, h.log, "error while claiming next report", errorStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "report claimed successfully", http.StatusOK, resp)
+}
+
+// ResolveReport godoc
+// @Router       /moderation/report/{id}/resolve [POST]
+// @Summary      Resolve report
+// @Description  Resolve a report claimed by the authenticated moderator with an action: hide_tweet hides the reported tweet, suspend_user suspends the author of the reported content or the reported user, dismiss takes no action. The action is recorded in the moderation audit log. Moderators only
+// @Tags         moderation
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id path string true "report_id"
+// @Param        resolution body models.ResolveReport true "resolution"
+// @Success      200  {object}  models.Report
+// @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      403  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) ResolveReport(c *gin.Context) {
+	request := models.ResolveReport{}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		handleResponse(c, h.log, "error while reading body from client", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	userID, id, ok := h.getAuthorizedID(c)
+	if !ok {
+		return
+	}
+	request.ReportID = id
+	request.ModeratorUserID = userID
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	resp, err := h.services.Reports().Resolve(ctx, request)
+	if err != nil {
+		handleResponse(c, h.log, "error while resolving report", errorStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "report resolved successfully", http.StatusOK, resp)
+}
+
+// GetModerationActions godoc
+// @Router       /moderation/actions [GET]
+// @Summary      Get moderation audit log
+// @Description  Get a paginated list of the actions moderators took, most recent first, optionally only those about one target. Entries are never changed or removed. Moderators only
+// @Tags         moderation
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        target_type query string false "target_type"
+// @Param        target_id query string false "target_id"
+// @Param        page query string false "page"
+// @Param        limit query string false "limit"
+// @Success      200  {object}  models.ModerationActionsResponse
+// @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      403  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) GetModerationActions(c *gin.Context) {
+	request, ok := h.getOwnListRequest(c)
+	if !ok {
+		return
+	}
+
+	targetID := c.Query("target_id")
+	if targetID != "" {
+		if _, err := uuid.Parse(targetID); err != nil {
+			handleResponse(c, h.log, "invalid uuid type", http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	resp, err := h.services.Reports().GetActions(ctx, request.UserID, models.GetModerationActionsRequest{
+		Page:       request.Page,
+		Limit:      request.Limit,
+		TargetType: c.Query("target_type"),
+		TargetID:   targetID,
+	})
+	if err != nil {
+		handleResponse(c, h.log, "error while getting moderation actions", errorStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "", http.StatusOK, resp)
+}
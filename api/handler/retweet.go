@@ -7,18 +7,22 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 // CreateRetweet godoc
 // @Router       /retweet [POST]
 // @Summary      Creates a new retweet
-// @Description  Create a new retweet for a tweet
+// @Description  Create a new retweet for a tweet as the authenticated user
 // @Tags         retweet
 // @Accept       json
 // @Produce      json
+// @Security     ApiKeyAuth
 // @Param        retweet body models.CreateRetweet true "retweet"
 // @Success      201  {object}  models.Retweet
 // @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      404  {object}  models.Response
 // @Failure      500  {object}  models.Response
 func (h Handler) CreateRetweet(c *gin.Context) {
 	var createRetweet models.CreateRetweet
@@ -28,11 +32,23 @@ func (h Handler) CreateRetweet(c *gin.Context) {
 		return
 	}
 
+	if _, err := uuid.Parse(createRetweet.OriginalTweetID); err != nil {
+		handleResponse(c, h.log, "invalid uuid type", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		handleResponse(c, h.log, "unauthorized", http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+	createRetweet.UserID = userID
+
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
 	defer cancel()
 	id, err := h.services.Retweets().Create(ctx, createRetweet)
 	if err != nil {
-		handleResponse(c, h.log, "error while creating retweet", http.StatusInternalServerError, err.Error())
+		handleResponse(c, h.log, "error while creating retweet", errorStatus(err), err.Error())
 		return
 	}
 
@@ -42,23 +58,155 @@ func (h Handler) CreateRetweet(c *gin.Context) {
 // DeleteRetweet godoc
 // @Router       /retweet/{id} [DELETE]
 // @Summary      Delete retweet
-// @Description  Delete a retweet by ID
+// @Description  Delete a retweet of the authenticated user by ID
 // @Tags         retweet
 // @Accept       json
 // @Produce      json
+// @Security     ApiKeyAuth
 // @Param        id path string true "retweet_id"
 // @Success      200  {object}  models.Response
 // @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      403  {object}  models.Response
+// @Failure      404  {object}  models.Response
 // @Failure      500  {object}  models.Response
 func (h Handler) DeleteRetweet(c *gin.Context) {
-	uid := c.Param("id")
+	userID, id, ok := h.getAuthorizedID(c)
+	if !ok {
+		return
+	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
 	defer cancel()
-	if err := h.services.Retweets().Delete(ctx, models.PrimaryKey{ID: uid}); err != nil {
-		handleResponse(c, h.log, "error while deleting retweet", http.StatusInternalServerError, err.Error())
+	if err := h.services.Retweets().Delete(ctx, models.PrimaryKey{ID: id}, userID); err != nil {
+		handleResponse(c, h.log, "error while deleting retweet", errorStatus(err), err.Error())
 		return
 	}
 
 	handleResponse(c, h.log, "", http.StatusOK, "retweet successfully deleted")
 }
+
+// RetweetTweet godoc
+// @Router       /tweet/{id}/retweet [PUT]
+// @Summary      Retweet tweet
+// @Description  Retweet a tweet as the authenticated user, retweeting twice has no effect
+// @Tags         retweet
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id path string true "tweet_id"
+// @Success      200  {object}  models.Retweet
+// @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) RetweetTweet(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		handleResponse(c, h.log, "unauthorized", http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		handleResponse(c, h.log, "invalid uuid type", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	resp, err := h.services.Retweets().Retweet(ctx, models.CreateRetweet{
+		OriginalTweetID: id.String(),
+		UserID:          userID,
+	})
+	if err != nil {
+		handleResponse(c, h.log, "error while retweeting tweet", errorStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "", http.StatusOK, resp)
+}
+
+// UnretweetTweet godoc
+// @Router       /tweet/{id}/retweet [DELETE]
+// @Summary      Undo retweet
+// @Description  Remove the authenticated user's retweet of a tweet, undoing twice has no effect
+// @Tags         retweet
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id path string true "tweet_id"
+// @Success      200  {object}  models.Response
+// @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) UnretweetTweet(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		handleResponse(c, h.log, "unauthorized", http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		handleResponse(c, h.log, "invalid uuid type", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	if err = h.services.Retweets().Unretweet(ctx, models.CreateRetweet{
+		OriginalTweetID: id.String(),
+		UserID:          userID,
+	}); err != nil {
+		handleResponse(c, h.log, "error while undoing retweet", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "", http.StatusOK, "retweet successfully undone")
+}
+
+// GetTweetRetweets godoc
+// @Router       /tweet/{id}/retweets [GET]
+// @Summary      Get users who retweeted tweet
+// @Description  Get a paginated list of users who retweeted a tweet
+// @Tags         retweet
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "tweet_id"
+// @Param        page query string false "page"
+// @Param        limit query string false "limit"
+// @Success      200  {object}  models.UserSummariesResponse
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) GetTweetRetweets(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		handleResponse(c, h.log, "invalid uuid type", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	page, limit, err := getPageAndLimit(c)
+	if err != nil {
+		handleResponse(c, h.log, "error while parsing page or limit", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	viewerID, _ := getUserID(c)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	resp, err := h.services.Retweets().GetRetweetingUsers(ctx, models.GetListRequest{
+		Page:     page,
+		Limit:    limit,
+		TweetID:  id.String(),
+		ViewerID: viewerID,
+	})
+	if err != nil {
+		handleResponse(c, h.log, "error while getting users who retweeted tweet", errorStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "", http.StatusOK, resp)
+}
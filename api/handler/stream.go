@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"net/http"
+	"test/pkg/logger"
+	"test/pkg/pubsub"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// heartbeatInterval is how often idle streams are pinged so proxies keep
+	// them open and dead clients are noticed.
+	heartbeatInterval = 15 * time.Second
+
+	// streamWriteTimeout is how long a single write to a client may take
+	// before the client is considered too slow and disconnected.
+	streamWriteTimeout = 10 * time.Second
+
+	// overflowEvent tells a client that it was dropped for falling behind and
+	// should refetch before reconnecting.
+	overflowEvent = "overflow"
+)
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	// the stream is authenticated with a token rather than cookies, so other
+	// origins cannot use it on behalf of a user
+	CheckOrigin: func(*http.Request) bool { return true },
+}
+
+// Stream godoc
+// @Router       /stream [GET]
+// @Summary      Stream events
+// @Description  Push new home timeline tweets, notifications and counter updates to the authenticated user as Server-Sent Events. Browsers may send the token as the access_token query parameter.
+// @Tags         stream
+// @Produce      text/event-stream
+// @Security     ApiKeyAuth
+// @Param        access_token query string false "access token"
+// @Success      200  {string}  string
+// @Failure      401  {object}  models.Response
+func (h Handler) Stream(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		handleResponse(c, h.log, "unauthorized", http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	sub := h.hub.Subscribe(userID)
+	defer h.hub.Unsubscribe(sub)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	controller := http.NewResponseController(c.Writer)
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-heartbeat.C:
+			_ = controller.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+			if _, err := c.Writer.WriteString(": heartbeat\n\n"); err != nil {
+				return
+			}
+		case event, ok := <-sub.Events():
+			_ = controller.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+			if !ok {
+				if sub.Dropped() {
+					c.SSEvent(overflowEvent, "")
+					c.Writer.Flush()
+				}
+				return
+			}
+			c.SSEvent(event.Type, event.Data)
+		}
+		c.Writer.Flush()
+	}
+}
+
+// StreamWebSocket godoc
+// @Router       /stream/ws [GET]
+// @Summary      Stream events over WebSocket
+// @Description  Push new home timeline tweets, notifications and counter updates to the authenticated user as JSON WebSocket messages. Browsers may send the token as the access_token query parameter.
+// @Tags         stream
+// @Security     ApiKeyAuth
+// @Param        access_token query string false "access token"
+// @Success      101  {string}  string
+// @Failure      401  {object}  models.Response
+func (h Handler) StreamWebSocket(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		handleResponse(c, h.log, "unauthorized", http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		h.log.Error("error while upgrading stream connection", logger.Error(err))
+		return
+	}
+	defer conn.Close()
+
+	sub := h.hub.Subscribe(userID)
+	defer h.hub.Unsubscribe(sub)
+
+	// the client only sends pongs and close frames, reading them keeps the
+	// deadline moving and notices when the client goes away
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+
+		_ = conn.SetReadDeadline(time.Now().Add(2 * heartbeatInterval))
+		conn.SetPongHandler(func(string) error {
+			return conn.SetReadDeadline(time.Now().Add(2 * heartbeatInterval))
+		})
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-closed:
+			return
+		case <-heartbeat.C:
+			if err = conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(streamWriteTimeout)); err != nil {
+				return
+			}
+		case event, ok := <-sub.Events():
+			_ = conn.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+			if !ok {
+				if sub.Dropped() {
+					_ = conn.WriteJSON(pubsub.Event{Type: overflowEvent})
+				}
+				return
+			}
+			if err = conn.WriteJSON(event); err != nil {
+				return
+			}
+		}
+	}
+}
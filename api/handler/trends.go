@@ -0,0 +1,30 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetTrends godoc
+// @Router       /trends [GET]
+// @Summary      Get trends
+// @Description  Get the hashtags and phrases used much more in the last hour than over the day before, with the number of users who tweeted them in the last hour
+// @Tags         trend
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  models.TrendsResponse
+// @Failure      500  {object}  models.Response
+func (h Handler) GetTrends(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	resp, err := h.services.Trends().GetList(ctx)
+	if err != nil {
+		handleResponse(c, h.log, "error while getting trends", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "", http.StatusOK, resp)
+}
@@ -2,9 +2,7 @@ package handler
 
 import (
 	"context"
-	"errors"
 	"net/http"
-	"strconv"
 	"test/api/models"
 	"time"
 
@@ -15,14 +13,17 @@ import (
 // CreateTweet godoc
 // @Router       /tweet [POST]
 // @Summary      Creates a new tweet
-// @Description  Create a new tweet by an authenticated user
+// @Description  Create a new tweet by an authenticated user, with up to four images or one video, or with a poll of 2 to 4 options lasting 5 minutes to 7 days. reply_audience, everyone by default, limits replies to the people the author follows or mentions (following) or only mentions (mentioned)
 // @Tags         tweet
 // @Accept       json
 // @Produce      json
+// @Security     ApiKeyAuth
 // @Param        tweet body models.CreateTweet true "tweet"
 // @Success      201  {object}  models.Tweet
 // @Failure      400  {object}  models.Response
 // @Failure      401  {object}  models.Response
+// @Failure      403  {object}  models.Response
+// @Failure      404  {object}  models.Response
 // @Failure      500  {object}  models.Response
 func (h Handler) CreateTweet(c *gin.Context) {
 	var createTweet models.CreateTweet
@@ -45,7 +46,7 @@ func (h Handler) CreateTweet(c *gin.Context) {
 
 	tweet, err := h.services.Tweets().Create(ctx, createTweet)
 	if err != nil {
-		handleResponse(c, h.log, "error while creating tweet", http.StatusInternalServerError, err.Error())
+		handleResponse(c, h.log, "error while creating tweet", errorStatus(err), err.Error())
 		return
 	}
 
@@ -75,9 +76,10 @@ func (h Handler) GetTweet(c *gin.Context) {
 
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
 	defer cancel()
-	tweet, err := h.services.Tweets().Get(ctx, id.String())
+	viewerID, _ := getUserID(c)
+	tweet, err := h.services.Tweets().Get(ctx, id.String(), viewerID)
 	if err != nil {
-		handleResponse(c, h.log, "error while getting tweet by id", http.StatusInternalServerError, err)
+		handleResponse(c, h.log, "error while getting tweet by id", errorStatus(err), err.Error())
 		return
 	}
 
@@ -87,7 +89,7 @@ func (h Handler) GetTweet(c *gin.Context) {
 // GetTweetList godoc
 // @Router       /tweets [GET]
 // @Summary      Get tweet list
-// @Description  Get list of tweets
+// @Description  Get list of tweets. search supports words, "phrases", -excluded words, from:username, #hashtag, since:2006-01-30, until:2006-01-30 and filter:media, matches are ranked and highlighted
 // @Tags         tweet
 // @Accept       json
 // @Produce      json
@@ -99,77 +101,170 @@ func (h Handler) GetTweet(c *gin.Context) {
 // @Failure      404  {object}  models.Response
 // @Failure      500  {object}  models.Response
 func (h Handler) GetTweetList(c *gin.Context) {
-	var (
-		page, limit int
-		search      string
-		err         error
-	)
-
-	pageStr := c.DefaultQuery("page", "1")
-	page, err = strconv.Atoi(pageStr)
+	page, limit, err := getPageAndLimit(c)
 	if err != nil {
-		handleResponse(c, h.log, "error while parsing page", http.StatusBadRequest, err.Error())
+		handleResponse(c, h.log, "error while parsing page or limit", http.StatusBadRequest, err.Error())
 		return
 	}
 
-	limitStr := c.DefaultQuery("limit", "10")
-	limit, err = strconv.Atoi(limitStr)
+	search := c.Query("search")
+	viewerID, _ := getUserID(c)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	resp, err := h.services.Tweets().GetList(ctx, models.GetListRequest{
+		Page:     page,
+		Limit:    limit,
+		Search:   search,
+		ViewerID: viewerID,
+	})
 	if err != nil {
-		handleResponse(c, h.log, "error while parsing limit", http.StatusBadRequest, err.Error())
+		handleResponse(c, h.log, "error while getting tweets", errorStatus(err), err.Error())
 		return
 	}
 
-	search = c.Query("search")
+	handleResponse(c, h.log, "success!", http.StatusOK, resp)
+}
+
+// GetMentions godoc
+// @Router       /mentions [GET]
+// @Summary      Get mentions
+// @Description  Get tweets mentioning the authenticated user, leaving out muted users, keywords and conversations
+// @Tags         tweet
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        page query string false "page"
+// @Param        limit query string false "limit"
+// @Success      200  {object}  models.TweetsResponse
+// @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) GetMentions(c *gin.Context) {
+	request, ok := h.getOwnListRequest(c)
+	if !ok {
+		return
+	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
 	defer cancel()
-	resp, err := h.services.Tweets().GetList(ctx, models.GetListRequest{
-		Page:   page,
-		Limit:  limit,
-		Search: search,
-	})
+	resp, err := h.services.Tweets().GetMentions(ctx, request)
 	if err != nil {
-		handleResponse(c, h.log, "error while getting tweets", http.StatusInternalServerError, err)
+		handleResponse(c, h.log, "error while getting mentions", http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	handleResponse(c, h.log, "success!", http.StatusOK, resp)
+	handleResponse(c, h.log, "", http.StatusOK, resp)
 }
 
 // UpdateTweet godoc
 // @Router       /tweet/{id} [PUT]
 // @Summary      Update tweet
-// @Description  Update a tweet
+// @Description  Edit the content of a tweet of the authenticated user. Tweets can be edited within a window after posting and a limited number of times, every revision is kept in the tweet history. Mentions follow the edited content, so people no longer mentioned lose the replies their mention allowed
 // @Tags         tweet
 // @Accept       json
 // @Produce      json
+// @Security     ApiKeyAuth
 // @Param        id path string true "tweet_id"
 // @Param        tweet body models.UpdateTweet true "tweet"
 // @Success      200  {object}  models.Tweet
 // @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      403  {object}  models.Response
 // @Failure      404  {object}  models.Response
 // @Failure      500  {object}  models.Response
 func (h Handler) UpdateTweet(c *gin.Context) {
 	updateTweet := models.UpdateTweet{}
+	if err := c.ShouldBindJSON(&updateTweet); err != nil {
+		handleResponse(c, h.log, "error while reading body", http.StatusBadRequest, err.Error())
+		return
+	}
 
-	uid := c.Param("id")
-	if uid == "" {
-		handleResponse(c, h.log, "invalid uuid", http.StatusBadRequest, errors.New("uuid is not valid"))
+	userID, id, ok := h.getAuthorizedID(c)
+	if !ok {
 		return
 	}
+	updateTweet.ID = id
+	updateTweet.UserID = userID
 
-	updateTweet.ID = uid
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	resp, err := h.services.Tweets().Update(ctx, updateTweet)
+	if err != nil {
+		handleResponse(c, h.log, "error while updating tweet", errorStatus(err), err.Error())
+		return
+	}
 
-	if err := c.ShouldBindJSON(&updateTweet); err != nil {
+	handleResponse(c, h.log, "", http.StatusOK, resp)
+}
+
+// UpdateTweetReplyAudience godoc
+// @Router       /tweet/{id}/reply-audience [PUT]
+// @Summary      Update tweet reply audience
+// @Description  Change who can reply to a tweet of the authenticated user: everyone, the people the author follows or mentioned (following), or only the people mentioned (mentioned). Replies already posted are kept
+// @Tags         tweet
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id path string true "tweet_id"
+// @Param        reply_audience body models.UpdateReplyAudience true "reply_audience"
+// @Success      200  {object}  models.Tweet
+// @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      403  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) UpdateTweetReplyAudience(c *gin.Context) {
+	request := models.UpdateReplyAudience{}
+	if err := c.ShouldBindJSON(&request); err != nil {
 		handleResponse(c, h.log, "error while reading body", http.StatusBadRequest, err.Error())
 		return
 	}
 
+	userID, id, ok := h.getAuthorizedID(c)
+	if !ok {
+		return
+	}
+	request.TweetID = id
+	request.UserID = userID
+
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
 	defer cancel()
-	resp, err := h.services.Tweets().Update(ctx, updateTweet)
+	resp, err := h.services.Tweets().UpdateReplyAudience(ctx, request)
 	if err != nil {
-		handleResponse(c, h.log, "error while updating tweet", http.StatusInternalServerError, err.Error())
+		handleResponse(c, h.log, "error while updating tweet reply audience", errorStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "", http.StatusOK, resp)
+}
+
+// GetTweetHistory godoc
+// @Router       /tweet/{id}/history [GET]
+// @Summary      Get tweet edit history
+// @Description  Get the revisions of a tweet, the original first and the current one last
+// @Tags         tweet
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "tweet_id"
+// @Success      200  {object}  models.TweetHistoryResponse
+// @Failure      400  {object}  models.Response
+// @Failure      403  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) GetTweetHistory(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		handleResponse(c, h.log, "invalid uuid type", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	viewerID, _ := getUserID(c)
+	resp, err := h.services.Tweets().GetHistory(ctx, id.String(), viewerID)
+	if err != nil {
+		handleResponse(c, h.log, "error while getting tweet history", errorStatus(err), err.Error())
 		return
 	}
 
@@ -179,29 +274,28 @@ func (h Handler) UpdateTweet(c *gin.Context) {
 // DeleteTweet godoc
 // @Router       /tweet/{id} [DELETE]
 // @Summary      Delete tweet
-// @Description  Delete a tweet
+// @Description  Delete a tweet of the authenticated user
 // @Tags         tweet
 // @Accept       json
 // @Produce      json
+// @Security     ApiKeyAuth
 // @Param        id path string true "tweet_id"
 // @Success      200  {object}  models.Response
 // @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      403  {object}  models.Response
 // @Failure      404  {object}  models.Response
 // @Failure      500  {object}  models.Response
 func (h Handler) DeleteTweet(c *gin.Context) {
-	uid := c.Param("id")
-	id, err := uuid.Parse(uid)
-	if err != nil {
-		handleResponse(c, h.log, "uuid is not valid", http.StatusBadRequest, err.Error())
+	userID, id, ok := h.getAuthorizedID(c)
+	if !ok {
 		return
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
 	defer cancel()
-	if err = h.services.Tweets().Delete(ctx, models.PrimaryKey{
-		ID: id.String(),
-	}); err != nil {
-		handleResponse(c, h.log, "error while deleting tweet by id", http.StatusInternalServerError, err.Error())
+	if err := h.services.Tweets().Delete(ctx, models.PrimaryKey{ID: id}, userID); err != nil {
+		handleResponse(c, h.log, "error while deleting tweet by id", errorStatus(err), err.Error())
 		return
 	}
 
@@ -47,7 +47,7 @@ func (h Handler) CreateUser(c *gin.Context) {
 // GetUser godoc
 // @Router       /user/{id} [GET]
 // @Summary      Gets user
-// @Description  get user by ID
+// @Description  get user by ID, with the tweet pinned to their profile when the viewer can see it
 // @Tags         user
 // @Accept       json
 // @Produce      json
@@ -67,13 +67,15 @@ func (h Handler) GetUser(c *gin.Context) {
 		return
 	}
 
+	viewerID, _ := getUserID(c)
+
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
 	defer cancel()
-	user, err := h.services.User().GetByID(ctx, models.PrimaryKey{
+	user, err := h.services.User().GetProfile(ctx, models.PrimaryKey{
 		ID: id.String(),
-	})
+	}, viewerID)
 	if err != nil {
-		handleResponse(c, h.log, "error while getting user by id", http.StatusInternalServerError, err)
+		handleResponse(c, h.log, "error while getting user by id", errorStatus(err), err.Error())
 		return
 	}
 
@@ -95,34 +97,22 @@ func (h Handler) GetUser(c *gin.Context) {
 // @Failure      404  {object}  models.Response
 // @Failure      500  {object}  models.Response
 func (h Handler) GetUserList(c *gin.Context) {
-	var (
-		page, limit int
-		search      string
-		err         error
-	)
-
-	pageStr := c.DefaultQuery("page", "1")
-	page, err = strconv.Atoi(pageStr)
+	page, limit, err := getPageAndLimit(c)
 	if err != nil {
-		handleResponse(c, h.log, "error while parsing page", http.StatusBadRequest, err.Error())
+		handleResponse(c, h.log, "error while parsing page or limit", http.StatusBadRequest, err.Error())
 		return
 	}
 
-	limitStr := c.DefaultQuery("limit", "10")
-	limit, err = strconv.Atoi(limitStr)
-	if err != nil {
-		handleResponse(c, h.log, "error while parsing limit", http.StatusBadRequest, err.Error())
-		return
-	}
-
-	search = c.Query("search")
+	search := c.Query("search")
+	viewerID, _ := getUserID(c)
 
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
 	defer cancel()
 	resp, err := h.services.User().GetList(ctx, models.GetListRequest{
-		Page:   page,
-		Limit:  limit,
-		Search: search,
+		Page:     page,
+		Limit:    limit,
+		Search:   search,
+		ViewerID: viewerID,
 	})
 	if err != nil {
 		handleResponse(c, h.log, "error while getting users", http.StatusInternalServerError, err)
@@ -132,6 +122,43 @@ func (h Handler) GetUserList(c *gin.Context) {
 	handleResponse(c, h.log, "success!", http.StatusOK, resp)
 }
 
+// GetUserTypeahead godoc
+// @Router       /users/typeahead [GET]
+// @Summary      Suggest users
+// @Description  Suggest users whose username or name matches a partially typed query, meant to be called on every keystroke
+// @Tags         user
+// @Accept       json
+// @Produce      json
+// @Param        q query string true "q"
+// @Param        limit query string false "limit"
+// @Success      200  {object}  models.TypeaheadResponse
+// @Failure      400  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) GetUserTypeahead(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "8"))
+	if err != nil || limit < 1 || limit > 20 {
+		handleResponse(c, h.log, "invalid limit", http.StatusBadRequest, "limit must be between 1 and 20")
+		return
+	}
+
+	viewerID, _ := getUserID(c)
+
+	// suggestions arriving later than this are outdated by the next keystroke
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*500)
+	defer cancel()
+	resp, err := h.services.User().Typeahead(ctx, models.TypeaheadRequest{
+		Query:    c.Query("q"),
+		ViewerID: viewerID,
+		Limit:    limit,
+	})
+	if err != nil {
+		handleResponse(c, h.log, "error while suggesting users", errorStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "", http.StatusOK, resp)
+}
+
 // UpdateUser godoc
 // @Router       /user/{id} [PUT]
 // @Summary      Update user
@@ -242,3 +269,144 @@ func (h Handler) UpdateUserPassword(c *gin.Context) {
 
 	handleResponse(c, h.log, "", http.StatusOK, "password successfully updated")
 }
+
+// GetUserSettings godoc
+// @Router       /user/me/settings [GET]
+// @Summary      Get account settings
+// @Description  Get the account settings of the authenticated user
+// @Tags         user
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Success      200  {object}  models.UserSettings
+// @Failure      401  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) GetUserSettings(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		handleResponse(c, h.log, "unauthorized", http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	resp, err := h.services.User().GetSettings(ctx, models.PrimaryKey{ID: userID})
+	if err != nil {
+		handleResponse(c, h.log, "error while getting user settings", errorStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "", http.StatusOK, resp)
+}
+
+// UpdateUserSettings godoc
+// @Router       /user/me/settings [PUT]
+// @Summary      Update account settings
+// @Description  Update the account settings of the authenticated user, omitted settings are left unchanged. alt_text_reminder is off, nudge or require, deciding whether tweets with images missing alt text are refused unless ignore_alt_text_reminder is set, or always
+// @Tags         user
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        settings body models.UpdateUserSettings true "settings"
+// @Success      200  {object}  models.UserSettings
+// @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) UpdateUserSettings(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		handleResponse(c, h.log, "unauthorized", http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	updateSettings := models.UpdateUserSettings{}
+	if err := c.ShouldBindJSON(&updateSettings); err != nil {
+		handleResponse(c, h.log, "error while reading body", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	updateSettings.ID = userID
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	resp, err := h.services.User().UpdateSettings(ctx, updateSettings)
+	if err != nil {
+		handleResponse(c, h.log, "error while updating user settings", errorStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "", http.StatusOK, resp)
+}
+
+// PinTweet godoc
+// @Router       /user/me/pin [PUT]
+// @Summary      Pin tweet
+// @Description  Pin one of the authenticated user's tweets to the top of their profile, replacing the tweet pinned before. Deleted tweets are unpinned
+// @Tags         user
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        pin body models.PinTweet true "pinned tweet"
+// @Success      200  {object}  models.User
+// @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      403  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) PinTweet(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		handleResponse(c, h.log, "unauthorized", http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	pin := models.PinTweet{}
+	if err := c.ShouldBindJSON(&pin); err != nil {
+		handleResponse(c, h.log, "error while reading body", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if _, err := uuid.Parse(pin.TweetID); err != nil {
+		handleResponse(c, h.log, "invalid uuid type", http.StatusBadRequest, err.Error())
+		return
+	}
+	pin.UserID = userID
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	resp, err := h.services.User().PinTweet(ctx, pin)
+	if err != nil {
+		handleResponse(c, h.log, "error while pinning tweet", errorStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "tweet pinned successfully", http.StatusOK, resp)
+}
+
+// UnpinTweet godoc
+// @Router       /user/me/pin [DELETE]
+// @Summary      Unpin tweet
+// @Description  Unpin the tweet pinned to the authenticated user's profile, unpinning twice has no effect
+// @Tags         user
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Success      200  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) UnpinTweet(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		handleResponse(c, h.log, "unauthorized", http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	if err := h.services.User().UnpinTweet(ctx, userID); err != nil {
+		handleResponse(c, h.log, "error while unpinning tweet", errorStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.log, "", http.StatusOK, "tweet unpinned successfully")
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+type Block struct {
+	BlockID       string    `json:"block_id"`
+	UserID        string    `json:"user_id"`
+	BlockedUserID string    `json:"blocked_user_id"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type CreateBlock struct {
+	UserID        string `json:"user_id"`
+	BlockedUserID string `json:"blocked_user_id"`
+}
@@ -0,0 +1,61 @@
+package models
+
+import "time"
+
+type Bookmark struct {
+	BookmarkID string    `json:"bookmark_id"`
+	FolderID   *string   `json:"folder_id,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	Tweet      Tweet     `json:"tweet"`
+}
+
+type CreateBookmark struct {
+	UserID   string  `json:"-"`
+	TweetID  string  `json:"-"`
+	FolderID *string `json:"folder_id,omitempty"`
+}
+
+type BookmarksResponse struct {
+	Bookmarks  []Bookmark `json:"bookmarks"`
+	NextCursor string     `json:"next_cursor,omitempty"`
+}
+
+// GetBookmarksRequest pages through bookmarks with a cursor, the bookmarks
+// older than the one created at CursorCreatedAt with CursorID are returned.
+type GetBookmarksRequest struct {
+	UserID          string
+	FolderID        string
+	Limit           int
+	CursorCreatedAt *time.Time
+	CursorID        string
+}
+
+type BookmarkFolder struct {
+	FolderID  string    `json:"folder_id"`
+	UserID    string    `json:"user_id"`
+	Name      string    `json:"name"`
+	Position  int       `json:"position"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type CreateBookmarkFolder struct {
+	UserID string `json:"-"`
+	Name   string `json:"name"`
+}
+
+type UpdateBookmarkFolder struct {
+	FolderID string `json:"-"`
+	UserID   string `json:"-"`
+	Name     string `json:"name"`
+}
+
+type ReorderBookmarkFolders struct {
+	UserID    string   `json:"-"`
+	FolderIDs []string `json:"folder_ids"`
+}
+
+type BookmarkFoldersResponse struct {
+	Folders []BookmarkFolder `json:"folders"`
+	Count   int              `json:"count"`
+}
@@ -9,5 +9,8 @@ type GetListRequest struct {
 	Limit  int    `json:"limit"`
 	Search string `json:"search"`
 
-	UserID string `json:"user_id"`
+	UserID   string `json:"user_id"`
+	TweetID  string `json:"tweet_id"`
+	ListID   string `json:"list_id"`
+	ViewerID string `json:"-"`
 }
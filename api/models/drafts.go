@@ -0,0 +1,70 @@
+package models
+
+import "time"
+
+const (
+	DraftStateDraft      = "draft"
+	DraftStateScheduled  = "scheduled"
+	DraftStatePublishing = "publishing"
+	DraftStateFailed     = "failed"
+)
+
+// Draft is a tweet only its author sees until it is published, right away or
+// at PublishAt when scheduled. Drafts whose publishing failed keep the reason
+// in LastError until they are edited.
+type Draft struct {
+	ID                    string      `json:"id"`
+	UserID                string      `json:"user_id"`
+	Content               string      `json:"content"`
+	MediaIDs              []string    `json:"media_ids"`
+	ReplyToTweetID        *string     `json:"reply_to_tweet_id,omitempty"`
+	Poll                  *CreatePoll `json:"poll,omitempty"`
+	ReplyAudience         string      `json:"reply_audience"`
+	IgnoreAltTextReminder bool        `json:"ignore_alt_text_reminder"`
+	State                 string      `json:"state"`
+	PublishAt             *time.Time  `json:"publish_at,omitempty"`
+	Attempts              int         `json:"-"`
+	LastError             *string     `json:"last_error,omitempty"`
+	CreatedAt             time.Time   `json:"created_at"`
+	UpdatedAt             time.Time   `json:"updated_at"`
+}
+
+// CreateDraft saves a draft, scheduled when PublishAt is set.
+type CreateDraft struct {
+	UserID                string      `json:"-"`
+	Content               string      `json:"content"`
+	MediaIDs              []string    `json:"media_ids,omitempty"`
+	ReplyToTweetID        *string     `json:"reply_to_tweet_id,omitempty"`
+	Poll                  *CreatePoll `json:"poll,omitempty"`
+	ReplyAudience         string      `json:"reply_audience,omitempty"`
+	IgnoreAltTextReminder bool        `json:"ignore_alt_text_reminder,omitempty"`
+	PublishAt             *time.Time  `json:"publish_at,omitempty"`
+}
+
+// UpdateDraft replaces the content of a draft. Leaving out PublishAt turns a
+// scheduled draft back into a plain one.
+type UpdateDraft struct {
+	ID                    string      `json:"-"`
+	UserID                string      `json:"-"`
+	Content               string      `json:"content"`
+	MediaIDs              []string    `json:"media_ids,omitempty"`
+	ReplyToTweetID        *string     `json:"reply_to_tweet_id,omitempty"`
+	Poll                  *CreatePoll `json:"poll,omitempty"`
+	ReplyAudience         string      `json:"reply_audience,omitempty"`
+	IgnoreAltTextReminder bool        `json:"ignore_alt_text_reminder,omitempty"`
+	PublishAt             *time.Time  `json:"publish_at,omitempty"`
+}
+
+type DraftsResponse struct {
+	Drafts []Draft `json:"drafts"`
+	Count  int     `json:"count"`
+}
+
+// GetDraftsRequest lists the drafts of UserID, only the scheduled ones or
+// only the others when Scheduled is set.
+type GetDraftsRequest struct {
+	Page      int
+	Limit     int
+	UserID    string
+	Scheduled *bool
+}
@@ -2,6 +2,11 @@ package models
 
 import "time"
 
+const (
+	FollowStatusFollowing = "following"
+	FollowStatusPending   = "pending"
+)
+
 type Follower struct {
 	FollowerID     string    `json:"follower_id"`
 	UserID         string    `json:"user_id"`
@@ -11,7 +16,13 @@ type Follower struct {
 
 type CreateFollower struct {
 	UserID         string `json:"user_id"`
-	FollowerUserID string `json:"follower_user_id"`
+	FollowerUserID string `json:"-"`
+}
+
+type CreateFollowerResponse struct {
+	Status        string         `json:"status"`
+	Follower      *Follower      `json:"follower,omitempty"`
+	FollowRequest *FollowRequest `json:"follow_request,omitempty"`
 }
 
 type UpdateFollower struct {
@@ -24,3 +35,33 @@ type FollowersResponse struct {
 	Followers []Follower `json:"followers"`
 	Count     int        `json:"count"`
 }
+
+// RelationshipRequest asks how SourceID, the user asking, relates to TargetID.
+type RelationshipRequest struct {
+	SourceID string `json:"source"`
+	TargetID string `json:"target"`
+}
+
+// Relationship is how SourceID relates to TargetID. Blocking and Muting are
+// private to SourceID, who must be the user asking.
+type Relationship struct {
+	SourceID   string `json:"source"`
+	TargetID   string `json:"target"`
+	Following  bool   `json:"following"`
+	FollowedBy bool   `json:"followed_by"`
+	Blocking   bool   `json:"blocking"`
+	Muting     bool   `json:"muting"`
+}
+
+type FollowRequest struct {
+	FollowRequestID string      `json:"follow_request_id"`
+	UserID          string      `json:"user_id"`
+	RequesterUserID string      `json:"requester_user_id"`
+	CreatedAt       time.Time   `json:"created_at"`
+	User            UserSummary `json:"user"`
+}
+
+type FollowRequestsResponse struct {
+	FollowRequests []FollowRequest `json:"follow_requests"`
+	Count          int             `json:"count"`
+}
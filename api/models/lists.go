@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+type List struct {
+	ListID          string    `json:"list_id"`
+	OwnerUserID     string    `json:"owner_user_id"`
+	Name            string    `json:"name"`
+	Description     string    `json:"description"`
+	Private         bool      `json:"private"`
+	MemberCount     int       `json:"member_count"`
+	SubscriberCount int       `json:"subscriber_count"`
+	Subscribed      bool      `json:"subscribed"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+type CreateList struct {
+	OwnerUserID string `json:"-"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Private     bool   `json:"private"`
+}
+
+type UpdateList struct {
+	ListID      string `json:"-"`
+	OwnerUserID string `json:"-"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Private     bool   `json:"private"`
+}
+
+type ListsResponse struct {
+	Lists []List `json:"lists"`
+	Count int    `json:"count"`
+}
+
+// ListMember adds UserID to or removes it from a list, it is also used for
+// list subscriptions.
+type ListMember struct {
+	ListID string `json:"-"`
+	UserID string `json:"-"`
+}
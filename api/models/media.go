@@ -0,0 +1,113 @@
+package models
+
+import "time"
+
+const (
+	MediaTypeImage = "image"
+	MediaTypeVideo = "video"
+
+	// MediaStateUploading is the state of media whose chunks are still being
+	// uploaded.
+	MediaStateUploading = "uploading"
+	// MediaStateProcessing is the state of uploaded images waiting for their
+	// metadata to be stripped and their variants to be generated. They have
+	// no url until then.
+	MediaStateProcessing = "processing"
+	MediaStateReady      = "ready"
+	MediaStateFailed     = "failed"
+
+	// MediaPurposeTweet is the purpose of media attached to tweets, avatars
+	// and banners are set on the profile of their owner once processed.
+	MediaPurposeTweet  = "tweet"
+	MediaPurposeAvatar = "avatar"
+	MediaPurposeBanner = "banner"
+
+	MaxAltTextLength = 1000
+)
+
+// Media is an uploaded image or video, attached to at most one tweet of its
+// owner. ReceivedSize is how much of Size was uploaded, so that an interrupted
+// chunked upload resumes from there. Width, Height, Blurhash and Variants are
+// set once images are processed.
+type Media struct {
+	ID           string         `json:"id"`
+	UserID       string         `json:"user_id"`
+	TweetID      *string        `json:"tweet_id,omitempty"`
+	Purpose      string         `json:"purpose"`
+	Type         string         `json:"type"`
+	MimeType     string         `json:"mime_type"`
+	Size         int64          `json:"size"`
+	ReceivedSize int64          `json:"received_size"`
+	State        string         `json:"state"`
+	URL          string         `json:"url,omitempty"`
+	AltText      string         `json:"alt_text"`
+	Width        int            `json:"width,omitempty"`
+	Height       int            `json:"height,omitempty"`
+	Blurhash     string         `json:"blurhash,omitempty"`
+	Variants     []MediaVariant `json:"variants,omitempty"`
+	Attempts     int            `json:"-"`
+	StorageKey   string         `json:"-"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+}
+
+// MediaVariant is a resized copy of an image, named after its size.
+type MediaVariant struct {
+	Name       string `json:"name"`
+	Width      int    `json:"width"`
+	Height     int    `json:"height"`
+	URL        string `json:"url"`
+	StorageKey string `json:"-"`
+}
+
+type CreateMedia struct {
+	UserID   string `json:"-"`
+	Purpose  string `json:"-"`
+	Type     string `json:"-"`
+	MimeType string `json:"mime_type"`
+	Size     int64  `json:"size"`
+}
+
+// UpdateMediaAltText sets the text describing media for those who cannot see
+// it, removed when empty.
+type UpdateMediaAltText struct {
+	MediaID string `json:"-"`
+	UserID  string `json:"-"`
+	AltText string `json:"alt_text"`
+}
+
+// CompleteMedia records where the uploaded media is stored, moving it to
+// State.
+type CompleteMedia struct {
+	ID         string
+	MimeType   string
+	StorageKey string
+	URL        string
+	State      string
+}
+
+// ProcessedMedia is the result of processing an image, which replaces its
+// uploaded content and makes it ready.
+type ProcessedMedia struct {
+	ID         string
+	UserID     string
+	Purpose    string
+	MimeType   string
+	Size       int64
+	StorageKey string
+	URL        string
+	Width      int
+	Height     int
+	Blurhash   string
+	Variants   []MediaVariant
+}
+
+// MediaChunk is a part of a chunked upload starting Offset bytes into the
+// media.
+type MediaChunk struct {
+	MediaID    string
+	UserID     string
+	Offset     int64
+	Size       int64
+	StorageKey string
+}
@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+type Conversation struct {
+	ConversationID string        `json:"conversation_id"`
+	IsGroup        bool          `json:"is_group"`
+	Name           string        `json:"name"`
+	Members        []UserSummary `json:"members"`
+	LastMessage    *Message      `json:"last_message,omitempty"`
+	UnreadCount    int           `json:"unread_count"`
+	CreatedAt      time.Time     `json:"created_at"`
+	UpdatedAt      time.Time     `json:"updated_at"`
+}
+
+type CreateConversation struct {
+	CreatorID string   `json:"-"`
+	UserIDs   []string `json:"user_ids"`
+	Name      string   `json:"name,omitempty"`
+	DirectKey string   `json:"-"`
+}
+
+type ConversationsResponse struct {
+	Conversations []Conversation `json:"conversations"`
+	Count         int            `json:"count"`
+}
+
+type Message struct {
+	MessageID      string    `json:"message_id"`
+	ConversationID string    `json:"conversation_id"`
+	SenderUserID   *string   `json:"sender_user_id"`
+	Content        string    `json:"content"`
+	ReadBy         []string  `json:"read_by"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type CreateMessage struct {
+	ConversationID string `json:"-"`
+	SenderUserID   string `json:"-"`
+	Content        string `json:"content"`
+}
+
+type MessagesResponse struct {
+	Messages []Message `json:"messages"`
+	Count    int       `json:"count"`
+}
+
+type GetMessagesRequest struct {
+	ConversationID string `json:"conversation_id"`
+	UserID         string `json:"-"`
+	Page           int    `json:"page"`
+	Limit          int    `json:"limit"`
+}
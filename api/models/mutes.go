@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+type Mute struct {
+	MuteID      string    `json:"mute_id"`
+	UserID      string    `json:"user_id"`
+	MutedUserID string    `json:"muted_user_id"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type CreateMute struct {
+	UserID      string `json:"user_id"`
+	MutedUserID string `json:"muted_user_id"`
+}
+
+type MutedKeyword struct {
+	MutedKeywordID string     `json:"muted_keyword_id"`
+	UserID         string     `json:"user_id"`
+	Keyword        string     `json:"keyword"`
+	ExpiresAt      *time.Time `json:"expires_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+type CreateMutedKeyword struct {
+	UserID    string     `json:"-"`
+	Keyword   string     `json:"keyword"`
+	Pattern   string     `json:"-"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+type MutedKeywordsResponse struct {
+	MutedKeywords []MutedKeyword `json:"muted_keywords"`
+	Count         int            `json:"count"`
+}
+
+type MutedConversation struct {
+	MutedConversationID string    `json:"muted_conversation_id"`
+	UserID              string    `json:"user_id"`
+	ConversationID      string    `json:"conversation_id"`
+	CreatedAt           time.Time `json:"created_at"`
+}
+
+type CreateMutedConversation struct {
+	UserID         string `json:"user_id"`
+	ConversationID string `json:"conversation_id"`
+}
@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+const (
+	NotificationTypeLike          = "like"
+	NotificationTypeRetweet       = "retweet"
+	NotificationTypeFollow        = "follow"
+	NotificationTypeFollowRequest = "follow_request"
+	NotificationTypeMention       = "mention"
+	NotificationTypeReply         = "reply"
+	NotificationTypePollClosed    = "poll_closed"
+)
+
+type CreateNotification struct {
+	UserID      string  `json:"user_id"`
+	ActorUserID string  `json:"actor_user_id"`
+	Type        string  `json:"type"`
+	TweetID     *string `json:"tweet_id,omitempty"`
+}
+
+// Notification groups the events of one type about the same tweet, so that
+// several likes read as "A and 5 others liked your tweet".
+type Notification struct {
+	Type       string        `json:"type"`
+	TweetID    *string       `json:"tweet_id,omitempty"`
+	Actors     []UserSummary `json:"actors"`
+	ActorCount int           `json:"actor_count"`
+	Unread     bool          `json:"unread"`
+	Text       string        `json:"text"`
+	CreatedAt  time.Time     `json:"created_at"`
+}
+
+type NotificationsResponse struct {
+	Notifications []Notification `json:"notifications"`
+	Count         int            `json:"count"`
+	UnreadCount   int            `json:"unread_count"`
+}
+
+type MarkNotificationsRead struct {
+	UserID string     `json:"-"`
+	Before *time.Time `json:"before,omitempty"`
+}
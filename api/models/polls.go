@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+const (
+	MinPollOptions      = 2
+	MaxPollOptions      = 4
+	MaxPollOptionLength = 25
+	MinPollDuration     = 5 * time.Minute
+	MaxPollDuration     = 7 * 24 * time.Hour
+)
+
+// Poll is the poll of a tweet. Votes are only set once the viewer voted or
+// the poll closed, and always for its author; ViewerVote is the option the
+// viewer voted for.
+type Poll struct {
+	TweetID    string       `json:"tweet_id"`
+	Options    []PollOption `json:"options"`
+	TotalVotes *int         `json:"total_votes,omitempty"`
+	EndsAt     time.Time    `json:"ends_at"`
+	Closed     bool         `json:"closed"`
+	ViewerVote *string      `json:"viewer_vote,omitempty"`
+}
+
+type PollOption struct {
+	ID       string `json:"id"`
+	Position int    `json:"position"`
+	Label    string `json:"label"`
+	Votes    *int   `json:"votes,omitempty"`
+}
+
+// CreatePoll is the poll of a new tweet, open for DurationMinutes.
+type CreatePoll struct {
+	Options         []string `json:"options"`
+	DurationMinutes int      `json:"duration_minutes"`
+}
+
+type CreatePollVote struct {
+	TweetID  string `json:"-"`
+	UserID   string `json:"-"`
+	OptionID string `json:"option_id"`
+}
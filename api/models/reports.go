@@ -0,0 +1,109 @@
+package models
+
+import "time"
+
+const (
+	ReportTargetTweet   = "tweet"
+	ReportTargetUser    = "user"
+	ReportTargetMessage = "message"
+
+	ReportReasonSpam          = "spam"
+	ReportReasonHarassment    = "harassment"
+	ReportReasonHate          = "hate"
+	ReportReasonViolence      = "violence"
+	ReportReasonSelfHarm      = "self_harm"
+	ReportReasonImpersonation = "impersonation"
+	ReportReasonOther         = "other"
+
+	ReportStateOpen     = "open"
+	ReportStateClaimed  = "claimed"
+	ReportStateResolved = "resolved"
+
+	ModerationActionHideTweet   = "hide_tweet"
+	ModerationActionSuspendUser = "suspend_user"
+	ModerationActionDismiss     = "dismiss"
+
+	UserRoleUser      = "user"
+	UserRoleModerator = "moderator"
+	UserRoleAdmin     = "admin"
+)
+
+// Report is a report of a tweet, user or direct message for moderators to
+// review. TargetUserID is the author of the reported content, or the reported
+// user, and TargetContent a copy of the content as it was when reported, so
+// that it can be reviewed even after being edited or deleted.
+type Report struct {
+	ID             string     `json:"id"`
+	ReporterUserID string     `json:"reporter_user_id"`
+	TargetType     string     `json:"target_type"`
+	TargetID       string     `json:"target_id"`
+	TargetUserID   *string    `json:"target_user_id,omitempty"`
+	TargetContent  string     `json:"target_content"`
+	Reason         string     `json:"reason"`
+	Details        string     `json:"details"`
+	State          string     `json:"state"`
+	ClaimedBy      *string    `json:"claimed_by,omitempty"`
+	ClaimedAt      *time.Time `json:"claimed_at,omitempty"`
+	ResolvedBy     *string    `json:"resolved_by,omitempty"`
+	ResolvedAt     *time.Time `json:"resolved_at,omitempty"`
+	Resolution     *string    `json:"resolution,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+type CreateReport struct {
+	ReporterUserID string  `json:"-"`
+	TargetType     string  `json:"target_type"`
+	TargetID       string  `json:"target_id"`
+	TargetUserID   *string `json:"-"`
+	TargetContent  string  `json:"-"`
+	Reason         string  `json:"reason"`
+	Details        string  `json:"details,omitempty"`
+}
+
+// ResolveReport closes a report claimed by ModeratorUserID with Action.
+type ResolveReport struct {
+	ReportID        string `json:"-"`
+	ModeratorUserID string `json:"-"`
+	Action          string `json:"action"`
+	Note            string `json:"note,omitempty"`
+}
+
+type ReportsResponse struct {
+	Reports []Report `json:"reports"`
+	Count   int      `json:"count"`
+}
+
+// GetReportsRequest lists the reports in State, all of them when empty.
+type GetReportsRequest struct {
+	Page  int
+	Limit int
+	State string
+}
+
+// ModerationAction is an entry of the moderation audit log, which is never
+// changed once written.
+type ModerationAction struct {
+	ID              string    `json:"id"`
+	ModeratorUserID string    `json:"moderator_user_id"`
+	ReportID        *string   `json:"report_id,omitempty"`
+	Action          string    `json:"action"`
+	TargetType      string    `json:"target_type"`
+	TargetID        string    `json:"target_id"`
+	Note            string    `json:"note"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+type ModerationActionsResponse struct {
+	Actions []ModerationAction `json:"actions"`
+	Count   int                `json:"count"`
+}
+
+// GetModerationActionsRequest lists the audit log, only the entries about
+// TargetType and TargetID when set.
+type GetModerationActionsRequest struct {
+	Page       int
+	Limit      int
+	TargetType string
+	TargetID   string
+}
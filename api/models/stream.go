@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+const (
+	EventTypeTweet        = "tweet"
+	EventTypeNotification = "notification"
+	EventTypeCounters     = "counters"
+	EventTypeMessage      = "message"
+	EventTypeMessageRead  = "message_read"
+)
+
+// NotificationEvent is pushed to the recipient of a new notification.
+type NotificationEvent struct {
+	Type        string  `json:"type"`
+	ActorUserID string  `json:"actor_user_id"`
+	TweetID     *string `json:"tweet_id,omitempty"`
+	UnreadCount int     `json:"unread_count"`
+}
+
+type TweetCounters struct {
+	TweetID      string `json:"tweet_id"`
+	LikeCount    int    `json:"like_count"`
+	RetweetCount int    `json:"retweet_count"`
+	ReplyCount   int    `json:"reply_count"`
+}
+
+// MessageReadEvent is pushed to the other members of a conversation when a
+// member reads it.
+type MessageReadEvent struct {
+	ConversationID string    `json:"conversation_id"`
+	UserID         string    `json:"user_id"`
+	ReadAt         time.Time `json:"read_at"`
+}
@@ -0,0 +1,18 @@
+package models
+
+// UserSuggestion is an account suggested to follow with what it has in common
+// with the user. FollowedBy holds up to three of the accounts followed by the
+// user that follow the suggested account, out of FollowedByCount.
+type UserSuggestion struct {
+	User            UserSummary   `json:"user"`
+	Reason          string        `json:"reason"`
+	FollowedBy      []UserSummary `json:"followed_by"`
+	FollowedByCount int           `json:"followed_by_count"`
+	FollowsYou      bool          `json:"follows_you"`
+	SharedHashtags  []string      `json:"shared_hashtags"`
+}
+
+type UserSuggestionsResponse struct {
+	Suggestions []UserSuggestion `json:"suggestions"`
+	Count       int              `json:"count"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+const (
+	TrendTypeHashtag = "hashtag"
+	TrendTypePhrase  = "phrase"
+)
+
+type Trend struct {
+	Term       string `json:"term"`
+	Type       string `json:"type"`
+	TweetCount int64  `json:"tweet_count"`
+}
+
+type TrendsResponse struct {
+	Trends    []Trend   `json:"trends"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -2,28 +2,85 @@ package models
 
 import "time"
 
+// Reply audiences say who can reply to a tweet besides its author: everyone,
+// the people the author follows or mentioned, or only the people mentioned.
+const (
+	ReplyAudienceEveryone  = "everyone"
+	ReplyAudienceFollowing = "following"
+	ReplyAudienceMentioned = "mentioned"
+)
+
+// Tweet is a tweet with its media and poll. ImageURL and VideoURL are only set
+// on tweets created before media uploads. CanReply tells whether the viewer
+// may reply to it. Hidden tweets were hidden by moderators and are only seen by
+// their author.
 type Tweet struct {
-	ID        string    `json:"id"`
-	UserID    string    `json:"user_id"`
-	Content   string    `json:"content"`
-	ImageURL  *string   `json:"image_url,omitempty"`
-	VideoURL  *string   `json:"video_url,omitempty"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID             string    `json:"id"`
+	UserID         string    `json:"user_id"`
+	Content        string    `json:"content"`
+	ImageURL       *string   `json:"image_url,omitempty"`
+	VideoURL       *string   `json:"video_url,omitempty"`
+	ReplyToTweetID *string   `json:"reply_to_tweet_id,omitempty"`
+	ConversationID string    `json:"conversation_id"`
+	ReplyAudience  string    `json:"reply_audience"`
+	EditCount      int       `json:"edit_count"`
+	Hidden         bool      `json:"hidden,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+
+	Media      []Media `json:"media"`
+	Poll       *Poll   `json:"poll,omitempty"`
+	Bookmarked bool    `json:"bookmarked"`
+	CanReply   bool    `json:"can_reply"`
+	Highlight  string  `json:"highlight,omitempty"`
 }
 
+// CreateTweet creates a tweet with up to four images or one video uploaded
+// beforehand, or with a poll. IgnoreAltTextReminder posts images without alt
+// text when the user is nudged to add it. DraftID is the draft the tweet is
+// published from, a draft is published once. MentionedUserIDs and Hashtags
+// are those of the content.
 type CreateTweet struct {
-	UserID   string  `json:"user_id"`
-	Content  string  `json:"content"`
-	ImageURL *string `json:"image_url,omitempty"`
-	VideoURL *string `json:"video_url,omitempty"`
+	UserID                string      `json:"user_id"`
+	Content               string      `json:"content"`
+	MediaIDs              []string    `json:"media_ids,omitempty"`
+	ReplyToTweetID        *string     `json:"reply_to_tweet_id,omitempty"`
+	Poll                  *CreatePoll `json:"poll,omitempty"`
+	ReplyAudience         string      `json:"reply_audience,omitempty"`
+	IgnoreAltTextReminder bool        `json:"ignore_alt_text_reminder,omitempty"`
+	DraftID               *string     `json:"-"`
+	MentionedUserIDs      []string    `json:"-"`
+	Hashtags              []string    `json:"-"`
 }
 
+// UpdateTweet edits the content of a tweet of UserID. MentionedUserIDs and
+// Hashtags are those of the new content.
 type UpdateTweet struct {
-	ID       string  `json:"id"`
-	Content  *string `json:"content,omitempty"`
-	ImageURL *string `json:"image_url,omitempty"`
-	VideoURL *string `json:"video_url,omitempty"`
+	ID               string   `json:"id"`
+	UserID           string   `json:"-"`
+	Content          *string  `json:"content,omitempty"`
+	MentionedUserIDs []string `json:"-"`
+	Hashtags         []string `json:"-"`
+}
+
+// UpdateReplyAudience changes who can reply to a tweet of UserID.
+type UpdateReplyAudience struct {
+	TweetID       string `json:"-"`
+	UserID        string `json:"-"`
+	ReplyAudience string `json:"reply_audience"`
+}
+
+// TweetRevision is a version of the content of a tweet, the original one
+// being revision 0.
+type TweetRevision struct {
+	Revision  int       `json:"revision"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type TweetHistoryResponse struct {
+	Revisions []TweetRevision `json:"revisions"`
+	Count     int             `json:"count"`
 }
 
 type TweetsResponse struct {
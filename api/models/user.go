@@ -2,35 +2,78 @@ package models
 
 import "time"
 
+// User is a user. ProfilePicture and Banner are the stable urls of their
+// avatar and banner, empty when they have none. PinnedTweet is only loaded
+// with the profile, for viewers who can see it.
 type User struct {
-	ID                string    `json:"id"`
-	Username          string    `json:"username"`
-	PasswordHash      string    `json:"-"`
-	Name              string    `json:"name"`
-	Bio               string    `json:"bio"`
-	ProfilePicture    string    `json:"profile_picture"`
-	CreatedAt         time.Time `json:"created_at"`
-	UpdatedAt         time.Time `json:"updated_at"`
+	ID             string     `json:"id"`
+	Username       string     `json:"username"`
+	PasswordHash   string     `json:"-"`
+	Name           string     `json:"name"`
+	Bio            string     `json:"bio"`
+	ProfilePicture string     `json:"profile_picture"`
+	Banner         string     `json:"banner"`
+	Protected      bool       `json:"protected"`
+	PinnedTweetID  *string    `json:"pinned_tweet_id,omitempty"`
+	Role           string     `json:"-"`
+	SuspendedAt    *time.Time `json:"-"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+
+	PinnedTweet *Tweet `json:"pinned_tweet,omitempty"`
 }
 
 type CreateUser struct {
-	Username          string `json:"username"`
-	Password          string `json:"password"`
-	Name              string `json:"name"`
-	Bio               string `json:"bio,omitempty"`
-	ProfilePicture    string `json:"profile_picture,omitempty"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Name     string `json:"name"`
+	Bio      string `json:"bio,omitempty"`
 }
 
 type UpdateUser struct {
-	ID                string  `json:"-"`
-	Name              *string `json:"name,omitempty"`
-	Bio               *string `json:"bio,omitempty"`
-	ProfilePicture    *string `json:"profile_picture,omitempty"`
+	ID   string  `json:"-"`
+	Name *string `json:"name,omitempty"`
+	Bio  *string `json:"bio,omitempty"`
+}
+
+// PinTweet pins a tweet of UserID to the top of their profile.
+type PinTweet struct {
+	UserID  string `json:"-"`
+	TweetID string `json:"tweet_id"`
 }
 
 type UsersResponse struct {
 	Users []User `json:"users"`
-	Count int   `json:"count"`
+	Count int    `json:"count"`
+}
+
+type UserSummary struct {
+	ID             string `json:"id"`
+	Username       string `json:"username"`
+	Name           string `json:"name"`
+	ProfilePicture string `json:"profile_picture"`
+	Protected      bool   `json:"protected"`
+}
+
+type UserSummariesResponse struct {
+	Users []UserSummary `json:"users"`
+	Count int           `json:"count"`
+}
+
+type TypeaheadRequest struct {
+	Query    string
+	ViewerID string
+	Limit    int
+}
+
+type TypeaheadUser struct {
+	UserSummary
+	Following     bool `json:"following"`
+	FollowerCount int  `json:"follower_count"`
+}
+
+type TypeaheadResponse struct {
+	Users []TypeaheadUser `json:"users"`
 }
 
 type UpdateUserPassword struct {
@@ -39,3 +82,24 @@ type UpdateUserPassword struct {
 	OldPassword string `json:"old_password"`
 }
 
+const (
+	// AltTextReminderNudge rejects tweets with images lacking alt text unless
+	// the user chooses to post them anyway, AltTextReminderRequire always
+	// does.
+	AltTextReminderOff     = "off"
+	AltTextReminderNudge   = "nudge"
+	AltTextReminderRequire = "require"
+)
+
+type UserSettings struct {
+	Protected           bool   `json:"protected"`
+	DMFromFollowingOnly bool   `json:"dm_from_following_only"`
+	AltTextReminder     string `json:"alt_text_reminder"`
+}
+
+type UpdateUserSettings struct {
+	ID                  string  `json:"-"`
+	Protected           *bool   `json:"protected,omitempty"`
+	DMFromFollowingOnly *bool   `json:"dm_from_following_only,omitempty"`
+	AltTextReminder     *string `json:"alt_text_reminder,omitempty"`
+}
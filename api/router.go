@@ -1,13 +1,18 @@
 package api
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"net/url"
+	"strings"
 	_ "test/api/docs"
 	"test/api/handler"
+	"test/pkg/jwt"
 	"test/pkg/logger"
+	"test/pkg/pubsub"
 	"test/service"
 	"time"
 
@@ -23,13 +28,16 @@ import (
 // @securityDefinitions.apikey ApiKeyAuth
 // @in header
 // @name Authorization
-func New(services service.IServiceManager, log logger.ILogger) *gin.Engine {
-	h := handler.New(services, log)
+func New(services service.IServiceManager, hub *pubsub.Hub, log logger.ILogger) *gin.Engine {
+	h := handler.New(services, hub, log)
+
+	authenticateMiddleware := authenticate(services)
+	optionalAuthMiddleware := optionalAuth(services)
 
 	r := gin.New()
 
 	//r.Use(authenticateMiddleware)
-	r.Use(gin.Logger())
+	r.Use(gin.LoggerWithFormatter(logFormatter))
 
 	{
 		// auth endpoints
@@ -37,33 +45,155 @@ func New(services service.IServiceManager, log logger.ILogger) *gin.Engine {
 
 		// user endpoints
 		r.POST("/user", h.CreateUser)
-		r.GET("/user/:id", h.GetUser)
-		r.GET("/users", h.GetUserList)
+		r.GET("/user/:id", optionalAuthMiddleware, h.GetUser)
+		r.GET("/users", optionalAuthMiddleware, h.GetUserList)
+		r.GET("/users/typeahead", optionalAuthMiddleware, h.GetUserTypeahead)
 		r.PUT("/user/:id", h.UpdateUser)
 		r.DELETE("/user/:id", h.DeleteUser)
 		r.PATCH("/user/:id", h.UpdateUserPassword)
+		r.GET("/user/me/settings", authenticateMiddleware, h.GetUserSettings)
+		r.PUT("/user/me/settings", authenticateMiddleware, h.UpdateUserSettings)
+		r.PUT("/user/me/avatar", authenticateMiddleware, h.UploadAvatar)
+		r.DELETE("/user/me/avatar", authenticateMiddleware, h.DeleteAvatar)
+		r.PUT("/user/me/banner", authenticateMiddleware, h.UploadBanner)
+		r.DELETE("/user/me/banner", authenticateMiddleware, h.DeleteBanner)
+		r.PUT("/user/me/pin", authenticateMiddleware, h.PinTweet)
+		r.DELETE("/user/me/pin", authenticateMiddleware, h.UnpinTweet)
+		r.GET("/user/:id/avatar", h.GetAvatar)
+		r.GET("/user/:id/banner", h.GetBanner)
 
 		// tweets endpoints
-		r.POST("/tweet", h.CreateTweet)
-		r.GET("/tweet/:id", h.GetTweet)
-		r.GET("/tweets", h.GetTweetList)
-		r.PUT("/tweet/:id", h.UpdateTweet)
-		r.DELETE("/tweet/:id", h.DeleteTweet)
+		r.POST("/tweet", authenticateMiddleware, h.CreateTweet)
+		r.GET("/tweet/:id", optionalAuthMiddleware, h.GetTweet)
+		r.GET("/tweets", optionalAuthMiddleware, h.GetTweetList)
+		r.PUT("/tweet/:id", authenticateMiddleware, h.UpdateTweet)
+		r.PUT("/tweet/:id/reply-audience", authenticateMiddleware, h.UpdateTweetReplyAudience)
+		r.GET("/tweet/:id/history", optionalAuthMiddleware, h.GetTweetHistory)
+		r.DELETE("/tweet/:id", authenticateMiddleware, h.DeleteTweet)
+		r.GET("/mentions", authenticateMiddleware, h.GetMentions)
 
 		// likes endpoints
-		r.POST("/like", h.CreateLike)
-		r.GET("/like/:id", h.GetLike)
-		r.DELETE("/like", h.DeleteLike)
+		r.POST("/like", authenticateMiddleware, h.CreateLike)
+		r.GET("/like/:id", optionalAuthMiddleware, h.GetLike)
+		r.DELETE("/like/:id", authenticateMiddleware, h.DeleteLike)
+		r.PUT("/tweet/:id/like", authenticateMiddleware, h.LikeTweet)
+		r.DELETE("/tweet/:id/like", authenticateMiddleware, h.UnlikeTweet)
+		r.GET("/tweet/:id/likes", optionalAuthMiddleware, h.GetTweetLikes)
+		r.GET("/user/:id/likes", optionalAuthMiddleware, h.GetUserLikes)
 
 		// followers endpoints
-		r.POST("/follower", h.CreateFollower)
-		r.GET("/follower/:id", h.GetFollower)
-		r.GET("/followers", h.GetFollowerList)
-		r.PUT("/follower/:id", h.DeleteFollower)
+		r.POST("/follower", authenticateMiddleware, h.CreateFollower)
+		r.DELETE("/follower/:id", authenticateMiddleware, h.DeleteFollower)
+		r.DELETE("/user/:id/follow", authenticateMiddleware, h.UnfollowUser)
+		r.GET("/user/:id/followers", optionalAuthMiddleware, h.GetUserFollowers)
+		r.GET("/user/:id/following", optionalAuthMiddleware, h.GetUserFollowing)
+		r.GET("/user/:id/mutuals", optionalAuthMiddleware, h.GetUserMutuals)
+		r.GET("/relationship", authenticateMiddleware, h.GetRelationship)
+
+		// follow requests endpoints
+		r.GET("/follow-requests/incoming", authenticateMiddleware, h.GetIncomingFollowRequests)
+		r.GET("/follow-requests/outgoing", authenticateMiddleware, h.GetOutgoingFollowRequests)
+		r.POST("/follow-request/:id/accept", authenticateMiddleware, h.AcceptFollowRequest)
+		r.POST("/follow-request/:id/reject", authenticateMiddleware, h.RejectFollowRequest)
+		r.DELETE("/follow-request/:id", authenticateMiddleware, h.CancelFollowRequest)
+
+		// blocks endpoints
+		r.POST("/user/:id/block", authenticateMiddleware, h.BlockUser)
+		r.DELETE("/user/:id/block", authenticateMiddleware, h.UnblockUser)
+		r.GET("/blocks", authenticateMiddleware, h.GetBlockList)
+
+		// mutes endpoints
+		r.POST("/user/:id/mute", authenticateMiddleware, h.MuteUser)
+		r.DELETE("/user/:id/mute", authenticateMiddleware, h.UnmuteUser)
+		r.GET("/mutes/users", authenticateMiddleware, h.GetMutedUsers)
+		r.POST("/mutes/keywords", authenticateMiddleware, h.MuteKeyword)
+		r.GET("/mutes/keywords", authenticateMiddleware, h.GetMutedKeywords)
+		r.DELETE("/mutes/keyword/:id", authenticateMiddleware, h.UnmuteKeyword)
+		r.POST("/tweet/:id/mute", authenticateMiddleware, h.MuteConversation)
+		r.DELETE("/tweet/:id/mute", authenticateMiddleware, h.UnmuteConversation)
+
+		// notifications endpoints
+		r.GET("/notifications", authenticateMiddleware, h.GetNotifications)
+		r.POST("/notifications/read", authenticateMiddleware, h.MarkNotificationsRead)
+
+		// direct messages endpoints
+		r.POST("/dm/conversation", authenticateMiddleware, h.CreateConversation)
+		r.GET("/dm/conversations", authenticateMiddleware, h.GetConversations)
+		r.GET("/dm/conversation/:id", authenticateMiddleware, h.GetConversation)
+		r.GET("/dm/conversation/:id/messages", authenticateMiddleware, h.GetMessages)
+		r.POST("/dm/conversation/:id/messages", authenticateMiddleware, h.SendMessage)
+		r.POST("/dm/conversation/:id/read", authenticateMiddleware, h.MarkConversationRead)
+		r.POST("/dm/conversation/:id/leave", authenticateMiddleware, h.LeaveConversation)
+
+		// bookmarks endpoints
+		r.PUT("/tweet/:id/bookmark", authenticateMiddleware, h.BookmarkTweet)
+		r.DELETE("/tweet/:id/bookmark", authenticateMiddleware, h.UnbookmarkTweet)
+		r.GET("/bookmarks", authenticateMiddleware, h.GetBookmarks)
+		r.POST("/bookmarks/folders", authenticateMiddleware, h.CreateBookmarkFolder)
+		r.GET("/bookmarks/folders", authenticateMiddleware, h.GetBookmarkFolders)
+		r.PUT("/bookmarks/folders/order", authenticateMiddleware, h.ReorderBookmarkFolders)
+		r.PUT("/bookmarks/folder/:id", authenticateMiddleware, h.RenameBookmarkFolder)
+		r.DELETE("/bookmarks/folder/:id", authenticateMiddleware, h.DeleteBookmarkFolder)
+
+		// lists endpoints
+		r.POST("/list", authenticateMiddleware, h.CreateList)
+		r.GET("/list/:id", optionalAuthMiddleware, h.GetList)
+		r.PUT("/list/:id", authenticateMiddleware, h.UpdateList)
+		r.DELETE("/list/:id", authenticateMiddleware, h.DeleteList)
+		r.GET("/user/:id/lists", optionalAuthMiddleware, h.GetUserLists)
+		r.GET("/lists/subscriptions", authenticateMiddleware, h.GetSubscribedLists)
+		r.PUT("/list/:id/member/:user_id", authenticateMiddleware, h.AddListMember)
+		r.DELETE("/list/:id/member/:user_id", authenticateMiddleware, h.RemoveListMember)
+		r.GET("/list/:id/members", optionalAuthMiddleware, h.GetListMembers)
+		r.PUT("/list/:id/subscribe", authenticateMiddleware, h.SubscribeList)
+		r.DELETE("/list/:id/subscribe", authenticateMiddleware, h.UnsubscribeList)
+		r.GET("/list/:id/timeline", optionalAuthMiddleware, h.GetListTimeline)
+
+		// trends endpoints
+		r.GET("/trends", h.GetTrends)
+
+		// suggestions endpoints
+		r.GET("/suggestions/users", authenticateMiddleware, h.GetUserSuggestions)
+
+		// media endpoints
+		r.POST("/media", authenticateMiddleware, h.UploadMedia)
+		r.POST("/media/upload", authenticateMiddleware, h.InitMediaUpload)
+		r.PUT("/media/:id/chunk", authenticateMiddleware, h.AppendMediaChunk)
+		r.POST("/media/:id/finalize", authenticateMiddleware, h.FinalizeMediaUpload)
+		r.PUT("/media/:id/alt-text", authenticateMiddleware, h.UpdateMediaAltText)
+		r.GET("/media/:id", authenticateMiddleware, h.GetMedia)
+		r.GET("/media/files/*key", h.GetMediaFile)
+
+		// drafts endpoints
+		r.POST("/draft", authenticateMiddleware, h.CreateDraft)
+		r.GET("/drafts", authenticateMiddleware, h.GetDrafts)
+		r.GET("/draft/:id", authenticateMiddleware, h.GetDraft)
+		r.PUT("/draft/:id", authenticateMiddleware, h.UpdateDraft)
+		r.DELETE("/draft/:id", authenticateMiddleware, h.DeleteDraft)
+		r.POST("/draft/:id/publish", authenticateMiddleware, h.PublishDraft)
+
+		// reports endpoints
+		r.POST("/report", authenticateMiddleware, h.CreateReport)
+		r.GET("/moderation/reports", authenticateMiddleware, h.GetReports)
+		r.POST("/moderation/reports/claim", authenticateMiddleware, h.ClaimNextReport)
+		r.GET("/moderation/report/:id", authenticateMiddleware, h.GetReport)
+		r.POST("/moderation/report/:id/claim", authenticateMiddleware, h.ClaimReport)
+		r.POST("/moderation/report/:id/resolve", authenticateMiddleware, h.ResolveReport)
+		r.GET("/moderation/actions", authenticateMiddleware, h.GetModerationActions)
+
+		// polls endpoints
+		r.POST("/tweet/:id/poll/vote", authenticateMiddleware, h.VotePoll)
+
+		// stream endpoints
+		r.GET("/stream", queryTokenMiddleware, authenticateMiddleware, h.Stream)
+		r.GET("/stream/ws", queryTokenMiddleware, authenticateMiddleware, h.StreamWebSocket)
 
 		//retweets  endpoints
-		r.POST("/retweet", h.CreateRetweet)
-		r.DELETE("/retweet/:id", h.DeleteRetweet)
+		r.POST("/retweet", authenticateMiddleware, h.CreateRetweet)
+		r.DELETE("/retweet/:id", authenticateMiddleware, h.DeleteRetweet)
+		r.PUT("/tweet/:id/retweet", authenticateMiddleware, h.RetweetTweet)
+		r.DELETE("/tweet/:id/retweet", authenticateMiddleware, h.UnretweetTweet)
+		r.GET("/tweet/:id/retweets", optionalAuthMiddleware, h.GetTweetRetweets)
 
 		r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 	}
@@ -71,15 +201,125 @@ func New(services service.IServiceManager, log logger.ILogger) *gin.Engine {
 	return r
 }
 
-func authenticateMiddleware(c *gin.Context) {
-	auth := c.GetHeader("Authorization")
-	if auth == "" {
-		c.AbortWithError(http.StatusUnauthorized, errors.New("unauthorized"))
-	} else {
+var errUnauthorized = errors.New("unauthorized")
+
+// authenticate returns the middleware that only lets through requests with
+// the access token of a user who is not suspended.
+func authenticate(services service.IServiceManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := setAuthInfo(c, services); err != nil {
+			status := http.StatusUnauthorized
+			switch {
+			case errors.Is(err, service.ErrForbidden):
+				status = http.StatusForbidden
+			case !errors.Is(err, errUnauthorized):
+				status = http.StatusInternalServerError
+			}
+			c.AbortWithError(status, err)
+			return
+		}
+
 		c.Next()
 	}
 }
 
+// optionalAuth returns the middleware that identifies the user when a valid
+// token is sent and lets anonymous requests through otherwise.
+func optionalAuth(services service.IServiceManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		setAuthInfo(c, services)
+
+		c.Next()
+	}
+}
+
+// setAuthInfo stores the user_id and user_role claims of the access token in
+// the context. Refresh tokens are refused, and so are the tokens of suspended
+// users, read from the database so that a suspension takes effect right away.
+func setAuthInfo(c *gin.Context, services service.IServiceManager) error {
+	auth := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if auth == "" {
+		return errUnauthorized
+	}
+
+	claims, err := jwt.ExtractClaims(auth)
+	if err != nil || claims == nil {
+		return errUnauthorized
+	}
+
+	if tokenType, _ := claims["token_type"].(string); tokenType != jwt.AccessToken {
+		return errUnauthorized
+	}
+
+	userID, ok := claims["user_id"].(string)
+	if !ok || userID == "" {
+		return errUnauthorized
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	if err = services.AuthService().CheckActive(ctx, userID); err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			return errUnauthorized
+		}
+		return err
+	}
+
+	c.Set("user_id", userID)
+	if userRole, ok := claims["user_role"].(string); ok {
+		c.Set("user_role", userRole)
+	}
+
+	return nil
+}
+
+// queryTokenMiddleware accepts the access token as the access_token query
+// parameter, since browsers cannot set headers on EventSource and WebSocket
+// requests.
+func queryTokenMiddleware(c *gin.Context) {
+	if c.GetHeader("Authorization") == "" {
+		if token := c.Query("access_token"); token != "" {
+			c.Request.Header.Set("Authorization", token)
+		}
+	}
+
+	c.Next()
+}
+
+// logFormatter formats access log lines like gin's default logger, leaving
+// out the access tokens that queryTokenMiddleware accepts in the query string.
+func logFormatter(param gin.LogFormatterParams) string {
+	return fmt.Sprintf("[GIN] %v | %3d | %13v | %15s | %-7s %#v\n%s",
+		param.TimeStamp.Format("2006/01/02 - 15:04:05"),
+		param.StatusCode,
+		param.Latency,
+		param.ClientIP,
+		param.Method,
+		redactAccessToken(param.Path),
+		param.ErrorMessage,
+	)
+}
+
+// redactAccessToken replaces the value of the access_token query parameter of
+// path.
+func redactAccessToken(path string) string {
+	base, rawQuery, ok := strings.Cut(path, "?")
+	if !ok || !strings.Contains(rawQuery, "access_token") {
+		return path
+	}
+
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return base + "?[unparsable query]"
+	}
+
+	if _, ok = query["access_token"]; ok {
+		query.Set("access_token", "REDACTED")
+	}
+
+	return base + "?" + query.Encode()
+}
+
 func traceRequest(c *gin.Context) {
 	beforeRequest(c)
 
@@ -4,9 +4,18 @@ import (
 	"context"
 	"test/api"
 	"test/config"
+	"test/pkg/blob"
+	"test/pkg/cache"
 	"test/pkg/logger"
+	"test/pkg/pubsub"
+	"test/pkg/trends"
 	"test/service"
 	"test/storage/postgres"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/redis/go-redis/v9"
 )
 
 func main() {
@@ -21,12 +30,132 @@ func main() {
 	}
 	defer pgStore.Close()
 
-	services := service.New(pgStore, log)
+	redisClient := newRedisClient(cfg)
+
+	hub := pubsub.NewHub(newPubSubBackend(cfg, redisClient), log)
+	go func() {
+		if err := hub.Run(context.Background()); err != nil {
+			log.Error("error while running pubsub hub", logger.Error(err))
+		}
+	}()
+
+	tracker := trends.NewTracker(newTrendsStore(cfg, redisClient), cfg.TrendsBlockedTerms, log)
+	go func() {
+		if err := tracker.Run(context.Background()); err != nil {
+			log.Error("error while running trends tracker", logger.Error(err))
+		}
+	}()
+
+	blobs, err := newBlobStore(context.Background(), cfg)
+	if err != nil {
+		log.Error("error while connecting to media storage", logger.Error(err))
+		return
+	}
+
+	services := service.New(pgStore, log, hub, newCache(cfg, redisClient), tracker, blobs, service.TweetEditPolicy{
+		Window:   cfg.TweetEditWindow,
+		MaxEdits: cfg.TweetMaxEdits,
+	})
+
+	go func() {
+		if err := services.Suggestions().RunJob(context.Background()); err != nil {
+			log.Error("error while running user suggestions job", logger.Error(err))
+		}
+	}()
+
+	go func() {
+		if err := services.Polls().RunJob(context.Background()); err != nil {
+			log.Error("error while running polls job", logger.Error(err))
+		}
+	}()
 
-	server := api.New(services, log)
+	go func() {
+		if err := services.Drafts().RunScheduler(context.Background()); err != nil {
+			log.Error("error while running drafts scheduler", logger.Error(err))
+		}
+	}()
+
+	go func() {
+		if err := services.Media().RunProcessor(context.Background(), cfg.MediaWorkers); err != nil {
+			log.Error("error while running media processor", logger.Error(err))
+		}
+	}()
+
+	server := api.New(services, hub, log)
 
 	log.Info("Service is running on", logger.Int("port", 8080))
 	if err = server.Run("localhost:8080"); err != nil {
 		panic(err)
 	}
 }
+
+// newRedisClient connects to Redis when it is configured.
+func newRedisClient(cfg config.Config) *redis.Client {
+	if cfg.RedisHost == "" {
+		return nil
+	}
+
+	return redis.NewClient(&redis.Options{
+		Addr:     cfg.RedisHost + ":" + cfg.RedisPort,
+		Password: cfg.RedisPassword,
+	})
+}
+
+// newPubSubBackend shares stream events between instances through Redis when
+// it is configured and keeps them in process otherwise.
+func newPubSubBackend(cfg config.Config, client *redis.Client) pubsub.Backend {
+	if client == nil {
+		return pubsub.NewMemoryBackend()
+	}
+
+	return pubsub.NewRedisBackend(client, cfg.ServiceName+":stream")
+}
+
+// newCache shares cached values between instances through Redis when it is
+// configured and keeps them in process otherwise.
+func newCache(cfg config.Config, client *redis.Client) cache.Cache {
+	if client == nil {
+		return cache.NewMemoryCache(10000)
+	}
+
+	return cache.NewRedisCache(client, cfg.ServiceName+":cache:")
+}
+
+// newTrendsStore counts trends over every instance through Redis when it is
+// configured and over this instance otherwise.
+func newTrendsStore(cfg config.Config, client *redis.Client) trends.Store {
+	if client == nil {
+		return trends.NewMemoryStore(trends.Retention)
+	}
+
+	return trends.NewRedisStore(client, cfg.ServiceName+":trends:", trends.BucketSize*time.Duration(trends.Retention+1))
+}
+
+// newBlobStore keeps media in the configured S3 compatible bucket, created
+// when missing, or in a local directory.
+func newBlobStore(ctx context.Context, cfg config.Config) (blob.Store, error) {
+	if cfg.MediaStorage != "s3" {
+		return blob.NewLocalStore(cfg.MediaLocalDir, cfg.MediaBaseURL), nil
+	}
+
+	client, err := minio.New(cfg.S3Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.S3AccessKey, cfg.S3SecretKey, ""),
+		Secure: cfg.S3UseSSL,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	exists, err := client.BucketExists(ctx, cfg.S3Bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	if !exists {
+		if err = client.MakeBucket(ctx, cfg.S3Bucket, minio.MakeBucketOptions{}); err != nil {
+			return nil, err
+		}
+	}
+
+	return blob.NewS3Store(client, cfg.S3Bucket, cfg.MediaBaseURL), nil
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"github.com/spf13/cast"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -21,6 +23,22 @@ type Config struct {
 	RedisHost     string
 	RedisPort     string
 	RedisPassword string
+
+	TrendsBlockedTerms []string
+
+	MediaStorage  string
+	MediaLocalDir string
+	MediaBaseURL  string
+	MediaWorkers  int
+
+	TweetEditWindow time.Duration
+	TweetMaxEdits   int
+
+	S3Endpoint  string
+	S3AccessKey string
+	S3SecretKey string
+	S3Bucket    string
+	S3UseSSL    bool
 }
 
 func Load() Config {
@@ -39,7 +57,25 @@ func Load() Config {
 	cfg.ServiceName = cast.ToString(getOrReturnDefault("SERVICE_NAME", "minitwiter"))
 	cfg.LoggerLevel = cast.ToString(getOrReturnDefault("LOGGER_LEVEL", "debug"))
 
-	
+	cfg.RedisHost = cast.ToString(getOrReturnDefault("REDIS_HOST", ""))
+	cfg.RedisPort = cast.ToString(getOrReturnDefault("REDIS_PORT", "6379"))
+	cfg.RedisPassword = cast.ToString(getOrReturnDefault("REDIS_PASSWORD", ""))
+
+	cfg.TrendsBlockedTerms = strings.Split(cast.ToString(getOrReturnDefault("TRENDS_BLOCKED_TERMS", "")), ",")
+
+	cfg.MediaStorage = cast.ToString(getOrReturnDefault("MEDIA_STORAGE", "local"))
+	cfg.MediaLocalDir = cast.ToString(getOrReturnDefault("MEDIA_LOCAL_DIR", "./media"))
+	cfg.MediaBaseURL = cast.ToString(getOrReturnDefault("MEDIA_BASE_URL", "/media/files"))
+	cfg.MediaWorkers = cast.ToInt(getOrReturnDefault("MEDIA_WORKERS", "4"))
+
+	cfg.TweetEditWindow = cast.ToDuration(getOrReturnDefault("TWEET_EDIT_WINDOW", "30m"))
+	cfg.TweetMaxEdits = cast.ToInt(getOrReturnDefault("TWEET_MAX_EDITS", "5"))
+
+	cfg.S3Endpoint = cast.ToString(getOrReturnDefault("S3_ENDPOINT", "localhost:9000"))
+	cfg.S3AccessKey = cast.ToString(getOrReturnDefault("S3_ACCESS_KEY", ""))
+	cfg.S3SecretKey = cast.ToString(getOrReturnDefault("S3_SECRET_KEY", ""))
+	cfg.S3Bucket = cast.ToString(getOrReturnDefault("S3_BUCKET", "media"))
+	cfg.S3UseSSL = cast.ToBool(getOrReturnDefault("S3_USE_SSL", "false"))
 
 	return cfg
 }
@@ -0,0 +1,5 @@
+alter table likes
+    drop constraint if exists likes_tweet_id_user_id_key;
+
+alter table retweets
+    drop constraint if exists retweets_tweet_id_user_id_key;
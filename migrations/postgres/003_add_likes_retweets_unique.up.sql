@@ -0,0 +1,17 @@
+DELETE FROM likes a
+    USING likes b
+WHERE a.tweet_id = b.tweet_id
+  AND a.user_id = b.user_id
+  AND a.ctid > b.ctid;
+
+DELETE FROM retweets a
+    USING retweets b
+WHERE a.tweet_id = b.tweet_id
+  AND a.user_id = b.user_id
+  AND a.ctid > b.ctid;
+
+alter table likes
+    add constraint likes_tweet_id_user_id_key unique (tweet_id, user_id);
+
+alter table retweets
+    add constraint retweets_tweet_id_user_id_key unique (tweet_id, user_id);
@@ -0,0 +1,4 @@
+drop index if exists followers_follower_user_id_idx;
+
+alter table followers
+    drop constraint if exists followers_user_id_follower_user_id_key;
@@ -0,0 +1,10 @@
+DELETE FROM followers a
+    USING followers b
+WHERE a.user_id = b.user_id
+  AND a.follower_user_id = b.follower_user_id
+  AND a.ctid > b.ctid;
+
+alter table followers
+    add constraint followers_user_id_follower_user_id_key unique (user_id, follower_user_id);
+
+create index if not exists followers_follower_user_id_idx on followers (follower_user_id);
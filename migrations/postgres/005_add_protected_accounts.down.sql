@@ -0,0 +1,4 @@
+drop table if exists follow_requests;
+
+alter table users
+    drop column if exists protected;
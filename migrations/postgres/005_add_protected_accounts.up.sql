@@ -0,0 +1,12 @@
+alter table users
+    add column if not exists protected boolean not null default false;
+
+CREATE TABLE IF NOT EXISTS follow_requests (
+    follow_request_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    requester_user_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, requester_user_id)
+);
+
+create index if not exists follow_requests_requester_user_id_idx on follow_requests (requester_user_id);
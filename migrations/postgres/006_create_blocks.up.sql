@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS blocks (
+    block_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    blocked_user_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, blocked_user_id)
+);
+
+create index if not exists blocks_blocked_user_id_idx on blocks (blocked_user_id);
@@ -0,0 +1,3 @@
+alter table tweets
+    drop column if exists reply_to_tweet_id,
+    drop column if exists conversation_id;
@@ -0,0 +1,10 @@
+alter table tweets
+    add column if not exists reply_to_tweet_id uuid references tweets(tweet_id) on delete set null,
+    add column if not exists conversation_id uuid;
+
+update tweets
+set conversation_id = tweet_id
+where conversation_id is null;
+
+create index if not exists tweets_reply_to_tweet_id_idx on tweets (reply_to_tweet_id);
+create index if not exists tweets_conversation_id_idx on tweets (conversation_id);
@@ -0,0 +1,7 @@
+drop table if exists tweet_mentions;
+
+drop table if exists muted_conversations;
+
+drop table if exists muted_keywords;
+
+drop table if exists mutes;
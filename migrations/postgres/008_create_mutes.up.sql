@@ -0,0 +1,34 @@
+CREATE TABLE IF NOT EXISTS mutes (
+    mute_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    muted_user_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, muted_user_id)
+);
+
+CREATE TABLE IF NOT EXISTS muted_keywords (
+    muted_keyword_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    keyword VARCHAR(100) NOT NULL,
+    pattern TEXT NOT NULL,
+    expires_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, keyword)
+);
+
+CREATE TABLE IF NOT EXISTS muted_conversations (
+    muted_conversation_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    conversation_id UUID NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, conversation_id)
+);
+
+CREATE TABLE IF NOT EXISTS tweet_mentions (
+    tweet_id UUID NOT NULL REFERENCES tweets(tweet_id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (tweet_id, user_id)
+);
+
+create index if not exists tweet_mentions_user_id_idx on tweet_mentions (user_id, created_at desc);
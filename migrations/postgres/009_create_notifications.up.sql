@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS notifications (
+    notification_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    actor_user_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    type VARCHAR(20) NOT NULL,
+    tweet_id UUID REFERENCES tweets(tweet_id) ON DELETE CASCADE,
+    read_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+create unique index if not exists notifications_event_idx on notifications (user_id, actor_user_id, type, COALESCE(tweet_id, '00000000-0000-0000-0000-000000000000'));
+
+create index if not exists notifications_user_id_idx on notifications (user_id, created_at desc);
+
+create index if not exists notifications_unread_idx on notifications (user_id) where read_at is null;
@@ -0,0 +1,8 @@
+drop table if exists dm_messages;
+
+drop table if exists dm_members;
+
+drop table if exists dm_conversations;
+
+alter table users
+    drop column if exists dm_from_following_only;
@@ -0,0 +1,33 @@
+alter table users
+    add column if not exists dm_from_following_only boolean not null default false;
+
+CREATE TABLE IF NOT EXISTS dm_conversations (
+    conversation_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    is_group BOOLEAN NOT NULL DEFAULT false,
+    name VARCHAR(100) NOT NULL DEFAULT '',
+    direct_key VARCHAR(80) UNIQUE,
+    created_by UUID REFERENCES users(user_id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS dm_members (
+    conversation_id UUID NOT NULL REFERENCES dm_conversations(conversation_id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    last_read_at TIMESTAMP,
+    left_at TIMESTAMP,
+    joined_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (conversation_id, user_id)
+);
+
+CREATE TABLE IF NOT EXISTS dm_messages (
+    message_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    conversation_id UUID NOT NULL REFERENCES dm_conversations(conversation_id) ON DELETE CASCADE,
+    sender_user_id UUID REFERENCES users(user_id) ON DELETE SET NULL,
+    content TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+create index if not exists dm_members_user_id_idx on dm_members (user_id);
+
+create index if not exists dm_messages_conversation_id_idx on dm_messages (conversation_id, created_at desc);
@@ -0,0 +1,3 @@
+drop table if exists bookmarks;
+
+drop table if exists bookmark_folders;
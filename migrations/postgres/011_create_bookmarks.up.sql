@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS bookmark_folders (
+    folder_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    position INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS bookmarks (
+    bookmark_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    tweet_id UUID NOT NULL REFERENCES tweets(tweet_id) ON DELETE CASCADE,
+    folder_id UUID REFERENCES bookmark_folders(folder_id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, tweet_id)
+);
+
+create index if not exists bookmarks_user_id_idx on bookmarks (user_id, created_at desc, bookmark_id desc);
+
+create index if not exists bookmarks_folder_id_idx on bookmarks (folder_id, created_at desc, bookmark_id desc);
@@ -0,0 +1,7 @@
+drop index if exists tweets_user_id_created_at_idx;
+
+drop table if exists list_subscribers;
+
+drop table if exists list_members;
+
+drop table if exists lists;
@@ -0,0 +1,31 @@
+CREATE TABLE IF NOT EXISTS lists (
+    list_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    owner_user_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    name VARCHAR(25) NOT NULL,
+    description VARCHAR(100) NOT NULL DEFAULT '',
+    private BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS list_members (
+    list_id UUID NOT NULL REFERENCES lists(list_id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (list_id, user_id)
+);
+
+CREATE TABLE IF NOT EXISTS list_subscribers (
+    list_id UUID NOT NULL REFERENCES lists(list_id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (list_id, user_id)
+);
+
+create index if not exists lists_owner_user_id_idx on lists (owner_user_id, created_at desc);
+
+create index if not exists list_members_user_id_idx on list_members (user_id);
+
+create index if not exists list_subscribers_user_id_idx on list_subscribers (user_id, created_at desc);
+
+create index if not exists tweets_user_id_created_at_idx on tweets (user_id, created_at desc);
@@ -0,0 +1,4 @@
+drop index if exists tweets_search_vector_idx;
+
+alter table tweets
+    drop column if exists search_vector;
@@ -0,0 +1,4 @@
+alter table tweets
+    add column if not exists search_vector tsvector generated always as (to_tsvector('simple', content)) stored;
+
+create index if not exists tweets_search_vector_idx on tweets using gin (search_vector);
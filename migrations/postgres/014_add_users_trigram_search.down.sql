@@ -0,0 +1,3 @@
+drop index if exists users_name_trgm_idx;
+
+drop index if exists users_username_trgm_idx;
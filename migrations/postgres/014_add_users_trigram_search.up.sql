@@ -0,0 +1,5 @@
+create extension if not exists pg_trgm;
+
+create index if not exists users_username_trgm_idx on users using gin (lower(username) gin_trgm_ops);
+
+create index if not exists users_name_trgm_idx on users using gin (lower(name) gin_trgm_ops);
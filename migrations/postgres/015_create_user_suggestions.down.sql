@@ -0,0 +1,5 @@
+drop table if exists user_suggestion_runs;
+
+drop table if exists user_suggestions;
+
+drop table if exists tweet_hashtags;
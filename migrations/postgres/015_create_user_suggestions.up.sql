@@ -0,0 +1,38 @@
+CREATE TABLE IF NOT EXISTS tweet_hashtags (
+    tweet_id UUID NOT NULL REFERENCES tweets(tweet_id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    hashtag VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (tweet_id, hashtag)
+);
+
+create index if not exists tweet_hashtags_hashtag_idx on tweet_hashtags (hashtag, created_at desc);
+
+create index if not exists tweet_hashtags_user_id_idx on tweet_hashtags (user_id, created_at desc);
+
+INSERT INTO tweet_hashtags (tweet_id, user_id, hashtag, created_at)
+SELECT DISTINCT t.tweet_id, t.user_id, lower(m[1]), t.created_at
+FROM tweets t, regexp_matches(t.content, '(?:^|[^[:alnum:]_#])#([[:alnum:]_]*[[:alpha:]][[:alnum:]_]*)', 'g') m
+WHERE t.user_id IS NOT NULL AND length(m[1]) <= 100
+ON CONFLICT DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS user_suggestions (
+    user_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    suggested_user_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    score DOUBLE PRECISION NOT NULL,
+    followed_by_count INT NOT NULL DEFAULT 0,
+    followed_by_ids UUID[] NOT NULL DEFAULT '{}',
+    follows_you BOOLEAN NOT NULL DEFAULT FALSE,
+    shared_hashtags TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, suggested_user_id)
+);
+
+create index if not exists user_suggestions_score_idx on user_suggestions (user_id, score desc);
+
+CREATE TABLE IF NOT EXISTS user_suggestion_runs (
+    user_id UUID PRIMARY KEY REFERENCES users(user_id) ON DELETE CASCADE,
+    computed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+create index if not exists user_suggestion_runs_computed_at_idx on user_suggestion_runs (computed_at);
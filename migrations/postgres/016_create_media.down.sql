@@ -0,0 +1,3 @@
+drop table if exists media_chunks;
+
+drop table if exists media;
@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS media (
+    media_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    tweet_id UUID REFERENCES tweets(tweet_id) ON DELETE CASCADE,
+    position INT NOT NULL DEFAULT 0,
+    type VARCHAR(10) NOT NULL,
+    mime_type VARCHAR(50) NOT NULL,
+    size BIGINT NOT NULL,
+    received_size BIGINT NOT NULL DEFAULT 0,
+    state VARCHAR(20) NOT NULL DEFAULT 'uploading',
+    storage_key TEXT,
+    url TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+create index if not exists media_tweet_id_idx on media (tweet_id, position);
+
+create index if not exists media_user_id_idx on media (user_id, created_at desc);
+
+CREATE TABLE IF NOT EXISTS media_chunks (
+    media_id UUID NOT NULL REFERENCES media(media_id) ON DELETE CASCADE,
+    start_offset BIGINT NOT NULL,
+    size BIGINT NOT NULL,
+    storage_key TEXT NOT NULL,
+    PRIMARY KEY (media_id, start_offset)
+);
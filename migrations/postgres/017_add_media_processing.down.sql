@@ -0,0 +1,19 @@
+drop table if exists media_variants;
+
+drop index if exists media_processing_idx;
+
+UPDATE media SET state = 'ready' WHERE state = 'processing' AND url IS NOT NULL;
+
+UPDATE media SET state = 'failed' WHERE state = 'processing';
+
+alter table media drop column if exists last_error;
+
+alter table media drop column if exists next_attempt_at;
+
+alter table media drop column if exists attempts;
+
+alter table media drop column if exists blurhash;
+
+alter table media drop column if exists height;
+
+alter table media drop column if exists width;
@@ -0,0 +1,25 @@
+alter table media add column if not exists width INT;
+
+alter table media add column if not exists height INT;
+
+alter table media add column if not exists blurhash TEXT;
+
+alter table media add column if not exists attempts INT NOT NULL DEFAULT 0;
+
+alter table media add column if not exists next_attempt_at TIMESTAMP;
+
+alter table media add column if not exists last_error TEXT;
+
+create index if not exists media_processing_idx on media (next_attempt_at) where state = 'processing';
+
+CREATE TABLE IF NOT EXISTS media_variants (
+    media_id UUID NOT NULL REFERENCES media(media_id) ON DELETE CASCADE,
+    name VARCHAR(20) NOT NULL,
+    width INT NOT NULL,
+    height INT NOT NULL,
+    storage_key TEXT NOT NULL,
+    url TEXT NOT NULL,
+    PRIMARY KEY (media_id, name)
+);
+
+UPDATE media SET state = 'processing', next_attempt_at = NOW() WHERE type = 'image' AND state = 'ready';
@@ -0,0 +1,7 @@
+alter table users add column if not exists profile_picture VARCHAR(255);
+
+alter table users drop column if exists banner_media_id;
+
+alter table users drop column if exists avatar_media_id;
+
+alter table media drop column if exists purpose;
@@ -0,0 +1,7 @@
+alter table media add column if not exists purpose VARCHAR(10) NOT NULL DEFAULT 'tweet';
+
+alter table users add column if not exists avatar_media_id UUID REFERENCES media(media_id) ON DELETE SET NULL;
+
+alter table users add column if not exists banner_media_id UUID REFERENCES media(media_id) ON DELETE SET NULL;
+
+alter table users drop column if exists profile_picture;
@@ -0,0 +1,4 @@
+alter table users
+    drop column if exists alt_text_reminder;
+
+alter table media drop column if exists alt_text;
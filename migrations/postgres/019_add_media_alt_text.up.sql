@@ -0,0 +1,4 @@
+alter table media add column if not exists alt_text VARCHAR(1000);
+
+alter table users
+    add column if not exists alt_text_reminder VARCHAR(10) not null default 'off';
@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS polls (
+    tweet_id UUID PRIMARY KEY REFERENCES tweets(tweet_id) ON DELETE CASCADE,
+    ends_at TIMESTAMP NOT NULL,
+    closed_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS poll_options (
+    option_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tweet_id UUID NOT NULL REFERENCES polls(tweet_id) ON DELETE CASCADE,
+    position INT NOT NULL,
+    label VARCHAR(25) NOT NULL,
+    vote_count INT NOT NULL DEFAULT 0,
+    UNIQUE (tweet_id, position)
+);
+
+CREATE TABLE IF NOT EXISTS poll_votes (
+    tweet_id UUID NOT NULL REFERENCES polls(tweet_id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    option_id UUID NOT NULL REFERENCES poll_options(option_id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (tweet_id, user_id)
+);
+
+create index if not exists polls_open_idx on polls (ends_at) where closed_at is null;
+
+create index if not exists poll_votes_option_id_idx on poll_votes (option_id);
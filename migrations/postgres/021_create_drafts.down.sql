@@ -0,0 +1,3 @@
+alter table tweets drop column if exists draft_id;
+
+drop table if exists drafts;
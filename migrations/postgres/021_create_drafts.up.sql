@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS drafts (
+    draft_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    content TEXT NOT NULL DEFAULT '',
+    media_ids UUID[] NOT NULL DEFAULT '{}',
+    reply_to_tweet_id UUID,
+    poll JSONB,
+    ignore_alt_text_reminder BOOLEAN NOT NULL DEFAULT FALSE,
+    state VARCHAR(20) NOT NULL,
+    publish_at TIMESTAMP,
+    attempts INT NOT NULL DEFAULT 0,
+    lease_until TIMESTAMP,
+    last_error TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+create index if not exists drafts_user_id_idx on drafts (user_id, state, updated_at desc);
+
+create index if not exists drafts_due_idx on drafts (publish_at) where state = 'scheduled';
+
+create index if not exists drafts_publishing_idx on drafts (lease_until) where state = 'publishing';
+
+alter table tweets add column if not exists draft_id UUID UNIQUE;
@@ -0,0 +1,3 @@
+alter table tweets drop column if exists edit_count;
+
+drop table if exists tweet_edits;
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS tweet_edits (
+    edit_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tweet_id UUID NOT NULL REFERENCES tweets(tweet_id) ON DELETE CASCADE,
+    revision INT NOT NULL,
+    content TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (tweet_id, revision)
+);
+
+alter table tweets add column if not exists edit_count INT NOT NULL DEFAULT 0;
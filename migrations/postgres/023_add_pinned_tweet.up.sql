@@ -0,0 +1 @@
+alter table users add column if not exists pinned_tweet_id UUID REFERENCES tweets(tweet_id) ON DELETE SET NULL;
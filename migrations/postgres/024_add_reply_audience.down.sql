@@ -0,0 +1,3 @@
+alter table drafts drop column if exists reply_audience;
+
+alter table tweets drop column if exists reply_audience;
@@ -0,0 +1,3 @@
+alter table tweets add column if not exists reply_audience VARCHAR(20) NOT NULL DEFAULT 'everyone';
+
+alter table drafts add column if not exists reply_audience VARCHAR(20) NOT NULL DEFAULT 'everyone';
@@ -0,0 +1,9 @@
+drop table if exists moderation_actions;
+drop function if exists moderation_actions_immutable();
+
+drop table if exists reports;
+
+alter table tweets drop column if exists hidden_at;
+
+alter table users drop column if exists suspended_at;
+alter table users drop column if exists role;
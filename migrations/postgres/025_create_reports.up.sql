@@ -0,0 +1,54 @@
+alter table users add column if not exists role VARCHAR(20) NOT NULL DEFAULT 'user';
+alter table users add column if not exists suspended_at TIMESTAMP;
+
+alter table tweets add column if not exists hidden_at TIMESTAMP;
+
+CREATE TABLE IF NOT EXISTS reports (
+    report_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    reporter_user_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    target_type VARCHAR(20) NOT NULL,
+    target_id UUID NOT NULL,
+    target_user_id UUID,
+    target_content TEXT NOT NULL DEFAULT '',
+    reason VARCHAR(30) NOT NULL,
+    details TEXT NOT NULL DEFAULT '',
+    state VARCHAR(20) NOT NULL DEFAULT 'open',
+    claimed_by UUID,
+    claimed_at TIMESTAMP,
+    resolved_by UUID,
+    resolved_at TIMESTAMP,
+    resolution VARCHAR(20),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+create unique index if not exists reports_pending_target_idx on reports (reporter_user_id, target_type, target_id) where state <> 'resolved';
+
+create index if not exists reports_queue_idx on reports (state, created_at);
+
+CREATE TABLE IF NOT EXISTS moderation_actions (
+    action_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    moderator_user_id UUID NOT NULL,
+    report_id UUID,
+    action VARCHAR(20) NOT NULL,
+    target_type VARCHAR(20) NOT NULL,
+    target_id UUID NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+create index if not exists moderation_actions_created_at_idx on moderation_actions (created_at desc);
+
+create index if not exists moderation_actions_target_idx on moderation_actions (target_type, target_id, created_at desc);
+
+-- the audit log is append only: entries outlive the users, reports and content
+-- they refer to and can be neither changed nor removed
+create or replace function moderation_actions_immutable() returns trigger as $$
+begin
+    raise exception 'moderation actions cannot be changed';
+end;
+$$ language plpgsql;
+
+drop trigger if exists moderation_actions_immutable on moderation_actions;
+create trigger moderation_actions_immutable before update or delete on moderation_actions
+    for each row execute function moderation_actions_immutable();
@@ -0,0 +1,22 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// ErrNotFound is returned when no blob is stored under a key.
+var ErrNotFound = errors.New("blob not found")
+
+// Store keeps uploaded files. Keys are slash separated paths.
+type Store interface {
+	// Put stores size bytes read from r under key, replacing any blob stored
+	// there.
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the blob. Deleting a missing blob is not an error.
+	Delete(ctx context.Context, key string) error
+	// URL returns the address the blob is served from.
+	URL(key string) string
+}
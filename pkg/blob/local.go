@@ -0,0 +1,79 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+type localStore struct {
+	dir     string
+	baseURL string
+}
+
+// NewLocalStore keeps blobs as files under dir, served from baseURL.
+func NewLocalStore(dir, baseURL string) Store {
+	return localStore{dir: dir, baseURL: strings.TrimSuffix(baseURL, "/")}
+}
+
+// path maps the key to a file under the directory, whatever dots it contains.
+func (l localStore) path(key string) string {
+	return filepath.Join(l.dir, filepath.FromSlash(path.Clean("/"+key)))
+}
+
+// Put writes the blob to a temporary file renamed over the key once complete,
+// so readers never see a partial blob.
+func (l localStore) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	target := l.path(key)
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(target), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	written, err := io.Copy(file, r)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	if written != size {
+		return fmt.Errorf("blob %s: wrote %d bytes, expected %d", key, written, size)
+	}
+
+	return os.Rename(file.Name(), target)
+}
+
+func (l localStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	file, err := os.Open(l.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+
+	return file, err
+}
+
+func (l localStore) Delete(ctx context.Context, key string) error {
+	err := os.Remove(l.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	return err
+}
+
+func (l localStore) URL(key string) string {
+	return l.baseURL + "/" + strings.TrimPrefix(key, "/")
+}
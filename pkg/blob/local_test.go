@@ -0,0 +1,112 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func readBlob(t *testing.T, store Store, key string) string {
+	t.Helper()
+
+	file, err := store.Get(context.Background(), key)
+	if err != nil {
+		t.Fatalf("Get(%q) error = %v", key, err)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		t.Fatalf("reading %q error = %v", key, err)
+	}
+
+	return string(data)
+}
+
+func TestLocalStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewLocalStore(t.TempDir(), "http://localhost/media/")
+
+	if err := store.Put(ctx, "media/a.txt", strings.NewReader("first"), 5, "text/plain"); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if got := readBlob(t, store, "media/a.txt"); got != "first" {
+		t.Errorf("Get() = %q, want %q", got, "first")
+	}
+
+	if err := store.Put(ctx, "media/a.txt", strings.NewReader("second"), 6, "text/plain"); err != nil {
+		t.Fatalf("Put() replacing error = %v", err)
+	}
+	if got := readBlob(t, store, "media/a.txt"); got != "second" {
+		t.Errorf("Get() after replacing = %q, want %q", got, "second")
+	}
+
+	if err := store.Delete(ctx, "media/a.txt"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := store.Get(ctx, "media/a.txt"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() after Delete() error = %v, want %v", err, ErrNotFound)
+	}
+
+	if err := store.Delete(ctx, "media/a.txt"); err != nil {
+		t.Errorf("Delete() of a missing blob error = %v, want nil", err)
+	}
+
+	if got, want := store.URL("/media/a.txt"), "http://localhost/media/media/a.txt"; got != want {
+		t.Errorf("URL() = %q, want %q", got, want)
+	}
+}
+
+func TestLocalStorePutSizeMismatch(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store := NewLocalStore(dir, "")
+
+	for _, size := range []int64{3, 10} {
+		if err := store.Put(ctx, "short", strings.NewReader("abcde"), size, ""); err == nil {
+			t.Errorf("Put() of 5 bytes with size %d error = nil, want an error", size)
+		}
+	}
+
+	// a failed upload leaves neither the blob nor its temporary file behind
+	if _, err := store.Get(ctx, "short"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() after failed Put() error = %v, want %v", err, ErrNotFound)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir() error = %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("directory has %d entries after failed Put(), want 0", len(entries))
+	}
+}
+
+func TestLocalStoreKeyContainment(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	dir := filepath.Join(root, "blobs")
+	store := NewLocalStore(dir, "")
+
+	for _, key := range []string{"../escaped", "a/../../escaped", "/../../escaped"} {
+		if err := store.Put(ctx, key, strings.NewReader("x"), 1, ""); err != nil {
+			t.Fatalf("Put(%q) error = %v", key, err)
+		}
+
+		if _, err := os.Stat(filepath.Join(root, "escaped")); !errors.Is(err, os.ErrNotExist) {
+			t.Fatalf("Put(%q) wrote outside the store directory", key)
+		}
+
+		if got := readBlob(t, store, key); got != "x" {
+			t.Errorf("Get(%q) = %q, want %q", key, got, "x")
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "escaped")); err != nil {
+		t.Errorf("Stat() of the contained blob error = %v", err)
+	}
+}
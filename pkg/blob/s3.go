@@ -0,0 +1,53 @@
+package blob
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+)
+
+type s3Store struct {
+	client  *minio.Client
+	bucket  string
+	baseURL string
+}
+
+// NewS3Store keeps blobs as objects of the bucket of an S3 compatible
+// service, such as a local MinIO server, served from baseURL.
+func NewS3Store(client *minio.Client, bucket, baseURL string) Store {
+	return s3Store{client: client, bucket: bucket, baseURL: strings.TrimSuffix(baseURL, "/")}
+}
+
+func (s s3Store) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	_, err := s.client.PutObject(ctx, s.bucket, key, r, size, minio.PutObjectOptions{ContentType: contentType})
+	return err
+}
+
+// Get checks the object exists before returning it, as minio only reports
+// missing objects on the first read.
+func (s s3Store) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	object, err := s.client.GetObject(ctx, s.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = object.Stat(); err != nil {
+		object.Close()
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return object, nil
+}
+
+func (s s3Store) Delete(ctx context.Context, key string) error {
+	return s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{})
+}
+
+func (s s3Store) URL(key string) string {
+	return s.baseURL + "/" + strings.TrimPrefix(key, "/")
+}
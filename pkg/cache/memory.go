@@ -0,0 +1,75 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type memoryEntry struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+// memoryCache keeps at most maxEntries values in process.
+type memoryCache struct {
+	mu         sync.Mutex
+	entries    map[string]memoryEntry
+	maxEntries int
+}
+
+func NewMemoryCache(maxEntries int) Cache {
+	return &memoryCache{
+		entries:    map[string]memoryEntry{},
+		maxEntries: maxEntries,
+	}
+}
+
+func (m *memoryCache) Get(_ context.Context, key string) ([]byte, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, ok := m.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+
+	if time.Now().After(entry.expiresAt) {
+		delete(m.entries, key)
+		return nil, false, nil
+	}
+
+	return entry.value, true, nil
+}
+
+func (m *memoryCache) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.entries[key]; !ok && len(m.entries) >= m.maxEntries {
+		m.evict()
+	}
+
+	m.entries[key] = memoryEntry{value: value, expiresAt: time.Now().Add(ttl)}
+
+	return nil
+}
+
+// evict drops the expired entries, or an arbitrary one when none expired.
+func (m *memoryCache) evict() {
+	now := time.Now()
+	for key, entry := range m.entries {
+		if now.After(entry.expiresAt) {
+			delete(m.entries, key)
+		}
+	}
+
+	if len(m.entries) < m.maxEntries {
+		return
+	}
+
+	for key := range m.entries {
+		delete(m.entries, key)
+		return
+	}
+}
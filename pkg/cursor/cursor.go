@@ -0,0 +1,39 @@
+package cursor
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalid = errors.New("invalid cursor")
+
+// Encode returns an opaque cursor pointing at the item created at createdAt
+// with the given id, for listings ordered by creation time then id.
+func Encode(createdAt time.Time, id string) string {
+	raw := strconv.FormatInt(createdAt.UnixMicro(), 10) + "|" + id
+
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// Decode reads a cursor returned by Encode.
+func Decode(cursor string) (time.Time, string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, "", ErrInvalid
+	}
+
+	micros, id, found := strings.Cut(string(raw), "|")
+	if !found || id == "" {
+		return time.Time{}, "", ErrInvalid
+	}
+
+	unixMicro, err := strconv.ParseInt(micros, 10, 64)
+	if err != nil {
+		return time.Time{}, "", ErrInvalid
+	}
+
+	return time.UnixMicro(unixMicro).UTC(), id, nil
+}
@@ -0,0 +1,53 @@
+package cursor
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRoundTrip(t *testing.T) {
+	createdAt := time.Date(2024, 3, 1, 12, 30, 45, 123456789, time.UTC)
+
+	gotCreatedAt, gotID, err := Decode(Encode(createdAt, "b2c7e3e4-5d41-4a8f-9d0a-3f1c2b7a9e10"))
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+
+	// cursors keep microseconds, as timestamps do in the database
+	if want := createdAt.Truncate(time.Microsecond); !gotCreatedAt.Equal(want) {
+		t.Errorf("Decode() created at = %v, want %v", gotCreatedAt, want)
+	}
+
+	if gotID != "b2c7e3e4-5d41-4a8f-9d0a-3f1c2b7a9e10" {
+		t.Errorf("Decode() id = %q, want %q", gotID, "b2c7e3e4-5d41-4a8f-9d0a-3f1c2b7a9e10")
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	encode := func(raw string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(raw))
+	}
+
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{name: "empty", cursor: ""},
+		{name: "bad base64", cursor: "not base64!"},
+		{name: "padded base64", cursor: base64.URLEncoding.EncodeToString([]byte("1|id"))},
+		{name: "missing separator", cursor: encode("1709296245123456")},
+		{name: "empty id", cursor: encode("1709296245123456|")},
+		{name: "bad time", cursor: encode("yesterday|id")},
+		{name: "empty time", cursor: encode("|id")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := Decode(tt.cursor); !errors.Is(err, ErrInvalid) {
+				t.Errorf("Decode(%q) error = %v, want %v", tt.cursor, err, ErrInvalid)
+			}
+		})
+	}
+}
@@ -0,0 +1,212 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+
+	_ "image/gif"
+
+	"github.com/buckket/go-blurhash"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// ErrInvalidImage is returned for images that cannot be processed, however
+// many times it is tried.
+var ErrInvalidImage = errors.New("invalid image")
+
+const (
+	// maxPixels keeps small files that decode to huge images from exhausting
+	// memory.
+	maxPixels = 50_000_000
+
+	jpegQuality  = 90
+	blurhashSide = 32
+)
+
+// Size is a variant generated for an image, by the longest side it fits in.
+// Only variants smaller than the image are generated.
+type Size struct {
+	Name    string
+	MaxSide int
+}
+
+// Options tell how to process an image. Images are cropped around their
+// center to AspectRatio, their width divided by their height, unless it is
+// zero.
+type Options struct {
+	AspectRatio float64
+	Sizes       []Size
+}
+
+var (
+	TweetOptions = Options{
+		Sizes: []Size{{"thumb", 150}, {"small", 680}, {"medium", 1200}, {"large", 2048}},
+	}
+	AvatarOptions = Options{
+		AspectRatio: 1,
+		Sizes:       []Size{{"mini", 48}, {"normal", 96}, {"bigger", 200}, {"large", 400}},
+	}
+	BannerOptions = Options{
+		AspectRatio: 3,
+		Sizes:       []Size{{"small", 600}, {"medium", 1500}},
+	}
+)
+
+// Variant is a resized copy of an image.
+type Variant struct {
+	Name     string
+	Width    int
+	Height   int
+	MimeType string
+	Data     []byte
+}
+
+// Result is a processed image: its content without metadata, its dimensions,
+// a blurhash placeholder and its resized variants.
+type Result struct {
+	MimeType string
+	Data     []byte
+	Width    int
+	Height   int
+	Blurhash string
+	Variants []Variant
+}
+
+// Process decodes a JPEG, PNG, GIF or WebP image and strips its EXIF and other
+// metadata, such as GPS coordinates. JPEG images are turned as their EXIF
+// orientation says first. Cropped images are always encoded again, as PNG
+// when they may be transparent and JPEG otherwise.
+func Process(data []byte, options Options) (Result, error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return Result{}, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > maxPixels {
+		return Result{}, fmt.Errorf("%w: %dx%d pixels", ErrInvalidImage, config.Width, config.Height)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return Result{}, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+
+	result := Result{MimeType: "image/" + format}
+	variantMimeType := "image/jpeg"
+
+	switch format {
+	case "jpeg":
+		// re-encoding keeps nothing but the pixels
+		img = orient(img, jpegOrientation(data))
+		result.Data, err = encode(img, "image/jpeg")
+	case "png":
+		result.Data, err = encode(img, "image/png")
+		variantMimeType = "image/png"
+	case "webp":
+		result.Data, err = stripWebP(data)
+	case "gif":
+		// GIF has no EXIF and re-encoding would lose the animation
+		result.Data = data
+		variantMimeType = "image/png"
+	default:
+		return Result{}, fmt.Errorf("%w: unsupported format %s", ErrInvalidImage, format)
+	}
+	if err != nil {
+		return Result{}, err
+	}
+
+	if options.AspectRatio > 0 {
+		img = crop(img, options.AspectRatio)
+		result.MimeType = variantMimeType
+		if result.Data, err = encode(img, variantMimeType); err != nil {
+			return Result{}, err
+		}
+	}
+
+	bounds := img.Bounds()
+	result.Width, result.Height = bounds.Dx(), bounds.Dy()
+
+	for _, size := range options.Sizes {
+		if max(result.Width, result.Height) <= size.MaxSide {
+			continue
+		}
+
+		resized := resize(img, size.MaxSide, draw.CatmullRom)
+		encoded, err := encode(resized, variantMimeType)
+		if err != nil {
+			return Result{}, err
+		}
+
+		result.Variants = append(result.Variants, Variant{
+			Name:     size.Name,
+			Width:    resized.Bounds().Dx(),
+			Height:   resized.Bounds().Dy(),
+			MimeType: variantMimeType,
+			Data:     encoded,
+		})
+	}
+
+	// the placeholder is blurry anyway, a tiny copy is enough
+	result.Blurhash, err = blurhash.Encode(4, 3, resize(img, blurhashSide, draw.ApproxBiLinear))
+	if err != nil {
+		return Result{}, err
+	}
+
+	return result, nil
+}
+
+// crop cuts the largest part of the image of the aspect ratio around its
+// center.
+func crop(img image.Image, aspectRatio float64) image.Image {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+
+	cw, ch := w, int(float64(w)/aspectRatio)
+	if ch > h {
+		cw, ch = int(float64(h)*aspectRatio), h
+	}
+	cw, ch = max(cw, 1), max(ch, 1)
+
+	x, y := bounds.Min.X+(w-cw)/2, bounds.Min.Y+(h-ch)/2
+	dst := image.NewNRGBA(image.Rect(0, 0, cw, ch))
+	draw.Draw(dst, dst.Bounds(), img, image.Point{X: x, Y: y}, draw.Src)
+
+	return dst
+}
+
+// resize scales the image down so that its longest side is maxSide.
+func resize(img image.Image, maxSide int, scaler draw.Scaler) image.Image {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	if w >= h {
+		w, h = maxSide, max(1, h*maxSide/w)
+	} else {
+		w, h = max(1, w*maxSide/h), maxSide
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+	scaler.Scale(dst, dst.Bounds(), img, bounds, draw.Src, nil)
+
+	return dst
+}
+
+func encode(img image.Image, mimeType string) ([]byte, error) {
+	var buf bytes.Buffer
+
+	var err error
+	if mimeType == "image/png" {
+		err = png.Encode(&buf, img)
+	} else {
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
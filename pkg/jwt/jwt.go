@@ -6,6 +6,13 @@ import (
 	"time"
 )
 
+const (
+	// AccessToken and RefreshToken are the token_type claims of the tokens
+	// GenerateJWT issues, so that one is not accepted in place of the other.
+	AccessToken  = "access"
+	RefreshToken = "refresh"
+)
+
 func GenerateJWT(m map[interface{}]interface{}) (string, string, error) {
 	accessToken := jwt.New(jwt.SigningMethodHS256)
 	refreshToken := jwt.New(jwt.SigningMethodHS256)
@@ -18,9 +25,11 @@ func GenerateJWT(m map[interface{}]interface{}) (string, string, error) {
 		rClaims[key.(string)] = value
 	}
 
+	aClaims["token_type"] = AccessToken
 	aClaims["exp"] = time.Now().Add(config.AccessExpireTime).Unix()
 	aClaims["iat"] = time.Now().Unix()
 
+	rClaims["token_type"] = RefreshToken
 	rClaims["exp"] = time.Now().Add(config.RefreshExpireTime).Unix()
 	rClaims["iat"] = time.Now().Unix()
 
@@ -64,5 +73,10 @@ func ExtractClaims(tokenString string) (map[interface{}]interface{}, error) {
 		m["user_role"] = userRole
 	}
 
+	tokenType, ok := token.Claims.(jwt.MapClaims)["token_type"]
+	if ok {
+		m["token_type"] = tokenType
+	}
+
 	return m, nil
 }
@@ -0,0 +1,41 @@
+package pubsub
+
+import (
+	"context"
+	"sync"
+)
+
+// memoryBackend delivers events within a single instance.
+type memoryBackend struct {
+	mu     sync.RWMutex
+	handle func(payload []byte)
+}
+
+func NewMemoryBackend() Backend {
+	return &memoryBackend{}
+}
+
+func (m *memoryBackend) Publish(_ context.Context, payload []byte) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.handle != nil {
+		m.handle(payload)
+	}
+
+	return nil
+}
+
+func (m *memoryBackend) Subscribe(ctx context.Context, handle func(payload []byte)) error {
+	m.mu.Lock()
+	m.handle = handle
+	m.mu.Unlock()
+
+	<-ctx.Done()
+
+	m.mu.Lock()
+	m.handle = nil
+	m.mu.Unlock()
+
+	return nil
+}
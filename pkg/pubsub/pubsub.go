@@ -0,0 +1,205 @@
+package pubsub
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"test/pkg/logger"
+	"time"
+)
+
+const (
+	// subscriptionBuffer is how many events may wait for a slow client before
+	// the client is dropped.
+	subscriptionBuffer = 64
+
+	minResubscribeDelay = time.Second
+	maxResubscribeDelay = 30 * time.Second
+)
+
+// Event is a message pushed to connected users.
+type Event struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// Publisher pushes events to the users connected to any instance.
+type Publisher interface {
+	Publish(ctx context.Context, userIDs []string, eventType string, data interface{}) error
+}
+
+// Backend carries published events to the hubs of every instance, including
+// the one that published them.
+type Backend interface {
+	Publish(ctx context.Context, payload []byte) error
+	// Subscribe passes every published payload to handle until ctx is done.
+	Subscribe(ctx context.Context, handle func(payload []byte)) error
+}
+
+type envelope struct {
+	UserIDs []string `json:"user_ids"`
+	Event   Event    `json:"event"`
+}
+
+// Hub delivers the events received from its backend to the subscriptions of
+// the users connected to this instance.
+type Hub struct {
+	backend Backend
+	log     logger.ILogger
+
+	mu            sync.Mutex
+	subscriptions map[string]map[*Subscription]struct{}
+}
+
+func NewHub(backend Backend, log logger.ILogger) *Hub {
+	return &Hub{
+		backend:       backend,
+		log:           log,
+		subscriptions: make(map[string]map[*Subscription]struct{}),
+	}
+}
+
+// Run delivers published events until ctx is done. The backend is subscribed
+// to again whenever the subscription fails or ends, waiting longer after each
+// failure in a row, so that delivery resumes once the backend is back.
+func (h *Hub) Run(ctx context.Context) error {
+	delay := minResubscribeDelay
+	for {
+		start := time.Now()
+		err := h.backend.Subscribe(ctx, h.dispatch)
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		// a subscription that held up for a while is not a failure in a row
+		if time.Since(start) > maxResubscribeDelay {
+			delay = minResubscribeDelay
+		}
+
+		if err != nil {
+			h.log.Error("error while subscribing to pubsub backend", logger.Error(err), logger.Any("retry_in", delay))
+		} else {
+			h.log.Error("pubsub subscription ended", logger.Any("retry_in", delay))
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(delay):
+		}
+
+		delay *= 2
+		if delay > maxResubscribeDelay {
+			delay = maxResubscribeDelay
+		}
+	}
+}
+
+func (h *Hub) Publish(ctx context.Context, userIDs []string, eventType string, data interface{}) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(envelope{
+		UserIDs: userIDs,
+		Event:   Event{Type: eventType, Data: raw},
+	})
+	if err != nil {
+		return err
+	}
+
+	return h.backend.Publish(ctx, payload)
+}
+
+// Subscribe registers a connection of the user. The subscription must be
+// released with Unsubscribe once the connection is closed.
+func (h *Hub) Subscribe(userID string) *Subscription {
+	sub := &Subscription{
+		userID: userID,
+		events: make(chan Event, subscriptionBuffer),
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.subscriptions[userID] == nil {
+		h.subscriptions[userID] = make(map[*Subscription]struct{})
+	}
+	h.subscriptions[userID][sub] = struct{}{}
+
+	return sub
+}
+
+func (h *Hub) Unsubscribe(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.remove(sub)
+}
+
+func (h *Hub) dispatch(payload []byte) {
+	message := envelope{}
+	if err := json.Unmarshal(payload, &message); err != nil {
+		h.log.Error("error while decoding published event", logger.Error(err))
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, userID := range message.UserIDs {
+		for sub := range h.subscriptions[userID] {
+			select {
+			case sub.events <- message.Event:
+			default:
+				// the client does not keep up, drop it rather than
+				// blocking delivery to everyone else
+				sub.dropped = true
+				h.remove(sub)
+				h.log.Info("dropped slow stream subscriber", logger.String("user_id", userID))
+			}
+		}
+	}
+}
+
+// remove closes the subscription unless it was already removed. The caller
+// must hold h.mu.
+func (h *Hub) remove(sub *Subscription) {
+	subs, ok := h.subscriptions[sub.userID]
+	if !ok {
+		return
+	}
+
+	if _, ok = subs[sub]; !ok {
+		return
+	}
+
+	delete(subs, sub)
+	if len(subs) == 0 {
+		delete(h.subscriptions, sub.userID)
+	}
+	close(sub.events)
+}
+
+// Subscription receives the events of one connection.
+type Subscription struct {
+	userID  string
+	events  chan Event
+	dropped bool
+}
+
+// Events is closed when the subscription is released or dropped.
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Dropped reports whether the subscription was closed because the client did
+// not keep up. It is only meaningful once Events is closed.
+func (s *Subscription) Dropped() bool {
+	return s.dropped
+}
@@ -0,0 +1,46 @@
+package pubsub
+
+import (
+	"context"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisBackend delivers events to every instance subscribed to the channel.
+type redisBackend struct {
+	client  *redis.Client
+	channel string
+}
+
+func NewRedisBackend(client *redis.Client, channel string) Backend {
+	return &redisBackend{
+		client:  client,
+		channel: channel,
+	}
+}
+
+func (r *redisBackend) Publish(ctx context.Context, payload []byte) error {
+	return r.client.Publish(ctx, r.channel, payload).Err()
+}
+
+func (r *redisBackend) Subscribe(ctx context.Context, handle func(payload []byte)) error {
+	sub := r.client.Subscribe(ctx, r.channel)
+	defer sub.Close()
+
+	if _, err := sub.Receive(ctx); err != nil {
+		return err
+	}
+
+	messages := sub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case message, ok := <-messages:
+			if !ok {
+				return nil
+			}
+			handle([]byte(message.Payload))
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package search
+
+import (
+	"errors"
+	"html"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const dateLayout = "2006-01-02"
+
+// Highlight markers put around matched words by the database, they are control
+// characters so they cannot be confused with tweet content.
+const (
+	HighlightStart = "\x02"
+	HighlightStop  = "\x03"
+)
+
+var (
+	ErrInvalidDate = errors.New("since: and until: dates must look like 2006-01-30")
+
+	usernameRegexp = regexp.MustCompile(`^\w{1,255}$`)
+	hashtagRegexp  = regexp.MustCompile(`^[\pL\pN_]{1,100}$`)
+)
+
+// Query is a parsed tweet search. Text holds the words, "quoted phrases" and
+// -excluded words in websearch_to_tsquery syntax, the operators are split
+// out of it.
+type Query struct {
+	Text string
+
+	From        []string
+	ExcludeFrom []string
+
+	Hashtags        []string
+	ExcludeHashtags []string
+
+	Since *time.Time
+	Until *time.Time
+
+	Media        bool
+	ExcludeMedia bool
+}
+
+// Empty reports whether the query matches every tweet.
+func (q Query) Empty() bool {
+	return q.Text == "" && len(q.From) == 0 && len(q.ExcludeFrom) == 0 &&
+		len(q.Hashtags) == 0 && len(q.ExcludeHashtags) == 0 &&
+		q.Since == nil && q.Until == nil && !q.Media && !q.ExcludeMedia
+}
+
+// Parse reads a search query. Besides words, "phrases" and -excluded words it
+// understands from:username, #hashtag, since:2006-01-30, until:2006-01-30
+// (exclusive) and filter:media, the operators can be negated with a leading -.
+// Usernames and hashtags are lowercased.
+func Parse(raw string) (Query, error) {
+	query := Query{}
+	text := []string{}
+
+	for _, token := range tokenize(raw) {
+		negated := strings.HasPrefix(token, "-") && len(token) > 1
+		operator := strings.ToLower(strings.TrimPrefix(token, "-"))
+
+		switch {
+		case strings.HasPrefix(operator, "from:") && usernameRegexp.MatchString(strings.TrimPrefix(operator[len("from:"):], "@")):
+			username := strings.TrimPrefix(operator[len("from:"):], "@")
+			if negated {
+				query.ExcludeFrom = append(query.ExcludeFrom, username)
+			} else {
+				query.From = append(query.From, username)
+			}
+		case strings.HasPrefix(operator, "#") && hashtagRegexp.MatchString(operator[1:]):
+			if negated {
+				query.ExcludeHashtags = append(query.ExcludeHashtags, operator[1:])
+			} else {
+				query.Hashtags = append(query.Hashtags, operator[1:])
+				text = append(text, operator[1:])
+			}
+		case strings.HasPrefix(operator, "since:") && !negated:
+			date, err := time.Parse(dateLayout, operator[len("since:"):])
+			if err != nil {
+				return Query{}, ErrInvalidDate
+			}
+			query.Since = &date
+		case strings.HasPrefix(operator, "until:") && !negated:
+			date, err := time.Parse(dateLayout, operator[len("until:"):])
+			if err != nil {
+				return Query{}, ErrInvalidDate
+			}
+			query.Until = &date
+		case operator == "filter:media":
+			if negated {
+				query.ExcludeMedia = true
+			} else {
+				query.Media = true
+			}
+		case hasWord(token):
+			text = append(text, token)
+		}
+	}
+
+	query.Text = strings.Join(text, " ")
+
+	return query, nil
+}
+
+// tokenize splits the query on whitespace, keeping "quoted phrases" together.
+func tokenize(raw string) []string {
+	var (
+		tokens  = []string{}
+		current strings.Builder
+		quoted  bool
+	)
+
+	for _, r := range raw {
+		switch {
+		case r == '"':
+			current.WriteRune(r)
+			quoted = !quoted
+		case unicode.IsSpace(r) && !quoted:
+			if current.Len() > 0 {
+				tokens = append(tokens, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteRune(r)
+		}
+	}
+
+	if current.Len() > 0 {
+		token := current.String()
+		if quoted {
+			token += `"`
+		}
+		tokens = append(tokens, token)
+	}
+
+	return tokens
+}
+
+func hasWord(token string) bool {
+	return strings.IndexFunc(token, func(r rune) bool {
+		return unicode.IsLetter(r) || unicode.IsNumber(r)
+	}) >= 0
+}
+
+// Highlight escapes a snippet returned by ts_headline with the highlight
+// markers and wraps the matched words in <mark> tags.
+func Highlight(snippet string) string {
+	snippet = html.EscapeString(snippet)
+	snippet = strings.ReplaceAll(snippet, HighlightStart, "<mark>")
+
+	return strings.ReplaceAll(snippet, HighlightStop, "</mark>")
+}
+
+// EscapeLike escapes the wildcards of value for a LIKE pattern.
+func EscapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
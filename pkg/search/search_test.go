@@ -0,0 +1,172 @@
+package search
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func date(t *testing.T, value string) *time.Time {
+	t.Helper()
+
+	parsed, err := time.Parse(dateLayout, value)
+	if err != nil {
+		t.Fatalf("time.Parse(%q) error = %v", value, err)
+	}
+
+	return &parsed
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want Query
+	}{
+		{
+			name: "words",
+			raw:  "  hello   world ",
+			want: Query{Text: "hello world"},
+		},
+		{
+			name: "phrase",
+			raw:  `"exact phrase" rest`,
+			want: Query{Text: `"exact phrase" rest`},
+		},
+		{
+			name: "unbalanced quote closes the phrase",
+			raw:  `word "open phrase`,
+			want: Query{Text: `word "open phrase"`},
+		},
+		{
+			name: "excluded word",
+			raw:  "cats -dogs",
+			want: Query{Text: "cats -dogs"},
+		},
+		{
+			name: "from",
+			raw:  "from:Alice from:@bob",
+			want: Query{From: []string{"alice", "bob"}},
+		},
+		{
+			name: "excluded from",
+			raw:  "-from:@Alice news",
+			want: Query{Text: "news", ExcludeFrom: []string{"alice"}},
+		},
+		{
+			name: "invalid username is a word",
+			raw:  "from:a-b",
+			want: Query{Text: "from:a-b"},
+		},
+		{
+			name: "hashtag is also searched as a word",
+			raw:  "#GoLang",
+			want: Query{Text: "golang", Hashtags: []string{"golang"}},
+		},
+		{
+			name: "excluded hashtag",
+			raw:  "release -#Spam",
+			want: Query{Text: "release", ExcludeHashtags: []string{"spam"}},
+		},
+		{
+			name: "media",
+			raw:  "FILTER:MEDIA",
+			want: Query{Media: true},
+		},
+		{
+			name: "excluded media",
+			raw:  "cats -filter:media",
+			want: Query{Text: "cats", ExcludeMedia: true},
+		},
+		{
+			name: "dates",
+			raw:  "since:2024-01-02 until:2024-02-01",
+			want: Query{Since: date(t, "2024-01-02"), Until: date(t, "2024-02-01")},
+		},
+		{
+			name: "punctuation only",
+			raw:  `- -- "" #`,
+			want: Query{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.raw)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.raw, err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse(%q) = %+v, want %+v", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseInvalidDate(t *testing.T) {
+	for _, raw := range []string{
+		"since:yesterday",
+		"until:2024-13-01",
+		"news since:2024-1-2",
+		"until:",
+	} {
+		t.Run(raw, func(t *testing.T) {
+			if _, err := Parse(raw); !errors.Is(err, ErrInvalidDate) {
+				t.Errorf("Parse(%q) error = %v, want %v", raw, err, ErrInvalidDate)
+			}
+		})
+	}
+}
+
+func TestEmpty(t *testing.T) {
+	for raw, want := range map[string]bool{
+		"":              true,
+		"   ":           true,
+		"cats":          false,
+		"-filter:media": false,
+		"-from:alice":   false,
+	} {
+		query, err := Parse(raw)
+		if err != nil {
+			t.Fatalf("Parse(%q) error = %v", raw, err)
+		}
+
+		if got := query.Empty(); got != want {
+			t.Errorf("Parse(%q).Empty() = %v, want %v", raw, got, want)
+		}
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		snippet string
+		want    string
+	}{
+		{
+			snippet: "plain text",
+			want:    "plain text",
+		},
+		{
+			snippet: "a " + HighlightStart + "match" + HighlightStop + " here",
+			want:    "a <mark>match</mark> here",
+		},
+		{
+			snippet: `<script>alert("x")</script> & ` + HighlightStart + "it's" + HighlightStop,
+			want:    `&lt;script&gt;alert(&#34;x&#34;)&lt;/script&gt; &amp; <mark>it&#39;s</mark>`,
+		},
+	}
+
+	for _, tt := range tests {
+		if got := Highlight(tt.snippet); got != tt.want {
+			t.Errorf("Highlight(%q) = %q, want %q", tt.snippet, got, tt.want)
+		}
+	}
+}
+
+func TestEscapeLike(t *testing.T) {
+	if got, want := EscapeLike(`50%_off\`), `50\%\_off\\`; got != want {
+		t.Errorf("EscapeLike() = %q, want %q", got, want)
+	}
+}
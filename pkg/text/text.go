@@ -0,0 +1,41 @@
+package text
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	mentionRegexp = regexp.MustCompile(`(?:^|[^\w@])@(\w{1,255})`)
+	hashtagRegexp = regexp.MustCompile(`(?:^|[^\pL\pN_#])#([\pL\pN_]*\pL[\pL\pN_]*)`)
+)
+
+// Mentions returns the lowercased usernames mentioned in content, without the
+// leading @ and in order of first appearance.
+func Mentions(content string) []string {
+	return unique(mentionRegexp.FindAllStringSubmatch(content, -1))
+}
+
+// Hashtags returns the lowercased hashtags in content, without the leading #
+// and in order of first appearance. Hashtags need at least one letter.
+func Hashtags(content string) []string {
+	return unique(hashtagRegexp.FindAllStringSubmatch(content, -1))
+}
+
+func unique(matches [][]string) []string {
+	var (
+		seen   = map[string]bool{}
+		result = []string{}
+	)
+
+	for _, match := range matches {
+		value := strings.ToLower(match[1])
+		if seen[value] {
+			continue
+		}
+		seen[value] = true
+		result = append(result, value)
+	}
+
+	return result
+}
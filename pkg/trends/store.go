@@ -0,0 +1,151 @@
+package trends
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Store counts term usage in time buckets. A user using a term several times
+// in a bucket is counted once, so a single account cannot make a term trend.
+type Store interface {
+	Incr(ctx context.Context, bucket int64, userID string, terms []string) error
+	// Counts sums the usage of every term over the buckets.
+	Counts(ctx context.Context, buckets []int64) (map[string]int64, error)
+}
+
+// memoryStore counts the usage of the terms recorded by this instance.
+type memoryStore struct {
+	mu        sync.Mutex
+	counts    map[int64]map[string]int64
+	seen      map[int64]map[string]bool
+	retention int64
+}
+
+// NewMemoryStore keeps the last retention buckets.
+func NewMemoryStore(retention int64) Store {
+	return &memoryStore{
+		counts:    map[int64]map[string]int64{},
+		seen:      map[int64]map[string]bool{},
+		retention: retention,
+	}
+}
+
+func (m *memoryStore) Incr(_ context.Context, bucket int64, userID string, terms []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.counts[bucket] == nil {
+		m.counts[bucket] = map[string]int64{}
+		m.seen[bucket] = map[string]bool{}
+		m.prune(bucket)
+	}
+
+	for _, term := range terms {
+		key := userID + "\x00" + term
+		if m.seen[bucket][key] {
+			continue
+		}
+		m.seen[bucket][key] = true
+		m.counts[bucket][term]++
+	}
+
+	return nil
+}
+
+func (m *memoryStore) Counts(_ context.Context, buckets []int64) (map[string]int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	counts := map[string]int64{}
+	for _, bucket := range buckets {
+		for term, count := range m.counts[bucket] {
+			counts[term] += count
+		}
+	}
+
+	return counts, nil
+}
+
+// prune drops the buckets older than the retention.
+func (m *memoryStore) prune(latest int64) {
+	for bucket := range m.counts {
+		if bucket <= latest-m.retention {
+			delete(m.counts, bucket)
+			delete(m.seen, bucket)
+		}
+	}
+}
+
+// incrScript adds the terms of a user to a bucket, counting each term once per
+// user: KEYS are the counts and seen keys, ARGV the ttl in seconds, the user
+// and the terms.
+var incrScript = redis.NewScript(`
+for i = 3, #ARGV do
+	if redis.call('SADD', KEYS[2], ARGV[2] .. '|' .. ARGV[i]) == 1 then
+		redis.call('ZINCRBY', KEYS[1], 1, ARGV[i])
+	end
+end
+redis.call('EXPIRE', KEYS[1], ARGV[1])
+redis.call('EXPIRE', KEYS[2], ARGV[1])
+return 0
+`)
+
+// redisStore counts terms in one sorted set per bucket, shared by every
+// instance.
+type redisStore struct {
+	client *redis.Client
+	prefix string
+	ttl    time.Duration
+}
+
+// NewRedisStore keeps each bucket for ttl.
+func NewRedisStore(client *redis.Client, prefix string, ttl time.Duration) Store {
+	return &redisStore{
+		client: client,
+		prefix: prefix,
+		ttl:    ttl,
+	}
+}
+
+func (r *redisStore) Incr(ctx context.Context, bucket int64, userID string, terms []string) error {
+	if len(terms) == 0 {
+		return nil
+	}
+
+	keys := []string{r.key("counts", bucket), r.key("seen", bucket)}
+	args := []interface{}{int64(r.ttl.Seconds()), userID}
+	for _, term := range terms {
+		args = append(args, term)
+	}
+
+	return incrScript.Run(ctx, r.client, keys, args...).Err()
+}
+
+func (r *redisStore) Counts(ctx context.Context, buckets []int64) (map[string]int64, error) {
+	keys := make([]string, 0, len(buckets))
+	for _, bucket := range buckets {
+		keys = append(keys, r.key("counts", bucket))
+	}
+
+	members, err := r.client.ZUnionWithScores(ctx, redis.ZStore{Keys: keys}).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(members))
+	for _, member := range members {
+		if term, ok := member.Member.(string); ok {
+			counts[term] = int64(member.Score)
+		}
+	}
+
+	return counts, nil
+}
+
+func (r *redisStore) key(kind string, bucket int64) string {
+	return r.prefix + kind + ":" + strconv.FormatInt(bucket, 10)
+}
@@ -0,0 +1,240 @@
+package trends
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"test/pkg/logger"
+	"test/pkg/text"
+	"time"
+	"unicode"
+)
+
+const (
+	// BucketSize is the resolution of the sliding windows.
+	BucketSize = time.Minute * 5
+	// Window is how far back current usage is counted.
+	Window = time.Hour
+	// Baseline is how far back, before the window, usual usage is counted.
+	Baseline = time.Hour * 24
+
+	// Retention is the number of buckets a store must keep.
+	Retention = int64((Window + Baseline) / BucketSize)
+
+	minCount         = 5
+	minScore         = 2
+	maxTrends        = 30
+	maxTermsPerTweet = 20
+	refreshInterval  = time.Minute
+)
+
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true, "but": true,
+	"by": true, "for": true, "from": true, "has": true, "have": true, "he": true, "her": true, "his": true,
+	"i": true, "if": true, "in": true, "is": true, "it": true, "its": true, "me": true, "my": true,
+	"not": true, "of": true, "on": true, "or": true, "our": true, "she": true, "so": true, "that": true,
+	"the": true, "their": true, "them": true, "they": true, "this": true, "to": true, "was": true, "we": true,
+	"were": true, "what": true, "when": true, "who": true, "will": true, "with": true, "you": true, "your": true,
+}
+
+// Trend is a hashtag or phrase used much more in the window than usual.
+type Trend struct {
+	Term    string
+	Hashtag bool
+	// Count is the number of users who used the term in the window.
+	Count int64
+	// Score is how unusual Count is compared to the baseline.
+	Score float64
+}
+
+// Tracker counts the hashtags and two word phrases of new tweets and keeps the
+// current trends, refreshed in the background so reading them is free.
+type Tracker struct {
+	store   Store
+	blocked map[string]bool
+	log     logger.ILogger
+
+	mu        sync.RWMutex
+	trends    []Trend
+	updatedAt time.Time
+}
+
+// NewTracker ignores the blocked terms and the phrases containing them.
+func NewTracker(store Store, blockedTerms []string, log logger.ILogger) *Tracker {
+	blocked := map[string]bool{}
+	for _, term := range blockedTerms {
+		if term = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(term, "#"))); term != "" {
+			blocked[term] = true
+		}
+	}
+
+	return &Tracker{
+		store:   store,
+		blocked: blocked,
+		log:     log,
+	}
+}
+
+// Record counts the terms of a tweet userID posted at the given time.
+func (t *Tracker) Record(ctx context.Context, userID, content string, at time.Time) error {
+	return t.store.Incr(ctx, bucketOf(at), userID, t.terms(content))
+}
+
+// Trends returns the trends of the last refresh and when they were computed.
+func (t *Tracker) Trends() ([]Trend, time.Time) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return t.trends, t.updatedAt
+}
+
+// Run refreshes the trends until ctx is done.
+func (t *Tracker) Run(ctx context.Context) error {
+	ticker := time.NewTicker(refreshInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := t.Refresh(ctx, time.Now()); err != nil {
+			t.log.Error("error while refreshing trends", logger.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Refresh ranks the terms by how far their usage in the window exceeds the
+// usage expected from the baseline, in standard deviations of a Poisson
+// distribution, so terms that are always popular do not trend.
+func (t *Tracker) Refresh(ctx context.Context, now time.Time) error {
+	var (
+		current     = bucketOf(now)
+		windowSize  = int64(Window / BucketSize)
+		windowStart = current - windowSize + 1
+	)
+
+	window, err := t.store.Counts(ctx, bucketRange(windowStart, current))
+	if err != nil {
+		return err
+	}
+
+	baseline, err := t.store.Counts(ctx, bucketRange(windowStart-int64(Baseline/BucketSize), windowStart-1))
+	if err != nil {
+		return err
+	}
+
+	trends := []Trend{}
+	for term, count := range window {
+		if count < minCount || t.isBlocked(term) {
+			continue
+		}
+
+		expected := float64(baseline[term]) * float64(Window) / float64(Baseline)
+		score := (float64(count) - expected) / math.Sqrt(expected+1)
+		if score < minScore {
+			continue
+		}
+
+		trends = append(trends, Trend{
+			Term:    term,
+			Hashtag: strings.HasPrefix(term, "#"),
+			Count:   count,
+			Score:   score,
+		})
+	}
+
+	sort.Slice(trends, func(i, j int) bool {
+		if trends[i].Score != trends[j].Score {
+			return trends[i].Score > trends[j].Score
+		}
+		return trends[i].Term < trends[j].Term
+	})
+
+	if len(trends) > maxTrends {
+		trends = trends[:maxTrends]
+	}
+
+	t.mu.Lock()
+	t.trends, t.updatedAt = trends, now
+	t.mu.Unlock()
+
+	return nil
+}
+
+// terms returns the hashtags of the content with their # and the pairs of
+// consecutive words that are not stop words, mentions, links or hashtags.
+func (t *Tracker) terms(content string) []string {
+	terms := []string{}
+	seen := map[string]bool{}
+	add := func(term string) {
+		if !seen[term] && len(terms) < maxTermsPerTweet {
+			seen[term] = true
+			terms = append(terms, term)
+		}
+	}
+
+	for _, hashtag := range text.Hashtags(content) {
+		add("#" + hashtag)
+	}
+
+	previous := ""
+	for _, field := range strings.Fields(strings.ToLower(content)) {
+		if strings.HasPrefix(field, "@") || strings.HasPrefix(field, "#") || strings.Contains(field, "://") {
+			previous = ""
+			continue
+		}
+
+		word := strings.TrimFunc(field, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+		})
+		if len([]rune(word)) < 2 || stopWords[word] || !isWord(word) {
+			previous = ""
+			continue
+		}
+
+		if previous != "" {
+			add(previous + " " + word)
+		}
+		previous = word
+	}
+
+	return terms
+}
+
+func (t *Tracker) isBlocked(term string) bool {
+	for _, word := range strings.Fields(strings.TrimPrefix(term, "#")) {
+		if t.blocked[word] {
+			return true
+		}
+	}
+
+	return t.blocked[strings.TrimPrefix(term, "#")]
+}
+
+func isWord(word string) bool {
+	for _, r := range word {
+		if !unicode.IsLetter(r) && !unicode.IsNumber(r) && r != '\'' && r != '-' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func bucketOf(at time.Time) int64 {
+	return at.Unix() / int64(BucketSize/time.Second)
+}
+
+func bucketRange(from, to int64) []int64 {
+	buckets := make([]int64, 0, to-from+1)
+	for bucket := from; bucket <= to; bucket++ {
+		buckets = append(buckets, bucket)
+	}
+
+	return buckets
+}
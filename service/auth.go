@@ -2,11 +2,15 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"test/api/models"
 	"test/pkg/jwt"
 	"test/pkg/logger"
 	"test/pkg/security"
 	"test/storage"
+
+	"github.com/jackc/pgx/v5"
 )
 
 type authService struct {
@@ -33,9 +37,13 @@ func (a authService) UserLogin(ctx context.Context, loginRequest models.UserLogi
 		return models.UserLoginResponse{}, err
 	}
 
+	if admin.SuspendedAt != nil {
+		return models.UserLoginResponse{}, fmt.Errorf("%w: this account is suspended", ErrForbidden)
+	}
+
 	m := make(map[interface{}]interface{})
 	m["user_id"] = admin.ID
-	m["user_role"] = "admin"
+	m["user_role"] = admin.Role
 
 	accessToken, refreshToken, err := jwt.GenerateJWT(m)
 	if err != nil {
@@ -48,3 +56,35 @@ func (a authService) UserLogin(ctx context.Context, loginRequest models.UserLogi
 		RefreshToken: refreshToken,
 	}, nil
 }
+
+// CheckActive returns ErrForbidden when the user is suspended and ErrNotFound
+// when they no longer exist, so that tokens issued before either stop working.
+func (a authService) CheckActive(ctx context.Context, userID string) error {
+	if err := ensureActive(ctx, a.storage, userID); err != nil {
+		if !errors.Is(err, ErrForbidden) && !errors.Is(err, ErrNotFound) {
+			a.log.Error("error in service layer while checking user suspension", logger.Error(err))
+		}
+		return err
+	}
+
+	return nil
+}
+
+// ensureActive returns ErrForbidden when moderators suspended the user and
+// ErrNotFound when they no longer exist. Work done on behalf of a user without
+// a request, such as publishing their scheduled drafts, checks it too.
+func ensureActive(ctx context.Context, store storage.IStorage, userID string) error {
+	suspended, err := store.User().IsSuspended(ctx, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotFound
+		}
+		return err
+	}
+
+	if suspended {
+		return fmt.Errorf("%w: this account is suspended", ErrForbidden)
+	}
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"test/api/models"
+	"test/pkg/logger"
+	"test/storage"
+)
+
+type blocksService struct {
+	storage storage.IStorage
+	log     logger.ILogger
+}
+
+func NewBlocksService(storage storage.IStorage, log logger.ILogger) blocksService {
+	return blocksService{storage: storage, log: log}
+}
+
+func (b blocksService) Block(ctx context.Context, block models.CreateBlock) (models.Block, error) {
+	b.log.Info("block create service layer", logger.Any("block", block))
+
+	if block.UserID == block.BlockedUserID {
+		return models.Block{}, fmt.Errorf("%w: you cannot block yourself", ErrInvalid)
+	}
+
+	id, err := b.storage.Blocks().Create(ctx, block)
+	if err != nil {
+		b.log.Error("error in service layer while blocking user", logger.Error(err))
+		return models.Block{}, err
+	}
+
+	createdBlock, err := b.storage.Blocks().GetByID(ctx, models.PrimaryKey{ID: id})
+	if err != nil {
+		b.log.Error("error in service layer while getting block by id", logger.Error(err))
+		return models.Block{}, err
+	}
+
+	return createdBlock, nil
+}
+
+func (b blocksService) Unblock(ctx context.Context, block models.CreateBlock) error {
+	if err := b.storage.Blocks().Delete(ctx, block); err != nil {
+		b.log.Error("error in service layer while unblocking user", logger.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+func (b blocksService) GetList(ctx context.Context, request models.GetListRequest) (models.UserSummariesResponse, error) {
+	users, err := b.storage.Blocks().GetList(ctx, request)
+	if err != nil {
+		b.log.Error("error in service layer while getting blocked users", logger.Error(err))
+		return models.UserSummariesResponse{}, err
+	}
+
+	return users, nil
+}
@@ -0,0 +1,266 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"test/api/models"
+	"test/pkg/cursor"
+	"test/pkg/logger"
+	"test/storage"
+	"unicode/utf8"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const maxBookmarkFolderNameLength = 100
+
+type bookmarksService struct {
+	storage storage.IStorage
+	log     logger.ILogger
+}
+
+func NewBookmarksService(storage storage.IStorage, log logger.ILogger) bookmarksService {
+	return bookmarksService{storage: storage, log: log}
+}
+
+// Bookmark privately saves a tweet the user can see, optionally into one of
+// the user's folders. Bookmarking a tweet again moves it to the folder.
+func (b bookmarksService) Bookmark(ctx context.Context, bookmark models.CreateBookmark) (models.Bookmark, error) {
+	b.log.Info("bookmark create service layer", logger.Any("bookmark", bookmark))
+
+	if _, err := getVisibleTweet(ctx, b.storage, bookmark.TweetID, bookmark.UserID); err != nil {
+		b.log.Error("error in service layer while getting tweet to bookmark", logger.Error(err))
+		return models.Bookmark{}, err
+	}
+
+	if bookmark.FolderID != nil {
+		if _, err := b.getOwnFolder(ctx, *bookmark.FolderID, bookmark.UserID); err != nil {
+			b.log.Error("error in service layer while getting bookmark folder", logger.Error(err))
+			return models.Bookmark{}, err
+		}
+	}
+
+	id, err := b.storage.Bookmarks().Upsert(ctx, bookmark)
+	if err != nil {
+		b.log.Error("error in service layer while bookmarking tweet", logger.Error(err))
+		return models.Bookmark{}, err
+	}
+
+	createdBookmark, err := b.storage.Bookmarks().GetByID(ctx, models.PrimaryKey{ID: id})
+	if err != nil {
+		b.log.Error("error in service layer while getting bookmark by id", logger.Error(err))
+		return models.Bookmark{}, err
+	}
+
+	if err = hydrateTweet(ctx, b.storage, bookmark.UserID, &createdBookmark.Tweet); err != nil {
+		b.log.Error("error in service layer while hydrating tweet", logger.Error(err))
+		return models.Bookmark{}, err
+	}
+
+	return createdBookmark, nil
+}
+
+func (b bookmarksService) Unbookmark(ctx context.Context, bookmark models.CreateBookmark) error {
+	if err := b.storage.Bookmarks().Delete(ctx, bookmark); err != nil {
+		b.log.Error("error in service layer while removing bookmark", logger.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// GetList pages through the user's bookmarks, most recent first. The cursor is
+// the next_cursor of the previous page, empty for the first page.
+func (b bookmarksService) GetList(ctx context.Context, request models.GetBookmarksRequest, pageCursor string) (models.BookmarksResponse, error) {
+	if pageCursor != "" {
+		createdAt, id, err := cursor.Decode(pageCursor)
+		if err != nil {
+			return models.BookmarksResponse{}, fmt.Errorf("%w: %v", ErrInvalid, err)
+		}
+		request.CursorCreatedAt, request.CursorID = &createdAt, id
+	}
+
+	if request.FolderID != "" {
+		if _, err := b.getOwnFolder(ctx, request.FolderID, request.UserID); err != nil {
+			b.log.Error("error in service layer while getting bookmark folder", logger.Error(err))
+			return models.BookmarksResponse{}, err
+		}
+	}
+
+	bookmarks, err := b.storage.Bookmarks().GetList(ctx, request)
+	if err != nil {
+		b.log.Error("error in service layer while getting bookmarks", logger.Error(err))
+		return models.BookmarksResponse{}, err
+	}
+
+	tweets := make([]models.Tweet, len(bookmarks.Bookmarks))
+	for i, bookmark := range bookmarks.Bookmarks {
+		tweets[i] = bookmark.Tweet
+	}
+
+	if err = hydrateTweets(ctx, b.storage, request.UserID, tweets); err != nil {
+		b.log.Error("error in service layer while hydrating tweets", logger.Error(err))
+		return models.BookmarksResponse{}, err
+	}
+
+	for i := range bookmarks.Bookmarks {
+		bookmarks.Bookmarks[i].Tweet = tweets[i]
+	}
+
+	return bookmarks, nil
+}
+
+func (b bookmarksService) CreateFolder(ctx context.Context, folder models.CreateBookmarkFolder) (models.BookmarkFolder, error) {
+	b.log.Info("bookmark folder create service layer", logger.Any("folder", folder))
+
+	name, err := b.checkFolderName(ctx, folder.UserID, "", folder.Name)
+	if err != nil {
+		return models.BookmarkFolder{}, err
+	}
+	folder.Name = name
+
+	id, err := b.storage.Bookmarks().CreateFolder(ctx, folder)
+	if err != nil {
+		b.log.Error("error in service layer while creating bookmark folder", logger.Error(err))
+		return models.BookmarkFolder{}, err
+	}
+
+	createdFolder, err := b.storage.Bookmarks().GetFolderByID(ctx, models.PrimaryKey{ID: id})
+	if err != nil {
+		b.log.Error("error in service layer while getting bookmark folder by id", logger.Error(err))
+		return models.BookmarkFolder{}, err
+	}
+
+	return createdFolder, nil
+}
+
+func (b bookmarksService) RenameFolder(ctx context.Context, folder models.UpdateBookmarkFolder) (models.BookmarkFolder, error) {
+	if _, err := b.getOwnFolder(ctx, folder.FolderID, folder.UserID); err != nil {
+		b.log.Error("error in service layer while getting bookmark folder", logger.Error(err))
+		return models.BookmarkFolder{}, err
+	}
+
+	name, err := b.checkFolderName(ctx, folder.UserID, folder.FolderID, folder.Name)
+	if err != nil {
+		return models.BookmarkFolder{}, err
+	}
+	folder.Name = name
+
+	if err = b.storage.Bookmarks().UpdateFolder(ctx, folder); err != nil {
+		b.log.Error("error in service layer while renaming bookmark folder", logger.Error(err))
+		return models.BookmarkFolder{}, err
+	}
+
+	updatedFolder, err := b.storage.Bookmarks().GetFolderByID(ctx, models.PrimaryKey{ID: folder.FolderID})
+	if err != nil {
+		b.log.Error("error in service layer while getting bookmark folder by id", logger.Error(err))
+		return models.BookmarkFolder{}, err
+	}
+
+	return updatedFolder, nil
+}
+
+// DeleteFolder removes the folder, its bookmarks are kept without a folder.
+func (b bookmarksService) DeleteFolder(ctx context.Context, key models.PrimaryKey, userID string) error {
+	if _, err := b.getOwnFolder(ctx, key.ID, userID); err != nil {
+		b.log.Error("error in service layer while getting bookmark folder", logger.Error(err))
+		return err
+	}
+
+	if err := b.storage.Bookmarks().DeleteFolder(ctx, key, userID); err != nil {
+		b.log.Error("error in service layer while deleting bookmark folder", logger.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+func (b bookmarksService) GetFolders(ctx context.Context, userID string) (models.BookmarkFoldersResponse, error) {
+	folders, err := b.storage.Bookmarks().GetFolders(ctx, userID)
+	if err != nil {
+		b.log.Error("error in service layer while getting bookmark folders", logger.Error(err))
+		return models.BookmarkFoldersResponse{}, err
+	}
+
+	return folders, nil
+}
+
+// ReorderFolders puts the user's folders in the given order, which must list
+// every folder of the user exactly once.
+func (b bookmarksService) ReorderFolders(ctx context.Context, request models.ReorderBookmarkFolders) (models.BookmarkFoldersResponse, error) {
+	folders, err := b.storage.Bookmarks().GetFolders(ctx, request.UserID)
+	if err != nil {
+		b.log.Error("error in service layer while getting bookmark folders", logger.Error(err))
+		return models.BookmarkFoldersResponse{}, err
+	}
+
+	owned := make(map[string]bool, len(folders.Folders))
+	for _, folder := range folders.Folders {
+		owned[folder.FolderID] = true
+	}
+
+	if len(request.FolderIDs) != len(owned) {
+		return models.BookmarkFoldersResponse{}, fmt.Errorf("%w: folder_ids must list every folder once", ErrInvalid)
+	}
+
+	for _, id := range request.FolderIDs {
+		if !owned[id] {
+			return models.BookmarkFoldersResponse{}, fmt.Errorf("%w: folder_ids must list every folder once", ErrInvalid)
+		}
+		delete(owned, id)
+	}
+
+	if err = b.storage.Bookmarks().ReorderFolders(ctx, request); err != nil {
+		b.log.Error("error in service layer while reordering bookmark folders", logger.Error(err))
+		return models.BookmarkFoldersResponse{}, err
+	}
+
+	return b.GetFolders(ctx, request.UserID)
+}
+
+// getOwnFolder loads the folder, returning ErrNotFound when it belongs to
+// another user so folder ids are not leaked.
+func (b bookmarksService) getOwnFolder(ctx context.Context, folderID, userID string) (models.BookmarkFolder, error) {
+	folder, err := b.storage.Bookmarks().GetFolderByID(ctx, models.PrimaryKey{ID: folderID})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.BookmarkFolder{}, ErrNotFound
+		}
+		return models.BookmarkFolder{}, err
+	}
+
+	if folder.UserID != userID {
+		return models.BookmarkFolder{}, ErrNotFound
+	}
+
+	return folder, nil
+}
+
+// checkFolderName trims the name and checks it is not empty, not too long and
+// not used by another folder of the user.
+func (b bookmarksService) checkFolderName(ctx context.Context, userID, folderID, name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", fmt.Errorf("%w: folder name is required", ErrInvalid)
+	}
+
+	if utf8.RuneCountInString(name) > maxBookmarkFolderNameLength {
+		return "", fmt.Errorf("%w: folder name is too long", ErrInvalid)
+	}
+
+	folders, err := b.storage.Bookmarks().GetFolders(ctx, userID)
+	if err != nil {
+		b.log.Error("error in service layer while getting bookmark folders", logger.Error(err))
+		return "", err
+	}
+
+	for _, folder := range folders.Folders {
+		if folder.Name == name && folder.FolderID != folderID {
+			return "", fmt.Errorf("%w: a folder with this name already exists", ErrInvalid)
+		}
+	}
+
+	return name, nil
+}
@@ -0,0 +1,326 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"test/api/models"
+	"test/pkg/logger"
+	"test/storage"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	draftsPollInterval = 15 * time.Second
+	draftsBatchSize    = 50
+	// draftPublishingLease is how long a draft is left to the instance
+	// publishing it before another one takes over, should the first one have
+	// died.
+	draftPublishingLease    = 5 * time.Minute
+	maxDraftPublishAttempts = 5
+	draftRetryDelay         = 30 * time.Second
+	maxDraftScheduleAhead   = 365 * 24 * time.Hour
+)
+
+type draftsService struct {
+	storage storage.IStorage
+	tweets  tweetService
+	log     logger.ILogger
+}
+
+func NewDraftsService(storage storage.IStorage, tweets tweetService, log logger.ILogger) draftsService {
+	return draftsService{storage: storage, tweets: tweets, log: log}
+}
+
+// Create saves a draft, scheduled for publishing if it has a publish time.
+func (d draftsService) Create(ctx context.Context, draft models.CreateDraft) (models.Draft, error) {
+	if err := checkReplyAudience(&draft.ReplyAudience); err != nil {
+		return models.Draft{}, err
+	}
+
+	if err := checkDraft(ctx, d.storage, models.CreateTweet{
+		UserID:                draft.UserID,
+		Content:               draft.Content,
+		MediaIDs:              draft.MediaIDs,
+		ReplyToTweetID:        draft.ReplyToTweetID,
+		Poll:                  draft.Poll,
+		ReplyAudience:         draft.ReplyAudience,
+		IgnoreAltTextReminder: draft.IgnoreAltTextReminder,
+	}, draft.PublishAt); err != nil {
+		d.log.Error("error in service layer while checking draft", logger.Error(err))
+		return models.Draft{}, err
+	}
+
+	id, err := d.storage.Drafts().Create(ctx, draft)
+	if err != nil {
+		d.log.Error("error in service layer while creating draft", logger.Error(err))
+		return models.Draft{}, err
+	}
+
+	createdDraft, err := d.storage.Drafts().GetByID(ctx, models.PrimaryKey{ID: id})
+	if err != nil {
+		d.log.Error("error in service layer while getting draft by id", logger.Error(err))
+		return models.Draft{}, err
+	}
+
+	return createdDraft, nil
+}
+
+func (d draftsService) Get(ctx context.Context, key models.PrimaryKey, userID string) (models.Draft, error) {
+	draft, err := d.getOwnDraft(ctx, key.ID, userID)
+	if err != nil {
+		d.log.Error("error in service layer while getting draft", logger.Error(err))
+		return models.Draft{}, err
+	}
+
+	return draft, nil
+}
+
+func (d draftsService) GetList(ctx context.Context, request models.GetDraftsRequest) (models.DraftsResponse, error) {
+	drafts, err := d.storage.Drafts().GetList(ctx, request)
+	if err != nil {
+		d.log.Error("error in service layer while getting drafts", logger.Error(err))
+		return models.DraftsResponse{}, err
+	}
+
+	return drafts, nil
+}
+
+// Update replaces the draft, rescheduling or unscheduling it. Drafts being
+// published can no longer be edited.
+func (d draftsService) Update(ctx context.Context, draft models.UpdateDraft) (models.Draft, error) {
+	current, err := d.getOwnDraft(ctx, draft.ID, draft.UserID)
+	if err != nil {
+		d.log.Error("error in service layer while getting draft", logger.Error(err))
+		return models.Draft{}, err
+	}
+
+	if current.State == models.DraftStatePublishing {
+		return models.Draft{}, fmt.Errorf("%w: the draft is being published", ErrInvalid)
+	}
+
+	if err = checkReplyAudience(&draft.ReplyAudience); err != nil {
+		return models.Draft{}, err
+	}
+
+	if err = checkDraft(ctx, d.storage, models.CreateTweet{
+		UserID:                draft.UserID,
+		Content:               draft.Content,
+		MediaIDs:              draft.MediaIDs,
+		ReplyToTweetID:        draft.ReplyToTweetID,
+		Poll:                  draft.Poll,
+		ReplyAudience:         draft.ReplyAudience,
+		IgnoreAltTextReminder: draft.IgnoreAltTextReminder,
+	}, draft.PublishAt); err != nil {
+		d.log.Error("error in service layer while checking draft", logger.Error(err))
+		return models.Draft{}, err
+	}
+
+	if err = d.storage.Drafts().Update(ctx, draft); err != nil {
+		d.log.Error("error in service layer while updating draft", logger.Error(err))
+		return models.Draft{}, err
+	}
+
+	updatedDraft, err := d.storage.Drafts().GetByID(ctx, models.PrimaryKey{ID: draft.ID})
+	if err != nil {
+		d.log.Error("error in service layer while getting updated draft by id", logger.Error(err))
+		return models.Draft{}, err
+	}
+
+	return updatedDraft, nil
+}
+
+// Delete deletes the draft, cancelling it when scheduled. Drafts being
+// published can no longer be cancelled.
+func (d draftsService) Delete(ctx context.Context, key models.PrimaryKey, userID string) error {
+	draft, err := d.getOwnDraft(ctx, key.ID, userID)
+	if err != nil {
+		d.log.Error("error in service layer while getting draft", logger.Error(err))
+		return err
+	}
+
+	if draft.State == models.DraftStatePublishing {
+		return fmt.Errorf("%w: the draft is being published", ErrInvalid)
+	}
+
+	if err = d.storage.Drafts().Delete(ctx, key, userID); err != nil {
+		d.log.Error("error in service layer while deleting draft", logger.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// Publish publishes the draft right away, scheduled or not. A draft that
+// cannot be published is kept as failed, with the reason.
+func (d draftsService) Publish(ctx context.Context, key models.PrimaryKey, userID string) (models.Tweet, error) {
+	if _, err := d.getOwnDraft(ctx, key.ID, userID); err != nil {
+		d.log.Error("error in service layer while getting draft", logger.Error(err))
+		return models.Tweet{}, err
+	}
+
+	draft, err := d.storage.Drafts().Claim(ctx, key, userID, draftPublishingLease)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Tweet{}, fmt.Errorf("%w: the draft is being published", ErrInvalid)
+		}
+		d.log.Error("error in service layer while claiming draft", logger.Error(err))
+		return models.Tweet{}, err
+	}
+
+	tweet, err := d.publish(ctx, draft)
+	if err != nil {
+		d.log.Error("error in service layer while publishing draft", logger.Error(err))
+		d.fail(ctx, draft.ID, err.Error())
+		return models.Tweet{}, err
+	}
+
+	return tweet, nil
+}
+
+// RunScheduler publishes the scheduled drafts that are due until ctx is done.
+// Drafts are claimed so that each is published by a single instance, and
+// once: a draft whose tweet was created by an attempt that died before
+// completing is not published again.
+func (d draftsService) RunScheduler(ctx context.Context) error {
+	ticker := time.NewTicker(draftsPollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := d.publishDue(ctx); err != nil {
+			d.log.Error("error while publishing scheduled drafts", logger.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// publishDue publishes due drafts a batch at a time until none is left.
+func (d draftsService) publishDue(ctx context.Context) error {
+	for ctx.Err() == nil {
+		drafts, err := d.storage.Drafts().ClaimDue(ctx, draftsBatchSize, draftPublishingLease)
+		if err != nil {
+			return err
+		}
+
+		for _, draft := range drafts {
+			d.publishScheduled(ctx, draft)
+		}
+
+		if len(drafts) < draftsBatchSize {
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// publishScheduled publishes a claimed draft, scheduling another attempt when
+// it fails for any other reason than the draft not being publishable.
+func (d draftsService) publishScheduled(ctx context.Context, draft models.Draft) {
+	_, err := d.publish(ctx, draft)
+	if err == nil {
+		return
+	}
+
+	d.log.Error("error while publishing scheduled draft", logger.String("draft_id", draft.ID), logger.Int("attempt", draft.Attempts), logger.Error(err))
+
+	if !isTransient(err) || draft.Attempts >= maxDraftPublishAttempts {
+		d.fail(ctx, draft.ID, err.Error())
+		return
+	}
+
+	retryAt := time.Now().Add(draftRetryDelay << (draft.Attempts - 1))
+	if err = d.storage.Drafts().RetryPublishing(ctx, draft.ID, err.Error(), retryAt); err != nil {
+		d.log.Error("error while scheduling draft publishing retry", logger.Error(err))
+	}
+}
+
+// publish creates the tweet of a claimed draft and removes the draft. The
+// tweet of a previous attempt is returned instead, if there is one. Drafts of
+// suspended authors are not publishable, creating the tweet is forbidden.
+func (d draftsService) publish(ctx context.Context, draft models.Draft) (models.Tweet, error) {
+	tweet, err := d.storage.Tweets().GetByDraftID(ctx, draft.ID)
+	switch {
+	case err == nil:
+	case errors.Is(err, pgx.ErrNoRows):
+		if tweet, err = d.tweets.Create(ctx, draftTweet(draft)); err != nil {
+			return models.Tweet{}, err
+		}
+	default:
+		return models.Tweet{}, err
+	}
+
+	// left over, the draft is removed by the next attempt as published
+	if err = d.storage.Drafts().Published(ctx, draft.ID); err != nil {
+		d.log.Error("error while removing published draft", logger.String("draft_id", draft.ID), logger.Error(err))
+	}
+
+	return tweet, nil
+}
+
+func (d draftsService) fail(ctx context.Context, draftID, reason string) {
+	if err := d.storage.Drafts().Fail(ctx, draftID, reason); err != nil {
+		d.log.Error("error while marking draft failed", logger.Error(err))
+	}
+}
+
+// getOwnDraft loads the draft of userID. Drafts of others are private and
+// reported as not found.
+func (d draftsService) getOwnDraft(ctx context.Context, draftID, userID string) (models.Draft, error) {
+	draft, err := d.storage.Drafts().GetByID(ctx, models.PrimaryKey{ID: draftID})
+	if err != nil {
+		return models.Draft{}, err
+	}
+
+	if draft.UserID != userID {
+		return models.Draft{}, ErrNotFound
+	}
+
+	return draft, nil
+}
+
+// checkDraft checks the tweet of a draft. Only scheduled drafts must be ready
+// to publish, with their media and in the coming year; others are checked as
+// far as they go.
+func checkDraft(ctx context.Context, store storage.IStorage, tweet models.CreateTweet, publishAt *time.Time) error {
+	if err := checkTweetPoll(tweet); err != nil {
+		return err
+	}
+
+	if publishAt == nil {
+		return nil
+	}
+
+	if now := time.Now(); !publishAt.After(now) || publishAt.After(now.Add(maxDraftScheduleAhead)) {
+		return fmt.Errorf("%w: publish_at must be in the coming year", ErrInvalid)
+	}
+
+	return checkTweetMedia(ctx, store, tweet)
+}
+
+// draftTweet is the tweet the draft publishes.
+func draftTweet(draft models.Draft) models.CreateTweet {
+	return models.CreateTweet{
+		UserID:                draft.UserID,
+		Content:               draft.Content,
+		MediaIDs:              draft.MediaIDs,
+		ReplyToTweetID:        draft.ReplyToTweetID,
+		Poll:                  draft.Poll,
+		ReplyAudience:         draft.ReplyAudience,
+		IgnoreAltTextReminder: draft.IgnoreAltTextReminder,
+		DraftID:               &draft.ID,
+	}
+}
+
+// isTransient reports whether err may not happen again, unlike the request
+// being invalid or the content gone.
+func isTransient(err error) bool {
+	return !errors.Is(err, ErrInvalid) && !errors.Is(err, ErrForbidden) && !errors.Is(err, ErrNotFound) && !errors.Is(err, pgx.ErrNoRows)
+}
@@ -0,0 +1,9 @@
+package service
+
+import "errors"
+
+var (
+	ErrForbidden = errors.New("forbidden")
+	ErrNotFound  = errors.New("not found")
+	ErrInvalid   = errors.New("invalid request")
+)
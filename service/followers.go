@@ -2,63 +2,268 @@ package service
 
 import (
 	"context"
+	"fmt"
 	"test/api/models"
 	"test/pkg/logger"
+	"test/pkg/pubsub"
 	"test/storage"
 )
 
 type followersService struct {
-	storage storage.IStorage
-	log     logger.ILogger
+	storage   storage.IStorage
+	publisher pubsub.Publisher
+	log       logger.ILogger
 }
 
-func NewfollowersService(storage storage.IStorage, log logger.ILogger) followersService {
-	return followersService{storage: storage, log: log}
+func NewfollowersService(storage storage.IStorage, publisher pubsub.Publisher, log logger.ILogger) followersService {
+	return followersService{storage: storage, publisher: publisher, log: log}
 }
 
-func (f followersService) Create(ctx context.Context, follower models.CreateFollower) (models.Follower, error) {
-    f.log.Info("follower create service layer", logger.Any("follower", follower))
+// Create follows the user right away, or sends a follow request when the
+// followed account is protected.
+func (f followersService) Create(ctx context.Context, follower models.CreateFollower) (models.CreateFollowerResponse, error) {
+	f.log.Info("follower create service layer", logger.Any("follower", follower))
 
-    id, err := f.storage.Followers().Create(ctx, follower)
-    if err != nil {
-        f.log.Error("error in service layer while creating follower", logger.Error(err))
-        return models.Follower{}, err
-    }
+	if follower.UserID == follower.FollowerUserID {
+		return models.CreateFollowerResponse{}, fmt.Errorf("%w: you cannot follow yourself", ErrInvalid)
+	}
 
-    createdFollower, err := f.storage.Followers().GetByID(ctx, models.PrimaryKey{ID: id})
-    if err != nil {
-        f.log.Error("error in service layer while getting follower by id", logger.Error(err))
-        return models.Follower{}, err
-    }
+	if err := ensureNotBlocked(ctx, f.storage, follower.UserID, follower.FollowerUserID, "follow"); err != nil {
+		f.log.Error("error in service layer while checking block", logger.Error(err))
+		return models.CreateFollowerResponse{}, err
+	}
 
-    return createdFollower, nil
+	user, err := f.storage.User().GetByID(ctx, models.PrimaryKey{ID: follower.UserID})
+	if err != nil {
+		f.log.Error("error in service layer while getting followed user", logger.Error(err))
+		return models.CreateFollowerResponse{}, err
+	}
+
+	if user.Protected {
+		id, err := f.storage.FollowRequests().Create(ctx, follower)
+		if err != nil {
+			f.log.Error("error in service layer while creating follow request", logger.Error(err))
+			return models.CreateFollowerResponse{}, err
+		}
+
+		request, err := f.storage.FollowRequests().GetByID(ctx, models.PrimaryKey{ID: id})
+		if err != nil {
+			f.log.Error("error in service layer while getting follow request by id", logger.Error(err))
+			return models.CreateFollowerResponse{}, err
+		}
+
+		notify(ctx, f.storage, f.publisher, f.log, models.CreateNotification{
+			UserID:      follower.UserID,
+			ActorUserID: follower.FollowerUserID,
+			Type:        models.NotificationTypeFollowRequest,
+		})
+
+		return models.CreateFollowerResponse{
+			Status:        models.FollowStatusPending,
+			FollowRequest: &request,
+		}, nil
+	}
+
+	id, err := f.storage.Followers().Create(ctx, follower)
+	if err != nil {
+		f.log.Error("error in service layer while creating follower", logger.Error(err))
+		return models.CreateFollowerResponse{}, err
+	}
+
+	createdFollower, err := f.storage.Followers().GetByID(ctx, models.PrimaryKey{ID: id})
+	if err != nil {
+		f.log.Error("error in service layer while getting follower by id", logger.Error(err))
+		return models.CreateFollowerResponse{}, err
+	}
+
+	notify(ctx, f.storage, f.publisher, f.log, models.CreateNotification{
+		UserID:      follower.UserID,
+		ActorUserID: follower.FollowerUserID,
+		Type:        models.NotificationTypeFollow,
+	})
+
+	return models.CreateFollowerResponse{
+		Status:   models.FollowStatusFollowing,
+		Follower: &createdFollower,
+	}, nil
+}
+
+// Unfollow stops follower.FollowerUserID following follower.UserID.
+// Unfollowing a user who is not followed has no effect.
+func (f followersService) Unfollow(ctx context.Context, follower models.CreateFollower) error {
+	if err := f.storage.Followers().DeleteByUsers(ctx, follower); err != nil {
+		f.log.Error("error in service layer while unfollowing user", logger.Error(err))
+		return err
+	}
+
+	unnotify(ctx, f.storage, f.log, models.CreateNotification{
+		UserID:      follower.UserID,
+		ActorUserID: follower.FollowerUserID,
+		Type:        models.NotificationTypeFollow,
+	})
+
+	return nil
+}
+
+// Delete removes a follow userID is part of, letting them unfollow the
+// followed user or remove a follower, as Unfollow does.
+func (f followersService) Delete(ctx context.Context, key models.PrimaryKey, userID string) error {
+	follower, err := f.storage.Followers().GetByID(ctx, key)
+	if err != nil {
+		f.log.Error("error in service layer while getting follower by id", logger.Error(err))
+		return err
+	}
+
+	if follower.UserID != userID && follower.FollowerUserID != userID {
+		return fmt.Errorf("%w: you can only remove your own follows and followers", ErrForbidden)
+	}
+
+	return f.Unfollow(ctx, models.CreateFollower{UserID: follower.UserID, FollowerUserID: follower.FollowerUserID})
+}
+
+// GetFollowers lists the users following request.UserID, if the viewer may see the user.
+func (f followersService) GetFollowers(ctx context.Context, request models.GetListRequest) (models.UserSummariesResponse, error) {
+	if err := ensureVisible(ctx, f.storage, request.UserID, request.ViewerID); err != nil {
+		f.log.Error("error in service layer while checking user visibility", logger.Error(err))
+		return models.UserSummariesResponse{}, err
+	}
+
+	users, err := f.storage.Followers().GetFollowers(ctx, request)
+	if err != nil {
+		f.log.Error("error in service layer while getting followers", logger.Error(err))
+		return models.UserSummariesResponse{}, err
+	}
+
+	return users, nil
+}
+
+// GetFollowing lists the users request.UserID follows, if the viewer may see the user.
+func (f followersService) GetFollowing(ctx context.Context, request models.GetListRequest) (models.UserSummariesResponse, error) {
+	if err := ensureVisible(ctx, f.storage, request.UserID, request.ViewerID); err != nil {
+		f.log.Error("error in service layer while checking user visibility", logger.Error(err))
+		return models.UserSummariesResponse{}, err
+	}
+
+	users, err := f.storage.Followers().GetFollowing(ctx, request)
+	if err != nil {
+		f.log.Error("error in service layer while getting following", logger.Error(err))
+		return models.UserSummariesResponse{}, err
+	}
+
+	return users, nil
 }
 
-func (f followersService) Get(ctx context.Context, id string) (models.Follower, error) {
-    follower, err := f.storage.Followers().GetByID(ctx, models.PrimaryKey{ID: id})
-    if err != nil {
-        f.log.Error("error in service layer while getting follower by id", logger.Error(err))
-        return models.Follower{}, err
-    }
+// GetMutuals lists the users who follow request.UserID and are followed back, if the viewer may see the user.
+func (f followersService) GetMutuals(ctx context.Context, request models.GetListRequest) (models.UserSummariesResponse, error) {
+	if err := ensureVisible(ctx, f.storage, request.UserID, request.ViewerID); err != nil {
+		f.log.Error("error in service layer while checking user visibility", logger.Error(err))
+		return models.UserSummariesResponse{}, err
+	}
 
-    return follower, nil
+	users, err := f.storage.Followers().GetMutuals(ctx, request)
+	if err != nil {
+		f.log.Error("error in service layer while getting mutuals", logger.Error(err))
+		return models.UserSummariesResponse{}, err
+	}
+
+	return users, nil
 }
 
+// GetRelationship describes how the user asking, request.SourceID, relates to
+// request.TargetID, including their own blocks and mutes, which nobody else
+// may learn about.
+func (f followersService) GetRelationship(ctx context.Context, request models.RelationshipRequest) (models.Relationship, error) {
+	relationship, err := f.storage.Followers().GetRelationship(ctx, request)
+	if err != nil {
+		f.log.Error("error in service layer while getting relationship", logger.Error(err))
+		return models.Relationship{}, err
+	}
 
-func (f followersService) GetList(ctx context.Context, request models.GetListRequest) (models.FollowersResponse, error) {
-    f.log.Info("follower get list service layer", logger.Any("request", request))
+	return relationship, nil
+}
 
-    followers, err := f.storage.Followers().GetList(ctx, request)
-    if err != nil {
-        f.log.Error("error in service layer while getting list of followers", logger.Error(err))
-        return models.FollowersResponse{}, err
-    }
+func (f followersService) GetIncomingRequests(ctx context.Context, request models.GetListRequest) (models.FollowRequestsResponse, error) {
+	requests, err := f.storage.FollowRequests().GetIncoming(ctx, request)
+	if err != nil {
+		f.log.Error("error in service layer while getting incoming follow requests", logger.Error(err))
+		return models.FollowRequestsResponse{}, err
+	}
 
-    return followers, nil
+	return requests, nil
 }
 
+func (f followersService) GetOutgoingRequests(ctx context.Context, request models.GetListRequest) (models.FollowRequestsResponse, error) {
+	requests, err := f.storage.FollowRequests().GetOutgoing(ctx, request)
+	if err != nil {
+		f.log.Error("error in service layer while getting outgoing follow requests", logger.Error(err))
+		return models.FollowRequestsResponse{}, err
+	}
+
+	return requests, nil
+}
+
+// AcceptRequest lets userID accept a request to follow them.
+func (f followersService) AcceptRequest(ctx context.Context, key models.PrimaryKey, userID string) error {
+	request, err := f.storage.FollowRequests().GetByID(ctx, key)
+	if err != nil {
+		f.log.Error("error in service layer while getting follow request by id", logger.Error(err))
+		return err
+	}
+
+	if request.UserID != userID {
+		return fmt.Errorf("%w: only the requested user can accept a follow request", ErrForbidden)
+	}
+
+	if err = f.storage.FollowRequests().Accept(ctx, key); err != nil {
+		f.log.Error("error in service layer while accepting follow request", logger.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// RejectRequest lets userID reject a request to follow them.
+func (f followersService) RejectRequest(ctx context.Context, key models.PrimaryKey, userID string) error {
+	request, err := f.storage.FollowRequests().GetByID(ctx, key)
+	if err != nil {
+		f.log.Error("error in service layer while getting follow request by id", logger.Error(err))
+		return err
+	}
+
+	if request.UserID != userID {
+		return fmt.Errorf("%w: only the requested user can reject a follow request", ErrForbidden)
+	}
+
+	if err = f.storage.FollowRequests().Delete(ctx, key); err != nil {
+		f.log.Error("error in service layer while rejecting follow request", logger.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// CancelRequest lets userID withdraw a follow request they sent.
+func (f followersService) CancelRequest(ctx context.Context, key models.PrimaryKey, userID string) error {
+	request, err := f.storage.FollowRequests().GetByID(ctx, key)
+	if err != nil {
+		f.log.Error("error in service layer while getting follow request by id", logger.Error(err))
+		return err
+	}
+
+	if request.RequesterUserID != userID {
+		return fmt.Errorf("%w: only the requester can cancel a follow request", ErrForbidden)
+	}
+
+	if err = f.storage.FollowRequests().Delete(ctx, key); err != nil {
+		f.log.Error("error in service layer while cancelling follow request", logger.Error(err))
+		return err
+	}
+
+	unnotify(ctx, f.storage, f.log, models.CreateNotification{
+		UserID:      request.UserID,
+		ActorUserID: request.RequesterUserID,
+		Type:        models.NotificationTypeFollowRequest,
+	})
 
-func (f followersService) Delete(ctx context.Context, key models.PrimaryKey) error {
-    err := f.storage.Followers().Delete(ctx, key)
-    return err
+	return nil
 }
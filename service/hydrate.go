@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"test/api/models"
+	"test/storage"
+)
+
+// hydrateTweets fills in the media and polls of the tweets and the fields that
+// depend on who is looking at them.
+func hydrateTweets(ctx context.Context, store storage.IStorage, viewerID string, tweets []models.Tweet) error {
+	if len(tweets) == 0 {
+		return nil
+	}
+
+	tweetIDs := make([]string, 0, len(tweets))
+	for _, tweet := range tweets {
+		tweetIDs = append(tweetIDs, tweet.ID)
+	}
+
+	media, err := store.Media().GetByTweetIDs(ctx, tweetIDs)
+	if err != nil {
+		return err
+	}
+
+	tweetMedia := make(map[string][]models.Media, len(tweets))
+	for _, item := range media {
+		tweetMedia[*item.TweetID] = append(tweetMedia[*item.TweetID], item)
+	}
+
+	polls, err := store.Polls().GetByTweetIDs(ctx, tweetIDs, viewerID)
+	if err != nil {
+		return err
+	}
+
+	tweetPolls := make(map[string]models.Poll, len(polls))
+	for _, poll := range polls {
+		tweetPolls[poll.TweetID] = poll
+	}
+
+	for i := range tweets {
+		tweets[i].Media = tweetMedia[tweets[i].ID]
+		if tweets[i].Media == nil {
+			tweets[i].Media = []models.Media{}
+		}
+
+		if poll, ok := tweetPolls[tweets[i].ID]; ok {
+			hidePollResults(&poll, tweets[i].UserID, viewerID)
+			tweets[i].Poll = &poll
+		}
+	}
+
+	if viewerID == "" {
+		return nil
+	}
+
+	bookmarkedIDs, err := store.Bookmarks().GetBookmarkedTweetIDs(ctx, viewerID, tweetIDs)
+	if err != nil {
+		return err
+	}
+
+	bookmarked := make(map[string]bool, len(bookmarkedIDs))
+	for _, id := range bookmarkedIDs {
+		bookmarked[id] = true
+	}
+
+	repliableIDs, err := store.Tweets().GetRepliableIDs(ctx, tweetIDs, viewerID)
+	if err != nil {
+		return err
+	}
+
+	repliable := make(map[string]bool, len(repliableIDs))
+	for _, id := range repliableIDs {
+		repliable[id] = true
+	}
+
+	for i := range tweets {
+		tweets[i].Bookmarked = bookmarked[tweets[i].ID]
+		tweets[i].CanReply = repliable[tweets[i].ID]
+	}
+
+	return nil
+}
+
+// hydrateTweet is hydrateTweets for a single tweet.
+func hydrateTweet(ctx context.Context, store storage.IStorage, viewerID string, tweet *models.Tweet) error {
+	tweets := []models.Tweet{*tweet}
+	if err := hydrateTweets(ctx, store, viewerID, tweets); err != nil {
+		return err
+	}
+
+	*tweet = tweets[0]
+
+	return nil
+}
@@ -2,29 +2,46 @@ package service
 
 import (
 	"context"
+	"fmt"
 	"test/api/models"
 	"test/pkg/logger"
+	"test/pkg/pubsub"
 	"test/storage"
 )
 
 type likesService struct {
-	storage storage.IStorage
-	log     logger.ILogger
+	storage   storage.IStorage
+	publisher pubsub.Publisher
+	log       logger.ILogger
 }
 
-func NewlikesService(storage storage.IStorage, log logger.ILogger) likesService {
-	return likesService{storage: storage, log: log}
+func NewlikesService(storage storage.IStorage, publisher pubsub.Publisher, log logger.ILogger) likesService {
+	return likesService{storage: storage, publisher: publisher, log: log}
 }
 
 func (l likesService) Create(ctx context.Context, like models.CreateLike) (models.Like, error) {
 	l.log.Info("likesService create service layer", logger.Any("like", like))
 
+	tweet, err := getVisibleTweet(ctx, l.storage, like.TweetID, like.UserID)
+	if err != nil {
+		l.log.Error("error in service layer while getting liked tweet", logger.Error(err))
+		return models.Like{}, err
+	}
+
 	id, err := l.storage.Likes().Create(ctx, like)
 	if err != nil {
 		l.log.Error("error in service layer while creating like", logger.Error(err))
 		return models.Like{}, err
 	}
 
+	notify(ctx, l.storage, l.publisher, l.log, models.CreateNotification{
+		UserID:      tweet.UserID,
+		ActorUserID: like.UserID,
+		Type:        models.NotificationTypeLike,
+		TweetID:     &like.TweetID,
+	})
+	publishCounters(ctx, l.storage, l.publisher, l.log, tweet, like.UserID)
+
 	createdLike, err := l.storage.Likes().GetByID(ctx, models.PrimaryKey{ID: id})
 	if err != nil {
 		l.log.Error("error in service layer while getting like by id", logger.Error(err))
@@ -34,17 +51,123 @@ func (l likesService) Create(ctx context.Context, like models.CreateLike) (model
 	return createdLike, nil
 }
 
-func (l likesService) Get(ctx context.Context, id string) (models.Like, error) {
+// Get returns the like if the viewer may see both the liker and the tweet.
+func (l likesService) Get(ctx context.Context, id, viewerID string) (models.Like, error) {
 	like, err := l.storage.Likes().GetByID(ctx, models.PrimaryKey{ID: id})
 	if err != nil {
 		l.log.Error("error in service layer while getting like by id", logger.Error(err))
 		return models.Like{}, err
 	}
 
+	if err = ensureVisible(ctx, l.storage, like.UserID, viewerID); err != nil {
+		return models.Like{}, err
+	}
+
+	if _, err = getVisibleTweet(ctx, l.storage, like.TweetID, viewerID); err != nil {
+		l.log.Error("error in service layer while getting liked tweet", logger.Error(err))
+		return models.Like{}, err
+	}
+
 	return like, nil
 }
 
-func (l likesService) Delete(ctx context.Context, key models.PrimaryKey) error {
-	err := l.storage.Likes().Delete(ctx, key)
-	return err
+// Delete removes a like of the user, as Unlike does.
+func (l likesService) Delete(ctx context.Context, key models.PrimaryKey, userID string) error {
+	like, err := l.storage.Likes().GetByID(ctx, key)
+	if err != nil {
+		l.log.Error("error in service layer while getting like by id", logger.Error(err))
+		return err
+	}
+
+	if like.UserID != userID {
+		return fmt.Errorf("%w: you can only remove your own likes", ErrForbidden)
+	}
+
+	return l.Unlike(ctx, models.CreateLike{TweetID: like.TweetID, UserID: userID})
+}
+
+func (l likesService) Like(ctx context.Context, like models.CreateLike) (models.Like, error) {
+	tweet, err := getVisibleTweet(ctx, l.storage, like.TweetID, like.UserID)
+	if err != nil {
+		l.log.Error("error in service layer while getting liked tweet", logger.Error(err))
+		return models.Like{}, err
+	}
+
+	id, err := l.storage.Likes().Upsert(ctx, like)
+	if err != nil {
+		l.log.Error("error in service layer while liking tweet", logger.Error(err))
+		return models.Like{}, err
+	}
+
+	notify(ctx, l.storage, l.publisher, l.log, models.CreateNotification{
+		UserID:      tweet.UserID,
+		ActorUserID: like.UserID,
+		Type:        models.NotificationTypeLike,
+		TweetID:     &like.TweetID,
+	})
+	publishCounters(ctx, l.storage, l.publisher, l.log, tweet, like.UserID)
+
+	createdLike, err := l.storage.Likes().GetByID(ctx, models.PrimaryKey{ID: id})
+	if err != nil {
+		l.log.Error("error in service layer while getting like by id", logger.Error(err))
+		return models.Like{}, err
+	}
+
+	return createdLike, nil
+}
+
+func (l likesService) Unlike(ctx context.Context, like models.CreateLike) error {
+	if err := l.storage.Likes().DeleteByTweetAndUser(ctx, like); err != nil {
+		l.log.Error("error in service layer while unliking tweet", logger.Error(err))
+		return err
+	}
+
+	if tweet, err := l.storage.Tweets().GetByID(ctx, models.PrimaryKey{ID: like.TweetID}); err == nil {
+		unnotify(ctx, l.storage, l.log, models.CreateNotification{
+			UserID:      tweet.UserID,
+			ActorUserID: like.UserID,
+			Type:        models.NotificationTypeLike,
+			TweetID:     &like.TweetID,
+		})
+		publishCounters(ctx, l.storage, l.publisher, l.log, tweet, like.UserID)
+	}
+
+	return nil
+}
+
+func (l likesService) GetLikingUsers(ctx context.Context, request models.GetListRequest) (models.UserSummariesResponse, error) {
+	if _, err := getVisibleTweet(ctx, l.storage, request.TweetID, request.ViewerID); err != nil {
+		l.log.Error("error in service layer while getting liked tweet", logger.Error(err))
+		return models.UserSummariesResponse{}, err
+	}
+
+	users, err := l.storage.Likes().GetLikingUsers(ctx, request)
+	if err != nil {
+		l.log.Error("error in service layer while getting liking users", logger.Error(err))
+		return models.UserSummariesResponse{}, err
+	}
+
+	return users, nil
+}
+
+// GetLikedTweets lists the tweets the user liked, if the viewer may see the
+// user, leaving out the tweets the viewer may not see.
+func (l likesService) GetLikedTweets(ctx context.Context, request models.GetListRequest) (models.TweetsResponse, error) {
+	if err := ensureVisible(ctx, l.storage, request.UserID, request.ViewerID); err != nil {
+		l.log.Error("error in service layer while checking user visibility", logger.Error(err))
+		return models.TweetsResponse{}, err
+	}
+
+	tweets, err := l.storage.Likes().GetLikedTweets(ctx, request)
+	if err != nil {
+		l.log.Error("error in service layer while getting liked tweets", logger.Error(err))
+		return models.TweetsResponse{}, err
+	}
+
+	if err = hydrateTweets(ctx, l.storage, request.ViewerID, tweets.Tweets); err != nil {
+		l.log.Error("error in service layer while hydrating tweets", logger.Error(err))
+		return models.TweetsResponse{}, err
+	}
+
+	return tweets, nil
 }
@@ -0,0 +1,270 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"test/api/models"
+	"test/pkg/logger"
+	"test/storage"
+	"unicode/utf8"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	maxListNameLength        = 25
+	maxListDescriptionLength = 100
+	maxListMembers           = 5000
+)
+
+type listsService struct {
+	storage storage.IStorage
+	log     logger.ILogger
+}
+
+func NewListsService(storage storage.IStorage, log logger.ILogger) listsService {
+	return listsService{storage: storage, log: log}
+}
+
+func (l listsService) Create(ctx context.Context, list models.CreateList) (models.List, error) {
+	l.log.Info("list create service layer", logger.Any("list", list))
+
+	name, description, err := checkListDetails(list.Name, list.Description)
+	if err != nil {
+		return models.List{}, err
+	}
+	list.Name, list.Description = name, description
+
+	id, err := l.storage.Lists().Create(ctx, list)
+	if err != nil {
+		l.log.Error("error in service layer while creating list", logger.Error(err))
+		return models.List{}, err
+	}
+
+	createdList, err := l.storage.Lists().GetByID(ctx, id, list.OwnerUserID)
+	if err != nil {
+		l.log.Error("error in service layer while getting list by id", logger.Error(err))
+		return models.List{}, err
+	}
+
+	return createdList, nil
+}
+
+func (l listsService) Get(ctx context.Context, listID, viewerID string) (models.List, error) {
+	list, err := l.storage.Lists().GetByID(ctx, listID, viewerID)
+	if err != nil {
+		l.log.Error("error in service layer while getting list", logger.Error(err))
+		return models.List{}, err
+	}
+
+	return list, nil
+}
+
+func (l listsService) Update(ctx context.Context, list models.UpdateList) (models.List, error) {
+	if _, err := l.getOwnList(ctx, list.ListID, list.OwnerUserID); err != nil {
+		l.log.Error("error in service layer while getting list", logger.Error(err))
+		return models.List{}, err
+	}
+
+	name, description, err := checkListDetails(list.Name, list.Description)
+	if err != nil {
+		return models.List{}, err
+	}
+	list.Name, list.Description = name, description
+
+	if err = l.storage.Lists().Update(ctx, list); err != nil {
+		l.log.Error("error in service layer while updating list", logger.Error(err))
+		return models.List{}, err
+	}
+
+	updatedList, err := l.storage.Lists().GetByID(ctx, list.ListID, list.OwnerUserID)
+	if err != nil {
+		l.log.Error("error in service layer while getting updated list by id", logger.Error(err))
+		return models.List{}, err
+	}
+
+	return updatedList, nil
+}
+
+func (l listsService) Delete(ctx context.Context, key models.PrimaryKey, ownerID string) error {
+	if _, err := l.getOwnList(ctx, key.ID, ownerID); err != nil {
+		l.log.Error("error in service layer while getting list", logger.Error(err))
+		return err
+	}
+
+	if err := l.storage.Lists().Delete(ctx, key, ownerID); err != nil {
+		l.log.Error("error in service layer while deleting list", logger.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+func (l listsService) GetUserLists(ctx context.Context, request models.GetListRequest) (models.ListsResponse, error) {
+	lists, err := l.storage.Lists().GetUserLists(ctx, request)
+	if err != nil {
+		l.log.Error("error in service layer while getting user lists", logger.Error(err))
+		return models.ListsResponse{}, err
+	}
+
+	return lists, nil
+}
+
+func (l listsService) GetSubscribedLists(ctx context.Context, request models.GetListRequest) (models.ListsResponse, error) {
+	lists, err := l.storage.Lists().GetSubscribedLists(ctx, request)
+	if err != nil {
+		l.log.Error("error in service layer while getting subscribed lists", logger.Error(err))
+		return models.ListsResponse{}, err
+	}
+
+	return lists, nil
+}
+
+// AddMember adds a user to a list of ownerID. Users who blocked the owner or
+// were blocked by them cannot be added.
+func (l listsService) AddMember(ctx context.Context, member models.ListMember, ownerID string) error {
+	list, err := l.getOwnList(ctx, member.ListID, ownerID)
+	if err != nil {
+		l.log.Error("error in service layer while getting list", logger.Error(err))
+		return err
+	}
+
+	if list.MemberCount >= maxListMembers {
+		return fmt.Errorf("%w: a list can have at most %d members", ErrInvalid, maxListMembers)
+	}
+
+	if _, err = l.storage.User().GetByID(ctx, models.PrimaryKey{ID: member.UserID}); err != nil {
+		l.log.Error("error in service layer while getting user to add to list", logger.Error(err))
+		return err
+	}
+
+	if err = ensureNotBlocked(ctx, l.storage, member.UserID, ownerID, "add to a list"); err != nil {
+		l.log.Error("error in service layer while checking block", logger.Error(err))
+		return err
+	}
+
+	if err = l.storage.Lists().AddMember(ctx, member); err != nil {
+		l.log.Error("error in service layer while adding list member", logger.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+func (l listsService) RemoveMember(ctx context.Context, member models.ListMember, ownerID string) error {
+	if _, err := l.getOwnList(ctx, member.ListID, ownerID); err != nil {
+		l.log.Error("error in service layer while getting list", logger.Error(err))
+		return err
+	}
+
+	if err := l.storage.Lists().RemoveMember(ctx, member); err != nil {
+		l.log.Error("error in service layer while removing list member", logger.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+func (l listsService) GetMembers(ctx context.Context, request models.GetListRequest) (models.UserSummariesResponse, error) {
+	if _, err := l.storage.Lists().GetByID(ctx, request.ListID, request.ViewerID); err != nil {
+		l.log.Error("error in service layer while getting list", logger.Error(err))
+		return models.UserSummariesResponse{}, err
+	}
+
+	members, err := l.storage.Lists().GetMembers(ctx, request)
+	if err != nil {
+		l.log.Error("error in service layer while getting list members", logger.Error(err))
+		return models.UserSummariesResponse{}, err
+	}
+
+	return members, nil
+}
+
+// Subscribe subscribes the user to a list of another user that they can see.
+func (l listsService) Subscribe(ctx context.Context, subscriber models.ListMember) (models.List, error) {
+	list, err := l.storage.Lists().GetByID(ctx, subscriber.ListID, subscriber.UserID)
+	if err != nil {
+		l.log.Error("error in service layer while getting list", logger.Error(err))
+		return models.List{}, err
+	}
+
+	if list.OwnerUserID == subscriber.UserID {
+		return models.List{}, fmt.Errorf("%w: you cannot subscribe to your own list", ErrInvalid)
+	}
+
+	if err = l.storage.Lists().Subscribe(ctx, subscriber); err != nil {
+		l.log.Error("error in service layer while subscribing to list", logger.Error(err))
+		return models.List{}, err
+	}
+
+	return l.Get(ctx, subscriber.ListID, subscriber.UserID)
+}
+
+func (l listsService) Unsubscribe(ctx context.Context, subscriber models.ListMember) error {
+	if err := l.storage.Lists().Unsubscribe(ctx, subscriber); err != nil {
+		l.log.Error("error in service layer while unsubscribing from list", logger.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// GetTimeline lists the tweets of the list members that the viewer may see.
+func (l listsService) GetTimeline(ctx context.Context, request models.GetListRequest) (models.TweetsResponse, error) {
+	if _, err := l.storage.Lists().GetByID(ctx, request.ListID, request.ViewerID); err != nil {
+		l.log.Error("error in service layer while getting list", logger.Error(err))
+		return models.TweetsResponse{}, err
+	}
+
+	tweets, err := l.storage.Lists().GetTimeline(ctx, request)
+	if err != nil {
+		l.log.Error("error in service layer while getting list timeline", logger.Error(err))
+		return models.TweetsResponse{}, err
+	}
+
+	if err = hydrateTweets(ctx, l.storage, request.ViewerID, tweets.Tweets); err != nil {
+		l.log.Error("error in service layer while hydrating tweets", logger.Error(err))
+		return models.TweetsResponse{}, err
+	}
+
+	return tweets, nil
+}
+
+// getOwnList loads a list userID may change, lists of other users are
+// ErrForbidden and lists userID cannot see are ErrNotFound.
+func (l listsService) getOwnList(ctx context.Context, listID, userID string) (models.List, error) {
+	list, err := l.storage.Lists().GetByID(ctx, listID, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.List{}, ErrNotFound
+		}
+		return models.List{}, err
+	}
+
+	if list.OwnerUserID != userID {
+		return models.List{}, fmt.Errorf("%w: only the owner can change this list", ErrForbidden)
+	}
+
+	return list, nil
+}
+
+// checkListDetails trims the name and description of a list and checks their
+// length.
+func checkListDetails(name, description string) (string, string, error) {
+	name, description = strings.TrimSpace(name), strings.TrimSpace(description)
+	if name == "" {
+		return "", "", fmt.Errorf("%w: list name is required", ErrInvalid)
+	}
+
+	if utf8.RuneCountInString(name) > maxListNameLength {
+		return "", "", fmt.Errorf("%w: list name is too long", ErrInvalid)
+	}
+
+	if utf8.RuneCountInString(description) > maxListDescriptionLength {
+		return "", "", fmt.Errorf("%w: list description is too long", ErrInvalid)
+	}
+
+	return name, description, nil
+}
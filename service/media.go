@@ -0,0 +1,584 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"test/api/models"
+	"test/pkg/blob"
+	"test/pkg/logger"
+	"test/storage"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	// MaxMediaUploadSize is the largest media uploaded in a single request,
+	// larger videos are uploaded in chunks.
+	MaxMediaUploadSize = 15 << 20
+	MaxMediaChunkSize  = 5 << 20
+
+	maxImageSize    = 5 << 20
+	maxVideoSize    = 512 << 20
+	maxTweetImages  = 4
+	mediaSniffBytes = 512
+)
+
+type mediaFormat struct {
+	mediaType string
+	extension string
+}
+
+// mediaFormats are the accepted media by MIME type, as sniffed by
+// http.DetectContentType.
+var mediaFormats = map[string]mediaFormat{
+	"image/jpeg": {models.MediaTypeImage, ".jpg"},
+	"image/png":  {models.MediaTypeImage, ".png"},
+	"image/gif":  {models.MediaTypeImage, ".gif"},
+	"image/webp": {models.MediaTypeImage, ".webp"},
+	"video/mp4":  {models.MediaTypeVideo, ".mp4"},
+	"video/webm": {models.MediaTypeVideo, ".webm"},
+}
+
+type mediaService struct {
+	storage storage.IStorage
+	blobs   blob.Store
+	// processing wakes up the processor when an image is uploaded
+	processing chan struct{}
+	log        logger.ILogger
+}
+
+func NewMediaService(storage storage.IStorage, blobs blob.Store, log logger.ILogger) mediaService {
+	return mediaService{storage: storage, blobs: blobs, processing: make(chan struct{}, 1), log: log}
+}
+
+// Upload stores media sent in a single request for the purpose given. Its
+// type is sniffed from its content, whatever the client claims. Avatars and
+// banners are images, set on the profile once processed.
+func (m mediaService) Upload(ctx context.Context, userID, purpose string, r io.Reader, size int64) (models.Media, error) {
+	head, err := readHead(r)
+	if err != nil {
+		m.log.Error("error in service layer while reading uploaded media", logger.Error(err))
+		return models.Media{}, err
+	}
+
+	mimeType := http.DetectContentType(head)
+	format, err := checkMediaFormat(mimeType, size)
+	if err != nil {
+		return models.Media{}, err
+	}
+
+	if purpose != models.MediaPurposeTweet && format.mediaType != models.MediaTypeImage {
+		return models.Media{}, fmt.Errorf("%w: %s must be an image", ErrInvalid, purpose)
+	}
+
+	id, err := m.storage.Media().Create(ctx, models.CreateMedia{
+		UserID:   userID,
+		Purpose:  purpose,
+		Type:     format.mediaType,
+		MimeType: mimeType,
+		Size:     size,
+	})
+	if err != nil {
+		m.log.Error("error in service layer while creating media", logger.Error(err))
+		return models.Media{}, err
+	}
+
+	return m.store(ctx, id, mimeType, io.MultiReader(bytes.NewReader(head), r), size)
+}
+
+// InitUpload starts a chunked upload of media of the declared type and size,
+// to be attached to a tweet.
+func (m mediaService) InitUpload(ctx context.Context, media models.CreateMedia) (models.Media, error) {
+	format, err := checkMediaFormat(media.MimeType, media.Size)
+	if err != nil {
+		return models.Media{}, err
+	}
+	media.Type = format.mediaType
+	media.Purpose = models.MediaPurposeTweet
+
+	id, err := m.storage.Media().Create(ctx, media)
+	if err != nil {
+		m.log.Error("error in service layer while creating media", logger.Error(err))
+		return models.Media{}, err
+	}
+
+	return m.storage.Media().GetByID(ctx, models.PrimaryKey{ID: id})
+}
+
+// AppendChunk stores the next chunk of a chunked upload. Chunks are sent in
+// order, each starting at the received size of the media, so a client whose
+// upload was interrupted gets the media and resumes from there.
+func (m mediaService) AppendChunk(ctx context.Context, chunk models.MediaChunk, data []byte) (models.Media, error) {
+	media, err := m.getOwnMedia(ctx, chunk.MediaID, chunk.UserID)
+	if err != nil {
+		return models.Media{}, err
+	}
+
+	if err = checkChunk(media, chunk.Offset, int64(len(data))); err != nil {
+		return models.Media{}, err
+	}
+
+	// every attempt gets its own key so a concurrent retry of the chunk does
+	// not overwrite the one recorded
+	chunk.Size = int64(len(data))
+	chunk.StorageKey = path.Join("uploads", media.ID, uuid.NewString())
+	if err = m.blobs.Put(ctx, chunk.StorageKey, bytes.NewReader(data), chunk.Size, "application/octet-stream"); err != nil {
+		m.log.Error("error in service layer while storing media chunk", logger.Error(err))
+		return models.Media{}, err
+	}
+
+	if err = m.storage.Media().AddChunk(ctx, chunk); err != nil {
+		m.deleteBlob(ctx, chunk.StorageKey)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Media{}, fmt.Errorf("%w: chunk was uploaded concurrently", ErrInvalid)
+		}
+		m.log.Error("error in service layer while adding media chunk", logger.Error(err))
+		return models.Media{}, err
+	}
+
+	return m.storage.Media().GetByID(ctx, models.PrimaryKey{ID: media.ID})
+}
+
+// FinalizeUpload assembles the chunks of a fully uploaded media. The media
+// fails when its content is not of the declared type of media.
+func (m mediaService) FinalizeUpload(ctx context.Context, mediaID, userID string) (models.Media, error) {
+	media, err := m.getOwnMedia(ctx, mediaID, userID)
+	if err != nil {
+		return models.Media{}, err
+	}
+
+	if media.State != models.MediaStateUploading {
+		return models.Media{}, fmt.Errorf("%w: media is already uploaded", ErrInvalid)
+	}
+	if media.ReceivedSize != media.Size {
+		return models.Media{}, fmt.Errorf("%w: %d of %d bytes uploaded", ErrInvalid, media.ReceivedSize, media.Size)
+	}
+
+	chunks, err := m.storage.Media().GetChunks(ctx, media.ID)
+	if err != nil {
+		m.log.Error("error in service layer while getting media chunks", logger.Error(err))
+		return models.Media{}, err
+	}
+
+	r := &chunkReader{ctx: ctx, blobs: m.blobs, chunks: chunks}
+	defer r.Close()
+
+	head, err := readHead(r)
+	if err != nil {
+		m.log.Error("error in service layer while reading media chunks", logger.Error(err))
+		return models.Media{}, err
+	}
+
+	mimeType := http.DetectContentType(head)
+	if format, ok := mediaFormats[mimeType]; !ok || format.mediaType != media.Type {
+		m.fail(ctx, media.ID, "unsupported content")
+		m.deleteChunks(ctx, chunks)
+		return models.Media{}, fmt.Errorf("%w: uploaded content is not a supported %s", ErrInvalid, media.Type)
+	}
+
+	completed, err := m.store(ctx, media.ID, mimeType, io.MultiReader(bytes.NewReader(head), r), media.Size)
+	if err != nil {
+		return models.Media{}, err
+	}
+
+	m.deleteChunks(ctx, chunks)
+
+	return completed, nil
+}
+
+// RemoveProfileMedia removes the avatar or banner of the user and deletes it.
+func (m mediaService) RemoveProfileMedia(ctx context.Context, userID, purpose string) error {
+	replacedID, err := m.storage.Media().SetProfileMedia(ctx, userID, purpose, nil)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotFound
+		}
+		m.log.Error("error in service layer while removing profile media", logger.Error(err))
+		return err
+	}
+
+	if replacedID != "" {
+		m.deleteMedia(ctx, replacedID)
+	}
+
+	return nil
+}
+
+// GetProfileMediaURL returns the url of the file of the avatar or banner of
+// the user, of the named size or the original one when size is empty.
+func (m mediaService) GetProfileMediaURL(ctx context.Context, userID, purpose, size string) (string, error) {
+	media, err := m.storage.Media().GetProfileMedia(ctx, userID, purpose)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", ErrNotFound
+		}
+		m.log.Error("error in service layer while getting profile media", logger.Error(err))
+		return "", err
+	}
+
+	if size == "" {
+		return media.URL, nil
+	}
+
+	for _, variant := range media.Variants {
+		if variant.Name == size {
+			return variant.URL, nil
+		}
+	}
+
+	// images smaller than a size have no variant of it
+	for _, option := range imageOptions(purpose).Sizes {
+		if option.Name == size {
+			return media.URL, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: unknown %s size %q", ErrInvalid, purpose, size)
+}
+
+// UpdateAltText sets the alt text of the user's media, before or after it is
+// posted.
+func (m mediaService) UpdateAltText(ctx context.Context, request models.UpdateMediaAltText) (models.Media, error) {
+	request.AltText = strings.TrimSpace(request.AltText)
+	if utf8.RuneCountInString(request.AltText) > models.MaxAltTextLength {
+		return models.Media{}, fmt.Errorf("%w: alt text is longer than %d characters", ErrInvalid, models.MaxAltTextLength)
+	}
+
+	if _, err := m.getOwnMedia(ctx, request.MediaID, request.UserID); err != nil {
+		return models.Media{}, err
+	}
+
+	if err := m.storage.Media().UpdateAltText(ctx, request); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Media{}, ErrNotFound
+		}
+		m.log.Error("error in service layer while updating media alt text", logger.Error(err))
+		return models.Media{}, err
+	}
+
+	return m.storage.Media().GetByID(ctx, models.PrimaryKey{ID: request.MediaID})
+}
+
+// Get returns the user's media, for clients to resume its upload.
+func (m mediaService) Get(ctx context.Context, mediaID, userID string) (models.Media, error) {
+	return m.getOwnMedia(ctx, mediaID, userID)
+}
+
+// Open returns the content of a stored media file and its MIME type. Only
+// media ready to be served is, never the chunks of uploads or the original
+// images still carrying their metadata.
+func (m mediaService) Open(ctx context.Context, key string) (io.ReadCloser, string, error) {
+	key = strings.TrimPrefix(path.Clean("/"+key), "/")
+	if !strings.HasPrefix(key, "media/") {
+		return nil, "", ErrNotFound
+	}
+
+	mimeType := "application/octet-stream"
+	for candidate, format := range mediaFormats {
+		if path.Ext(key) == format.extension {
+			mimeType = candidate
+		}
+	}
+
+	file, err := m.blobs.Get(ctx, key)
+	if err != nil {
+		if errors.Is(err, blob.ErrNotFound) {
+			return nil, "", ErrNotFound
+		}
+		m.log.Error("error in service layer while opening media file", logger.Error(err))
+		return nil, "", err
+	}
+
+	return file, mimeType, nil
+}
+
+// store puts the content of uploaded media in the blob store, or marks it
+// failed when it cannot. Videos are ready to be served right away, images
+// are kept out of reach until processing strips their metadata.
+func (m mediaService) store(ctx context.Context, id, mimeType string, r io.Reader, size int64) (models.Media, error) {
+	format := mediaFormats[mimeType]
+
+	completed := models.CompleteMedia{ID: id, MimeType: mimeType}
+	if format.mediaType == models.MediaTypeImage {
+		completed.StorageKey = "originals/" + id + format.extension
+		completed.State = models.MediaStateProcessing
+	} else {
+		completed.StorageKey = "media/" + id + format.extension
+		completed.URL = m.blobs.URL(completed.StorageKey)
+		completed.State = models.MediaStateReady
+	}
+
+	if err := m.blobs.Put(ctx, completed.StorageKey, r, size, mimeType); err != nil {
+		m.log.Error("error in service layer while storing media", logger.Error(err))
+		m.fail(ctx, id, "storing failed")
+		return models.Media{}, err
+	}
+
+	if err := m.storage.Media().Complete(ctx, completed); err != nil {
+		m.log.Error("error in service layer while completing media", logger.Error(err))
+		m.deleteBlob(ctx, completed.StorageKey)
+		return models.Media{}, err
+	}
+
+	if completed.State == models.MediaStateProcessing {
+		select {
+		case m.processing <- struct{}{}:
+		default:
+		}
+	}
+
+	return m.storage.Media().GetByID(ctx, models.PrimaryKey{ID: id})
+}
+
+func (m mediaService) getOwnMedia(ctx context.Context, mediaID, userID string) (models.Media, error) {
+	media, err := m.storage.Media().GetByID(ctx, models.PrimaryKey{ID: mediaID})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Media{}, ErrNotFound
+		}
+		m.log.Error("error in service layer while getting media", logger.Error(err))
+		return models.Media{}, err
+	}
+
+	// media of other users is only seen through their tweets
+	if media.UserID != userID {
+		return models.Media{}, ErrNotFound
+	}
+
+	return media, nil
+}
+
+func (m mediaService) fail(ctx context.Context, mediaID, reason string) {
+	if err := m.storage.Media().Fail(ctx, mediaID, reason); err != nil {
+		m.log.Error("error in service layer while marking media failed", logger.Error(err))
+	}
+}
+
+// deleteMedia deletes the media and its files.
+func (m mediaService) deleteMedia(ctx context.Context, mediaID string) {
+	media, err := m.storage.Media().GetByID(ctx, models.PrimaryKey{ID: mediaID})
+	if err != nil {
+		m.log.Error("error in service layer while getting media to delete", logger.Error(err))
+		return
+	}
+
+	if err = m.storage.Media().Delete(ctx, models.PrimaryKey{ID: mediaID}); err != nil {
+		m.log.Error("error in service layer while deleting media", logger.Error(err))
+		return
+	}
+
+	deleteMediaFiles(ctx, m.blobs, m.log, media)
+}
+
+func (m mediaService) deleteChunks(ctx context.Context, chunks []models.MediaChunk) {
+	for _, chunk := range chunks {
+		m.deleteBlob(ctx, chunk.StorageKey)
+	}
+}
+
+func (m mediaService) deleteBlob(ctx context.Context, key string) {
+	deleteMediaBlob(ctx, m.blobs, m.log, key)
+}
+
+// deleteMediaFiles removes the stored files of the media and of its
+// variants.
+func deleteMediaFiles(ctx context.Context, blobs blob.Store, log logger.ILogger, media models.Media) {
+	if media.StorageKey != "" {
+		deleteMediaBlob(ctx, blobs, log, media.StorageKey)
+	}
+
+	for _, variant := range media.Variants {
+		deleteMediaBlob(ctx, blobs, log, variant.StorageKey)
+	}
+}
+
+// deleteMediaBlob removes a stored file, logging failures as the file is
+// only left behind.
+func deleteMediaBlob(ctx context.Context, blobs blob.Store, log logger.ILogger, key string) {
+	if err := blobs.Delete(ctx, key); err != nil {
+		log.Error("error while deleting media file", logger.String("key", key), logger.Error(err))
+	}
+}
+
+// checkMediaFormat returns the format of media of the MIME type, checking it
+// is accepted and within the size limit of its type.
+func checkMediaFormat(mimeType string, size int64) (mediaFormat, error) {
+	format, ok := mediaFormats[mimeType]
+	if !ok {
+		return mediaFormat{}, fmt.Errorf("%w: unsupported media type %q", ErrInvalid, mimeType)
+	}
+
+	limit := int64(maxImageSize)
+	if format.mediaType == models.MediaTypeVideo {
+		limit = maxVideoSize
+	}
+
+	if size <= 0 || size > limit {
+		return mediaFormat{}, fmt.Errorf("%w: %s size must be between 1 and %d bytes", ErrInvalid, format.mediaType, limit)
+	}
+
+	return format, nil
+}
+
+// checkChunk checks a chunk of size bytes at offset continues the upload of
+// the media, within the chunk size limit and the declared media size.
+func checkChunk(media models.Media, offset, size int64) error {
+	switch {
+	case media.State != models.MediaStateUploading:
+		return fmt.Errorf("%w: media is already uploaded", ErrInvalid)
+	case offset != media.ReceivedSize:
+		return fmt.Errorf("%w: upload continues at offset %d", ErrInvalid, media.ReceivedSize)
+	case size == 0 || size > MaxMediaChunkSize:
+		return fmt.Errorf("%w: chunks must be between 1 and %d bytes", ErrInvalid, MaxMediaChunkSize)
+	case offset+size > media.Size:
+		return fmt.Errorf("%w: chunk exceeds the media size of %d bytes", ErrInvalid, media.Size)
+	}
+
+	return nil
+}
+
+// checkTweetMedia checks the user can attach the media to the new tweet: up
+// to four images or a single video of theirs, uploaded and not attached yet.
+// Images still processing may be attached, they show up once ready. Images
+// without alt text are refused as the alt text reminder setting of the user
+// says.
+func checkTweetMedia(ctx context.Context, store storage.IStorage, tweet models.CreateTweet) error {
+	var (
+		userID   = tweet.UserID
+		mediaIDs = tweet.MediaIDs
+	)
+	if len(mediaIDs) == 0 {
+		return nil
+	}
+
+	if len(mediaIDs) > maxTweetImages {
+		return fmt.Errorf("%w: a tweet has at most %d images", ErrInvalid, maxTweetImages)
+	}
+
+	seen := make(map[string]bool, len(mediaIDs))
+	for _, id := range mediaIDs {
+		if _, err := uuid.Parse(id); err != nil {
+			return fmt.Errorf("%w: invalid media id %q", ErrInvalid, id)
+		}
+		if seen[id] {
+			return fmt.Errorf("%w: media %s is attached twice", ErrInvalid, id)
+		}
+		seen[id] = true
+	}
+
+	media, err := store.Media().GetByIDs(ctx, mediaIDs)
+	if err != nil {
+		return err
+	}
+
+	var (
+		found          = 0
+		missingAltText = 0
+	)
+	for _, item := range media {
+		if item.UserID != userID {
+			continue
+		}
+		found++
+
+		if item.Type == models.MediaTypeImage && item.AltText == "" {
+			missingAltText++
+		}
+
+		switch {
+		case item.Purpose != models.MediaPurposeTweet:
+			return fmt.Errorf("%w: media %s is not for tweets", ErrInvalid, item.ID)
+		case item.State != models.MediaStateReady && item.State != models.MediaStateProcessing:
+			return fmt.Errorf("%w: media %s is not uploaded", ErrInvalid, item.ID)
+		case item.TweetID != nil:
+			return fmt.Errorf("%w: media %s is attached to another tweet", ErrInvalid, item.ID)
+		case item.Type == models.MediaTypeVideo && len(mediaIDs) > 1:
+			return fmt.Errorf("%w: a video cannot be attached with other media", ErrInvalid)
+		}
+	}
+
+	if found != len(mediaIDs) {
+		return fmt.Errorf("%w: media not found", ErrNotFound)
+	}
+
+	if missingAltText == 0 {
+		return nil
+	}
+
+	settings, err := store.User().GetSettings(ctx, models.PrimaryKey{ID: userID})
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case settings.AltTextReminder == models.AltTextReminderRequire:
+		return fmt.Errorf("%w: %d images have no alt text", ErrInvalid, missingAltText)
+	case settings.AltTextReminder == models.AltTextReminderNudge && !tweet.IgnoreAltTextReminder:
+		return fmt.Errorf("%w: %d images have no alt text, add it or set ignore_alt_text_reminder to post anyway", ErrInvalid, missingAltText)
+	}
+
+	return nil
+}
+
+// readHead reads the first bytes of r, enough to sniff its type.
+func readHead(r io.Reader) ([]byte, error) {
+	head := make([]byte, mediaSniffBytes)
+	n, err := io.ReadFull(r, head)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, err
+	}
+
+	return head[:n], nil
+}
+
+// chunkReader reads the chunks of an upload one after another.
+type chunkReader struct {
+	ctx     context.Context
+	blobs   blob.Store
+	chunks  []models.MediaChunk
+	current io.ReadCloser
+}
+
+func (c *chunkReader) Read(p []byte) (int, error) {
+	for {
+		if c.current == nil {
+			if len(c.chunks) == 0 {
+				return 0, io.EOF
+			}
+
+			file, err := c.blobs.Get(c.ctx, c.chunks[0].StorageKey)
+			if err != nil {
+				return 0, err
+			}
+			c.current, c.chunks = file, c.chunks[1:]
+		}
+
+		n, err := c.current.Read(p)
+		if errors.Is(err, io.EOF) {
+			c.current.Close()
+			c.current = nil
+			if n == 0 {
+				continue
+			}
+			err = nil
+		}
+
+		return n, err
+	}
+}
+
+func (c *chunkReader) Close() error {
+	if c.current == nil {
+		return nil
+	}
+
+	return c.current.Close()
+}
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"path"
+	"sync"
+	"test/api/models"
+	"test/pkg/imaging"
+	"test/pkg/logger"
+	"time"
+)
+
+const (
+	mediaPollInterval = 10 * time.Second
+	// mediaProcessingLease is how long an image is left to a worker before
+	// it is handed to another, should the first one have died.
+	mediaProcessingLease    = 5 * time.Minute
+	maxMediaProcessAttempts = 5
+	mediaRetryDelay         = 30 * time.Second
+)
+
+// RunProcessor processes uploaded images with a pool of workers until ctx is
+// done: their metadata is stripped, variants are generated and a blurhash is
+// computed. Failed attempts are retried with an increasing delay. Images
+// uploaded on any instance are picked up, those uploaded on this one right
+// away.
+func (m mediaService) RunProcessor(ctx context.Context, workers int) error {
+	workers = max(workers, 1)
+
+	var (
+		jobs = make(chan models.Media)
+		wg   sync.WaitGroup
+	)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for media := range jobs {
+				m.process(ctx, media)
+			}
+		}()
+	}
+	defer func() {
+		close(jobs)
+		wg.Wait()
+	}()
+
+	ticker := time.NewTicker(mediaPollInterval)
+	defer ticker.Stop()
+
+	for {
+		claimed, err := m.storage.Media().ClaimProcessing(ctx, workers, mediaProcessingLease)
+		if err != nil {
+			m.log.Error("error while claiming media to process", logger.Error(err))
+		}
+
+		for _, media := range claimed {
+			select {
+			case jobs <- media:
+			case <-ctx.Done():
+				return nil
+			}
+		}
+
+		// a full batch means more images may be waiting
+		if len(claimed) == workers {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		case <-m.processing:
+		}
+	}
+}
+
+// process processes an image, scheduling another attempt when it fails for
+// any other reason than the image being invalid.
+func (m mediaService) process(ctx context.Context, media models.Media) {
+	err := m.processImage(ctx, media)
+	if err == nil {
+		return
+	}
+
+	m.log.Error("error while processing media", logger.String("media_id", media.ID), logger.Int("attempt", media.Attempts), logger.Error(err))
+
+	if errors.Is(err, imaging.ErrInvalidImage) || media.Attempts >= maxMediaProcessAttempts {
+		m.fail(ctx, media.ID, err.Error())
+		return
+	}
+
+	retryAt := time.Now().Add(mediaRetryDelay << (media.Attempts - 1))
+	if err = m.storage.Media().RetryProcessing(ctx, media.ID, err.Error(), retryAt); err != nil {
+		m.log.Error("error while scheduling media processing retry", logger.Error(err))
+	}
+}
+
+func (m mediaService) processImage(ctx context.Context, media models.Media) error {
+	file, err := m.blobs.Get(ctx, media.StorageKey)
+	if err != nil {
+		return err
+	}
+
+	data, err := io.ReadAll(file)
+	file.Close()
+	if err != nil {
+		return err
+	}
+
+	result, err := imaging.Process(data, imageOptions(media.Purpose))
+	if err != nil {
+		return err
+	}
+
+	format, ok := mediaFormats[result.MimeType]
+	if !ok {
+		return imaging.ErrInvalidImage
+	}
+
+	dir := path.Join("media", media.ID)
+	processed := models.ProcessedMedia{
+		ID:         media.ID,
+		UserID:     media.UserID,
+		Purpose:    media.Purpose,
+		MimeType:   result.MimeType,
+		Size:       int64(len(result.Data)),
+		StorageKey: path.Join(dir, "image"+format.extension),
+		Width:      result.Width,
+		Height:     result.Height,
+		Blurhash:   result.Blurhash,
+	}
+	processed.URL = m.blobs.URL(processed.StorageKey)
+
+	if err = m.blobs.Put(ctx, processed.StorageKey, bytes.NewReader(result.Data), processed.Size, result.MimeType); err != nil {
+		return err
+	}
+
+	for _, variant := range result.Variants {
+		key := path.Join(dir, variant.Name+mediaFormats[variant.MimeType].extension)
+		if err = m.blobs.Put(ctx, key, bytes.NewReader(variant.Data), int64(len(variant.Data)), variant.MimeType); err != nil {
+			return err
+		}
+
+		processed.Variants = append(processed.Variants, models.MediaVariant{
+			Name:       variant.Name,
+			Width:      variant.Width,
+			Height:     variant.Height,
+			URL:        m.blobs.URL(key),
+			StorageKey: key,
+		})
+	}
+
+	replacedID, err := m.storage.Media().FinishProcessing(ctx, processed)
+	if err != nil {
+		return err
+	}
+
+	// the original carries the metadata that was stripped
+	if media.StorageKey != processed.StorageKey {
+		m.deleteBlob(ctx, media.StorageKey)
+	}
+
+	if replacedID != "" {
+		m.deleteMedia(ctx, replacedID)
+	}
+
+	return nil
+}
+
+// imageOptions returns how images uploaded for the purpose are
+// processed: avatars are square and banners three times as wide as high.
+func imageOptions(purpose string) imaging.Options {
+	switch purpose {
+	case models.MediaPurposeAvatar:
+		return imaging.AvatarOptions
+	case models.MediaPurposeBanner:
+		return imaging.BannerOptions
+	default:
+		return imaging.TweetOptions
+	}
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"test/api/models"
+	"test/pkg/blob"
+	"testing"
+)
+
+func TestSniffMedia(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		size      int64
+		mediaType string
+		wantErr   bool
+	}{
+		{name: "png", content: "\x89PNG\r\n\x1a\n" + strings.Repeat("\x00", 600), size: 1024, mediaType: models.MediaTypeImage},
+		{name: "jpeg", content: "\xff\xd8\xff\xe0", size: 4, mediaType: models.MediaTypeImage},
+		{name: "gif", content: "GIF89a", size: 6, mediaType: models.MediaTypeImage},
+		{name: "webp", content: "RIFF\x00\x00\x00\x00WEBPVP8 ", size: 16, mediaType: models.MediaTypeImage},
+		{name: "mp4", content: "\x00\x00\x00\x18ftypmp42\x00\x00\x00\x00mp42isom", size: 24, mediaType: models.MediaTypeVideo},
+		{name: "webm", content: "\x1a\x45\xdf\xa3", size: 4, mediaType: models.MediaTypeVideo},
+		{name: "large video", content: "\x1a\x45\xdf\xa3", size: maxImageSize + 1, mediaType: models.MediaTypeVideo},
+		{name: "html named as an image", content: "<html><script>alert(1)</script></html>", size: 38, wantErr: true},
+		{name: "svg", content: `<?xml version="1.0"?><svg xmlns="http://www.w3.org/2000/svg"/>`, size: 62, wantErr: true},
+		{name: "empty", content: "", size: 0, wantErr: true},
+		{name: "image too large", content: "GIF89a", size: maxImageSize + 1, wantErr: true},
+		{name: "video too large", content: "\x1a\x45\xdf\xa3", size: maxVideoSize + 1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			head, err := readHead(strings.NewReader(tt.content))
+			if err != nil {
+				t.Fatalf("readHead() error = %v", err)
+			}
+
+			if len(head) > mediaSniffBytes {
+				t.Fatalf("readHead() read %d bytes, want at most %d", len(head), mediaSniffBytes)
+			}
+
+			format, err := checkMediaFormat(http.DetectContentType(head), tt.size)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalid) {
+					t.Errorf("checkMediaFormat() error = %v, want %v", err, ErrInvalid)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("checkMediaFormat() error = %v", err)
+			}
+
+			if format.mediaType != tt.mediaType {
+				t.Errorf("checkMediaFormat() media type = %q, want %q", format.mediaType, tt.mediaType)
+			}
+		})
+	}
+}
+
+func TestCheckChunk(t *testing.T) {
+	uploading := models.Media{State: models.MediaStateUploading, Size: MaxMediaChunkSize + 10, ReceivedSize: 10}
+
+	tests := []struct {
+		name    string
+		media   models.Media
+		offset  int64
+		size    int64
+		wantErr bool
+	}{
+		{name: "next chunk", media: uploading, offset: 10, size: 100},
+		{name: "last chunk", media: uploading, offset: 10, size: MaxMediaChunkSize},
+		{name: "gap", media: uploading, offset: 20, size: 100, wantErr: true},
+		{name: "overlap", media: uploading, offset: 0, size: 100, wantErr: true},
+		{name: "negative offset", media: uploading, offset: -1, size: 100, wantErr: true},
+		{name: "empty chunk", media: uploading, offset: 10, size: 0, wantErr: true},
+		{name: "chunk too large", media: models.Media{State: models.MediaStateUploading, Size: 2 * MaxMediaChunkSize}, offset: 0, size: MaxMediaChunkSize + 1, wantErr: true},
+		{name: "past the media size", media: models.Media{State: models.MediaStateUploading, Size: 100, ReceivedSize: 50}, offset: 50, size: 51, wantErr: true},
+		{name: "already uploaded", media: models.Media{State: models.MediaStateProcessing, Size: 100, ReceivedSize: 100}, offset: 100, size: 1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkChunk(tt.media, tt.offset, tt.size)
+			if tt.wantErr && !errors.Is(err, ErrInvalid) {
+				t.Errorf("checkChunk() error = %v, want %v", err, ErrInvalid)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("checkChunk() error = %v, want nil", err)
+			}
+		})
+	}
+}
+
+func TestChunkReader(t *testing.T) {
+	ctx := context.Background()
+	blobs := blob.NewLocalStore(t.TempDir(), "")
+
+	chunks := []models.MediaChunk{}
+	for i, data := range []string{"GIF8", "9a", "", "rest"} {
+		key := "uploads/media/" + string(rune('a'+i))
+		if err := blobs.Put(ctx, key, strings.NewReader(data), int64(len(data)), ""); err != nil {
+			t.Fatalf("Put() error = %v", err)
+		}
+		chunks = append(chunks, models.MediaChunk{StorageKey: key})
+	}
+
+	r := &chunkReader{ctx: ctx, blobs: blobs, chunks: chunks}
+	defer r.Close()
+
+	head, err := readHead(r)
+	if err != nil {
+		t.Fatalf("readHead() error = %v", err)
+	}
+
+	// the type is sniffed across chunk boundaries
+	if got := http.DetectContentType(head); got != "image/gif" {
+		t.Errorf("DetectContentType() = %q, want %q", got, "image/gif")
+	}
+
+	rest, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+
+	if got := string(bytes.Join([][]byte{head, rest}, nil)); got != "GIF89arest" {
+		t.Errorf("chunks read = %q, want %q", got, "GIF89arest")
+	}
+}
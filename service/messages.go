@@ -0,0 +1,249 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"test/api/models"
+	"test/pkg/logger"
+	"test/pkg/pubsub"
+	"test/storage"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	maxGroupMembers           = 50
+	maxConversationNameLength = 100
+	maxMessageLength          = 10000
+)
+
+type messagesService struct {
+	storage   storage.IStorage
+	publisher pubsub.Publisher
+	log       logger.ILogger
+}
+
+func NewMessagesService(storage storage.IStorage, publisher pubsub.Publisher, log logger.ILogger) messagesService {
+	return messagesService{storage: storage, publisher: publisher, log: log}
+}
+
+// CreateConversation starts a conversation with the users, one to one for a
+// single user and a group otherwise. Starting a one to one conversation again
+// returns the existing one.
+func (m messagesService) CreateConversation(ctx context.Context, conversation models.CreateConversation) (models.Conversation, error) {
+	m.log.Info("conversation create service layer", logger.Any("conversation", conversation))
+
+	userIDs := []string{}
+	seen := map[string]bool{conversation.CreatorID: true}
+	for _, userID := range conversation.UserIDs {
+		if !seen[userID] {
+			seen[userID] = true
+			userIDs = append(userIDs, userID)
+		}
+	}
+
+	if len(userIDs) == 0 {
+		return models.Conversation{}, fmt.Errorf("%w: at least one other user is required", ErrInvalid)
+	}
+
+	if len(userIDs)+1 > maxGroupMembers {
+		return models.Conversation{}, fmt.Errorf("%w: a conversation can have at most %d members", ErrInvalid, maxGroupMembers)
+	}
+
+	for _, userID := range userIDs {
+		if err := m.ensureCanMessage(ctx, userID, conversation.CreatorID); err != nil {
+			m.log.Error("error in service layer while checking message permission", logger.Error(err))
+			return models.Conversation{}, err
+		}
+	}
+
+	conversation.UserIDs = userIDs
+	conversation.Name = strings.TrimSpace(conversation.Name)
+	if len(userIDs) == 1 {
+		pair := []string{conversation.CreatorID, userIDs[0]}
+		sort.Strings(pair)
+		conversation.DirectKey = strings.Join(pair, ":")
+		conversation.Name = ""
+	} else if utf8.RuneCountInString(conversation.Name) > maxConversationNameLength {
+		return models.Conversation{}, fmt.Errorf("%w: conversation name is too long", ErrInvalid)
+	}
+
+	id, err := m.storage.Messages().CreateConversation(ctx, conversation)
+	if err != nil {
+		m.log.Error("error in service layer while creating conversation", logger.Error(err))
+		return models.Conversation{}, err
+	}
+
+	createdConversation, err := m.storage.Messages().GetConversation(ctx, id, conversation.CreatorID)
+	if err != nil {
+		m.log.Error("error in service layer while getting conversation by id", logger.Error(err))
+		return models.Conversation{}, err
+	}
+
+	return createdConversation, nil
+}
+
+func (m messagesService) GetConversation(ctx context.Context, conversationID, userID string) (models.Conversation, error) {
+	conversation, err := m.storage.Messages().GetConversation(ctx, conversationID, userID)
+	if err != nil {
+		m.log.Error("error in service layer while getting conversation", logger.Error(err))
+		return models.Conversation{}, err
+	}
+
+	return conversation, nil
+}
+
+func (m messagesService) GetConversations(ctx context.Context, request models.GetListRequest) (models.ConversationsResponse, error) {
+	conversations, err := m.storage.Messages().GetConversations(ctx, request)
+	if err != nil {
+		m.log.Error("error in service layer while getting conversations", logger.Error(err))
+		return models.ConversationsResponse{}, err
+	}
+
+	return conversations, nil
+}
+
+// GetMessages pages through the messages of a conversation userID is a member
+// of.
+func (m messagesService) GetMessages(ctx context.Context, request models.GetMessagesRequest) (models.MessagesResponse, error) {
+	if _, err := m.storage.Messages().GetConversation(ctx, request.ConversationID, request.UserID); err != nil {
+		m.log.Error("error in service layer while getting conversation", logger.Error(err))
+		return models.MessagesResponse{}, err
+	}
+
+	messages, err := m.storage.Messages().GetMessages(ctx, request)
+	if err != nil {
+		m.log.Error("error in service layer while getting messages", logger.Error(err))
+		return models.MessagesResponse{}, err
+	}
+
+	return messages, nil
+}
+
+// Send posts the message to a conversation the sender is a member of and
+// pushes it to the other members. One to one messages must still be allowed
+// by the recipient's blocks and privacy settings.
+func (m messagesService) Send(ctx context.Context, message models.CreateMessage) (models.Message, error) {
+	message.Content = strings.TrimSpace(message.Content)
+	if message.Content == "" {
+		return models.Message{}, fmt.Errorf("%w: message content is required", ErrInvalid)
+	}
+
+	if utf8.RuneCountInString(message.Content) > maxMessageLength {
+		return models.Message{}, fmt.Errorf("%w: message is too long", ErrInvalid)
+	}
+
+	conversation, err := m.storage.Messages().GetConversation(ctx, message.ConversationID, message.SenderUserID)
+	if err != nil {
+		m.log.Error("error in service layer while getting conversation", logger.Error(err))
+		return models.Message{}, err
+	}
+
+	recipientIDs := []string{}
+	for _, member := range conversation.Members {
+		if member.ID != message.SenderUserID {
+			recipientIDs = append(recipientIDs, member.ID)
+		}
+	}
+
+	if !conversation.IsGroup {
+		for _, userID := range recipientIDs {
+			if err = m.ensureCanMessage(ctx, userID, message.SenderUserID); err != nil {
+				m.log.Error("error in service layer while checking message permission", logger.Error(err))
+				return models.Message{}, err
+			}
+		}
+	}
+
+	id, err := m.storage.Messages().CreateMessage(ctx, message)
+	if err != nil {
+		m.log.Error("error in service layer while creating message", logger.Error(err))
+		return models.Message{}, err
+	}
+
+	createdMessage, err := m.storage.Messages().GetMessageByID(ctx, models.PrimaryKey{ID: id})
+	if err != nil {
+		m.log.Error("error in service layer while getting message by id", logger.Error(err))
+		return models.Message{}, err
+	}
+
+	publish(ctx, m.publisher, m.log, recipientIDs, models.EventTypeMessage, createdMessage)
+
+	return createdMessage, nil
+}
+
+// MarkRead marks the conversation read by userID and pushes the read receipt to
+// the other members.
+func (m messagesService) MarkRead(ctx context.Context, conversationID, userID string) error {
+	if _, err := m.storage.Messages().GetConversation(ctx, conversationID, userID); err != nil {
+		m.log.Error("error in service layer while getting conversation", logger.Error(err))
+		return err
+	}
+
+	if err := m.storage.Messages().MarkRead(ctx, conversationID, userID); err != nil {
+		m.log.Error("error in service layer while marking conversation read", logger.Error(err))
+		return err
+	}
+
+	memberIDs, err := m.storage.Messages().GetMemberIDs(ctx, conversationID)
+	if err != nil {
+		m.log.Error("error in service layer while getting conversation members", logger.Error(err))
+		return nil
+	}
+
+	recipientIDs := []string{}
+	for _, memberID := range memberIDs {
+		if memberID != userID {
+			recipientIDs = append(recipientIDs, memberID)
+		}
+	}
+
+	publish(ctx, m.publisher, m.log, recipientIDs, models.EventTypeMessageRead, models.MessageReadEvent{
+		ConversationID: conversationID,
+		UserID:         userID,
+		ReadAt:         time.Now(),
+	})
+
+	return nil
+}
+
+// Leave removes userID from a group conversation.
+func (m messagesService) Leave(ctx context.Context, conversationID, userID string) error {
+	conversation, err := m.storage.Messages().GetConversation(ctx, conversationID, userID)
+	if err != nil {
+		m.log.Error("error in service layer while getting conversation", logger.Error(err))
+		return err
+	}
+
+	if !conversation.IsGroup {
+		return fmt.Errorf("%w: only group conversations can be left", ErrForbidden)
+	}
+
+	if err = m.storage.Messages().Leave(ctx, conversationID, userID); err != nil {
+		m.log.Error("error in service layer while leaving conversation", logger.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// ensureCanMessage returns ErrForbidden when senderID may not message userID
+// because of a block or userID's privacy settings.
+func (m messagesService) ensureCanMessage(ctx context.Context, userID, senderID string) error {
+	if err := ensureNotBlocked(ctx, m.storage, userID, senderID, "message"); err != nil {
+		return err
+	}
+
+	allowed, err := m.storage.Messages().CanMessage(ctx, userID, senderID)
+	if err != nil {
+		return err
+	}
+
+	if !allowed {
+		return fmt.Errorf("%w: this user only accepts messages from people they follow", ErrForbidden)
+	}
+
+	return nil
+}
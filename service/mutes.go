@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"test/api/models"
+	"test/pkg/logger"
+	"test/storage"
+	"unicode/utf8"
+)
+
+const maxMutedKeywordLength = 100
+
+type mutesService struct {
+	storage storage.IStorage
+	log     logger.ILogger
+}
+
+func NewMutesService(storage storage.IStorage, log logger.ILogger) mutesService {
+	return mutesService{storage: storage, log: log}
+}
+
+func (m mutesService) MuteUser(ctx context.Context, mute models.CreateMute) (models.Mute, error) {
+	m.log.Info("mute create service layer", logger.Any("mute", mute))
+
+	if mute.UserID == mute.MutedUserID {
+		return models.Mute{}, fmt.Errorf("%w: you cannot mute yourself", ErrInvalid)
+	}
+
+	id, err := m.storage.Mutes().CreateUserMute(ctx, mute)
+	if err != nil {
+		m.log.Error("error in service layer while muting user", logger.Error(err))
+		return models.Mute{}, err
+	}
+
+	createdMute, err := m.storage.Mutes().GetUserMuteByID(ctx, models.PrimaryKey{ID: id})
+	if err != nil {
+		m.log.Error("error in service layer while getting mute by id", logger.Error(err))
+		return models.Mute{}, err
+	}
+
+	return createdMute, nil
+}
+
+func (m mutesService) UnmuteUser(ctx context.Context, mute models.CreateMute) error {
+	if err := m.storage.Mutes().DeleteUserMute(ctx, mute); err != nil {
+		m.log.Error("error in service layer while unmuting user", logger.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+func (m mutesService) GetMutedUsers(ctx context.Context, request models.GetListRequest) (models.UserSummariesResponse, error) {
+	users, err := m.storage.Mutes().GetMutedUsers(ctx, request)
+	if err != nil {
+		m.log.Error("error in service layer while getting muted users", logger.Error(err))
+		return models.UserSummariesResponse{}, err
+	}
+
+	return users, nil
+}
+
+// MuteKeyword mutes a word, phrase or hashtag. Matching is case insensitive
+// and only whole words match, so muting "go" does not hide "google".
+func (m mutesService) MuteKeyword(ctx context.Context, keyword models.CreateMutedKeyword) (models.MutedKeyword, error) {
+	keyword.Keyword = strings.Join(strings.Fields(strings.ToLower(keyword.Keyword)), " ")
+	if keyword.Keyword == "" {
+		return models.MutedKeyword{}, fmt.Errorf("%w: keyword is required", ErrInvalid)
+	}
+
+	if utf8.RuneCountInString(keyword.Keyword) > maxMutedKeywordLength {
+		return models.MutedKeyword{}, fmt.Errorf("%w: keyword is at most %d characters", ErrInvalid, maxMutedKeywordLength)
+	}
+
+	keyword.Pattern = regexp.QuoteMeta(keyword.Keyword)
+
+	id, err := m.storage.Mutes().CreateKeyword(ctx, keyword)
+	if err != nil {
+		m.log.Error("error in service layer while muting keyword", logger.Error(err))
+		return models.MutedKeyword{}, err
+	}
+
+	createdKeyword, err := m.storage.Mutes().GetKeywordByID(ctx, models.PrimaryKey{ID: id})
+	if err != nil {
+		m.log.Error("error in service layer while getting muted keyword by id", logger.Error(err))
+		return models.MutedKeyword{}, err
+	}
+
+	return createdKeyword, nil
+}
+
+func (m mutesService) UnmuteKeyword(ctx context.Context, key models.PrimaryKey, userID string) error {
+	if err := m.storage.Mutes().DeleteKeyword(ctx, key, userID); err != nil {
+		m.log.Error("error in service layer while unmuting keyword", logger.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+func (m mutesService) GetMutedKeywords(ctx context.Context, request models.GetListRequest) (models.MutedKeywordsResponse, error) {
+	keywords, err := m.storage.Mutes().GetKeywords(ctx, request)
+	if err != nil {
+		m.log.Error("error in service layer while getting muted keywords", logger.Error(err))
+		return models.MutedKeywordsResponse{}, err
+	}
+
+	return keywords, nil
+}
+
+// MuteConversation mutes the conversation the tweet belongs to.
+func (m mutesService) MuteConversation(ctx context.Context, tweetID, userID string) (models.MutedConversation, error) {
+	tweet, err := getVisibleTweet(ctx, m.storage, tweetID, userID)
+	if err != nil {
+		m.log.Error("error in service layer while getting tweet to mute", logger.Error(err))
+		return models.MutedConversation{}, err
+	}
+
+	id, err := m.storage.Mutes().CreateConversationMute(ctx, models.CreateMutedConversation{
+		UserID:         userID,
+		ConversationID: tweet.ConversationID,
+	})
+	if err != nil {
+		m.log.Error("error in service layer while muting conversation", logger.Error(err))
+		return models.MutedConversation{}, err
+	}
+
+	mute, err := m.storage.Mutes().GetConversationMuteByID(ctx, models.PrimaryKey{ID: id})
+	if err != nil {
+		m.log.Error("error in service layer while getting muted conversation by id", logger.Error(err))
+		return models.MutedConversation{}, err
+	}
+
+	return mute, nil
+}
+
+// UnmuteConversation unmutes the conversation the tweet belongs to.
+func (m mutesService) UnmuteConversation(ctx context.Context, tweetID, userID string) error {
+	tweet, err := m.storage.Tweets().GetByID(ctx, models.PrimaryKey{ID: tweetID})
+	if err != nil {
+		m.log.Error("error in service layer while getting tweet to unmute", logger.Error(err))
+		return err
+	}
+
+	if err = m.storage.Mutes().DeleteConversationMute(ctx, models.CreateMutedConversation{
+		UserID:         userID,
+		ConversationID: tweet.ConversationID,
+	}); err != nil {
+		m.log.Error("error in service layer while unmuting conversation", logger.Error(err))
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"test/api/models"
+	"test/pkg/logger"
+	"test/pkg/pubsub"
+	"test/storage"
+)
+
+var notificationActions = map[string]string{
+	models.NotificationTypeLike:          "liked your tweet",
+	models.NotificationTypeRetweet:       "retweeted your tweet",
+	models.NotificationTypeFollow:        "followed you",
+	models.NotificationTypeFollowRequest: "requested to follow you",
+	models.NotificationTypeMention:       "mentioned you",
+	models.NotificationTypeReply:         "replied to your tweet",
+	models.NotificationTypePollClosed:    "poll you voted in has ended",
+}
+
+type notificationsService struct {
+	storage storage.IStorage
+	log     logger.ILogger
+}
+
+func NewNotificationsService(storage storage.IStorage, log logger.ILogger) notificationsService {
+	return notificationsService{storage: storage, log: log}
+}
+
+func (n notificationsService) GetList(ctx context.Context, request models.GetListRequest) (models.NotificationsResponse, error) {
+	notifications, err := n.storage.Notifications().GetList(ctx, request)
+	if err != nil {
+		n.log.Error("error in service layer while getting notifications", logger.Error(err))
+		return models.NotificationsResponse{}, err
+	}
+
+	for i := range notifications.Notifications {
+		notifications.Notifications[i].Text = notificationText(notifications.Notifications[i])
+	}
+
+	return notifications, nil
+}
+
+func (n notificationsService) MarkRead(ctx context.Context, request models.MarkNotificationsRead) error {
+	if err := n.storage.Notifications().MarkRead(ctx, request); err != nil {
+		n.log.Error("error in service layer while marking notifications read", logger.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// notify records the notification and pushes it to the recipient.
+// Notifications are a side effect of the event that caused them, so failing to
+// record one is logged but not returned.
+func notify(ctx context.Context, store storage.IStorage, publisher pubsub.Publisher, log logger.ILogger, notification models.CreateNotification) {
+	created, err := store.Notifications().Create(ctx, notification)
+	if err != nil {
+		log.Error("error while creating notification", logger.Error(err))
+		return
+	}
+
+	if !created {
+		return
+	}
+
+	unreadCount, err := store.Notifications().CountUnread(ctx, notification.UserID)
+	if err != nil {
+		log.Error("error while counting unread notifications", logger.Error(err))
+		return
+	}
+
+	publish(ctx, publisher, log, []string{notification.UserID}, models.EventTypeNotification, models.NotificationEvent{
+		Type:        notification.Type,
+		ActorUserID: notification.ActorUserID,
+		TweetID:     notification.TweetID,
+		UnreadCount: unreadCount,
+	})
+}
+
+// unnotify removes the notification of an event that was undone.
+func unnotify(ctx context.Context, store storage.IStorage, log logger.ILogger, notification models.CreateNotification) {
+	if err := store.Notifications().Delete(ctx, notification); err != nil {
+		log.Error("error while deleting notification", logger.Error(err))
+	}
+}
+
+// notificationText describes the notification as "A and 5 others liked your
+// tweet".
+func notificationText(notification models.Notification) string {
+	if len(notification.Actors) == 0 {
+		return ""
+	}
+
+	first := actorName(notification.Actors[0])
+	action := notificationActions[notification.Type]
+
+	// the poll of one author, whoever else voted
+	if notification.Type == models.NotificationTypePollClosed {
+		return fmt.Sprintf("%s's %s", first, action)
+	}
+
+	switch {
+	case notification.ActorCount <= 1:
+		return fmt.Sprintf("%s %s", first, action)
+	case notification.ActorCount == 2 && len(notification.Actors) > 1:
+		return fmt.Sprintf("%s and %s %s", first, actorName(notification.Actors[1]), action)
+	case notification.ActorCount == 2:
+		return fmt.Sprintf("%s and 1 other %s", first, action)
+	default:
+		return fmt.Sprintf("%s and %d others %s", first, notification.ActorCount-1, action)
+	}
+}
+
+func actorName(user models.UserSummary) string {
+	if user.Name != "" {
+		return user.Name
+	}
+
+	return user.Username
+}
@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"test/api/models"
+	"test/pkg/logger"
+	"test/pkg/pubsub"
+	"test/storage"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	pollsCloseInterval = time.Minute
+	pollsBatchSize     = 100
+)
+
+type pollsService struct {
+	storage   storage.IStorage
+	publisher pubsub.Publisher
+	log       logger.ILogger
+}
+
+func NewPollsService(storage storage.IStorage, publisher pubsub.Publisher, log logger.ILogger) pollsService {
+	return pollsService{storage: storage, publisher: publisher, log: log}
+}
+
+// Vote votes for an option of the poll of a tweet the user can see. Users
+// vote once and not in their own polls; the poll is returned with its
+// results.
+func (p pollsService) Vote(ctx context.Context, vote models.CreatePollVote) (models.Poll, error) {
+	tweet, err := getVisibleTweet(ctx, p.storage, vote.TweetID, vote.UserID)
+	if err != nil {
+		p.log.Error("error in service layer while getting poll tweet", logger.Error(err))
+		return models.Poll{}, err
+	}
+
+	poll, err := p.get(ctx, tweet, vote.UserID)
+	if err != nil {
+		return models.Poll{}, err
+	}
+
+	if tweet.UserID == vote.UserID {
+		return models.Poll{}, fmt.Errorf("%w: you cannot vote in your own poll", ErrForbidden)
+	}
+
+	if poll.ViewerVote != nil {
+		return models.Poll{}, fmt.Errorf("%w: you already voted in this poll", ErrInvalid)
+	}
+
+	if poll.Closed {
+		return models.Poll{}, fmt.Errorf("%w: the poll has ended", ErrInvalid)
+	}
+
+	if !hasPollOption(poll, vote.OptionID) {
+		return models.Poll{}, fmt.Errorf("%w: option is not in the poll", ErrInvalid)
+	}
+
+	voted, err := p.storage.Polls().Vote(ctx, vote)
+	if err != nil {
+		p.log.Error("error in service layer while voting in poll", logger.Error(err))
+		return models.Poll{}, err
+	}
+
+	// lost a race with another vote of the user or the poll closing
+	if !voted {
+		return models.Poll{}, fmt.Errorf("%w: you already voted in this poll or it has ended", ErrInvalid)
+	}
+
+	return p.get(ctx, tweet, vote.UserID)
+}
+
+func (p pollsService) get(ctx context.Context, tweet models.Tweet, viewerID string) (models.Poll, error) {
+	polls, err := p.storage.Polls().GetByTweetIDs(ctx, []string{tweet.ID}, viewerID)
+	if err != nil {
+		p.log.Error("error in service layer while getting poll", logger.Error(err))
+		return models.Poll{}, err
+	}
+
+	if len(polls) == 0 {
+		return models.Poll{}, fmt.Errorf("%w: tweet has no poll", ErrNotFound)
+	}
+
+	poll := polls[0]
+	hidePollResults(&poll, tweet.UserID, viewerID)
+
+	return poll, nil
+}
+
+// RunJob closes the polls past their end every pollsCloseInterval, until ctx
+// is done, and notifies their voters.
+func (p pollsService) RunJob(ctx context.Context) error {
+	ticker := time.NewTicker(pollsCloseInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := p.closeEnded(ctx); err != nil {
+			p.log.Error("error while closing polls", logger.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// closeEnded closes ended polls a batch at a time until none is left.
+func (p pollsService) closeEnded(ctx context.Context) error {
+	for ctx.Err() == nil {
+		tweetIDs, err := p.storage.Polls().Close(ctx, pollsBatchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, tweetID := range tweetIDs {
+			p.notifyVoters(ctx, tweetID)
+		}
+
+		if len(tweetIDs) < pollsBatchSize {
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// notifyVoters tells the voters of the closed poll its results are final. As
+// any notification, failing to is logged but not returned.
+func (p pollsService) notifyVoters(ctx context.Context, tweetID string) {
+	tweet, err := p.storage.Tweets().GetByID(ctx, models.PrimaryKey{ID: tweetID})
+	if err != nil {
+		p.log.Error("error while getting closed poll tweet", logger.String("tweet_id", tweetID), logger.Error(err))
+		return
+	}
+
+	voterIDs, err := p.storage.Polls().GetVoterIDs(ctx, tweetID)
+	if err != nil {
+		p.log.Error("error while getting poll voters", logger.String("tweet_id", tweetID), logger.Error(err))
+		return
+	}
+
+	for _, voterID := range voterIDs {
+		notify(ctx, p.storage, p.publisher, p.log, models.CreateNotification{
+			UserID:      voterID,
+			ActorUserID: tweet.UserID,
+			Type:        models.NotificationTypePollClosed,
+			TweetID:     &tweet.ID,
+		})
+	}
+}
+
+// checkTweetPoll checks the poll of a new tweet has 2 to 4 distinct options
+// of up to 25 characters and lasts 5 minutes to 7 days, trimming its options.
+func checkTweetPoll(tweet models.CreateTweet) error {
+	poll := tweet.Poll
+	if poll == nil {
+		return nil
+	}
+
+	if len(tweet.MediaIDs) > 0 {
+		return fmt.Errorf("%w: a tweet cannot have both media and a poll", ErrInvalid)
+	}
+
+	if len(poll.Options) < models.MinPollOptions || len(poll.Options) > models.MaxPollOptions {
+		return fmt.Errorf("%w: a poll has %d to %d options", ErrInvalid, models.MinPollOptions, models.MaxPollOptions)
+	}
+
+	seen := make(map[string]bool, len(poll.Options))
+	for i, option := range poll.Options {
+		option = strings.TrimSpace(option)
+		if option == "" {
+			return fmt.Errorf("%w: poll options cannot be empty", ErrInvalid)
+		}
+
+		if utf8.RuneCountInString(option) > models.MaxPollOptionLength {
+			return fmt.Errorf("%w: poll options are at most %d characters", ErrInvalid, models.MaxPollOptionLength)
+		}
+
+		if seen[strings.ToLower(option)] {
+			return fmt.Errorf("%w: poll options must differ", ErrInvalid)
+		}
+		seen[strings.ToLower(option)] = true
+		poll.Options[i] = option
+	}
+
+	duration := time.Duration(poll.DurationMinutes) * time.Minute
+	if duration < models.MinPollDuration || duration > models.MaxPollDuration {
+		return fmt.Errorf("%w: a poll lasts %d to %d minutes", ErrInvalid,
+			int(models.MinPollDuration.Minutes()), int(models.MaxPollDuration.Minutes()))
+	}
+
+	return nil
+}
+
+// hidePollResults leaves out the counts of an open poll the viewer neither
+// voted in nor authored, so that results do not sway votes.
+func hidePollResults(poll *models.Poll, authorID, viewerID string) {
+	if poll.Closed || poll.ViewerVote != nil || (viewerID != "" && viewerID == authorID) {
+		return
+	}
+
+	poll.TotalVotes = nil
+	for i := range poll.Options {
+		poll.Options[i].Votes = nil
+	}
+}
+
+func hasPollOption(poll models.Poll, optionID string) bool {
+	for _, option := range poll.Options {
+		if option.ID == optionID {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,317 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"test/api/models"
+	"test/pkg/logger"
+	"test/storage"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	maxReportDetailsLength  = 1000
+	maxModerationNoteLength = 1000
+	// reportClaimLease is how long a claimed report is left to its moderator
+	// before another one can take it over.
+	reportClaimLease = time.Hour
+)
+
+var reportReasons = map[string]bool{
+	models.ReportReasonSpam:          true,
+	models.ReportReasonHarassment:    true,
+	models.ReportReasonHate:          true,
+	models.ReportReasonViolence:      true,
+	models.ReportReasonSelfHarm:      true,
+	models.ReportReasonImpersonation: true,
+	models.ReportReasonOther:         true,
+}
+
+type reportsService struct {
+	storage storage.IStorage
+	log     logger.ILogger
+}
+
+func NewReportsService(storage storage.IStorage, log logger.ILogger) reportsService {
+	return reportsService{storage: storage, log: log}
+}
+
+// Create reports a tweet or user the reporter can see, or a message of one of
+// their conversations. A copy of the reported content is kept for moderators.
+func (r reportsService) Create(ctx context.Context, report models.CreateReport) (models.Report, error) {
+	if !reportReasons[report.Reason] {
+		return models.Report{}, fmt.Errorf("%w: unknown report reason", ErrInvalid)
+	}
+
+	report.Details = strings.TrimSpace(report.Details)
+	if utf8.RuneCountInString(report.Details) > maxReportDetailsLength {
+		return models.Report{}, fmt.Errorf("%w: details are at most %d characters", ErrInvalid, maxReportDetailsLength)
+	}
+
+	if err := r.setTarget(ctx, &report); err != nil {
+		r.log.Error("error in service layer while getting reported target", logger.Error(err))
+		return models.Report{}, err
+	}
+
+	if report.TargetUserID != nil && *report.TargetUserID == report.ReporterUserID {
+		return models.Report{}, fmt.Errorf("%w: you cannot report yourself", ErrInvalid)
+	}
+
+	id, err := r.storage.Reports().Create(ctx, report)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Report{}, fmt.Errorf("%w: you already reported this and it is pending review", ErrInvalid)
+		}
+		r.log.Error("error in service layer while creating report", logger.Error(err))
+		return models.Report{}, err
+	}
+
+	createdReport, err := r.storage.Reports().GetByID(ctx, models.PrimaryKey{ID: id})
+	if err != nil {
+		r.log.Error("error in service layer while getting report by id", logger.Error(err))
+		return models.Report{}, err
+	}
+
+	return createdReport, nil
+}
+
+// setTarget fills in the author and content of the reported target, which
+// must be visible to the reporter.
+func (r reportsService) setTarget(ctx context.Context, report *models.CreateReport) error {
+	switch report.TargetType {
+	case models.ReportTargetTweet:
+		tweet, err := getVisibleTweet(ctx, r.storage, report.TargetID, report.ReporterUserID)
+		if err != nil {
+			return err
+		}
+		report.TargetUserID = &tweet.UserID
+		report.TargetContent = tweet.Content
+
+	case models.ReportTargetUser:
+		if err := ensureVisible(ctx, r.storage, report.TargetID, report.ReporterUserID); err != nil {
+			return err
+		}
+
+		user, err := r.storage.User().GetByID(ctx, models.PrimaryKey{ID: report.TargetID})
+		if err != nil {
+			return err
+		}
+		report.TargetUserID = &user.ID
+		report.TargetContent = strings.TrimSpace(user.Name + "\n" + user.Bio)
+
+	case models.ReportTargetMessage:
+		message, err := r.storage.Messages().GetMessageByID(ctx, models.PrimaryKey{ID: report.TargetID})
+		if err != nil {
+			return err
+		}
+
+		// only members of the conversation know about its messages
+		if _, err = r.storage.Messages().GetConversation(ctx, message.ConversationID, report.ReporterUserID); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrNotFound
+			}
+			return err
+		}
+		report.TargetUserID = message.SenderUserID
+		report.TargetContent = message.Content
+
+	default:
+		return fmt.Errorf("%w: target_type must be %s, %s or %s", ErrInvalid,
+			models.ReportTargetTweet, models.ReportTargetUser, models.ReportTargetMessage)
+	}
+
+	return nil
+}
+
+// GetList lists the reports in the moderation queue.
+func (r reportsService) GetList(ctx context.Context, moderatorID string, request models.GetReportsRequest) (models.ReportsResponse, error) {
+	if err := ensureModerator(ctx, r.storage, moderatorID); err != nil {
+		return models.ReportsResponse{}, err
+	}
+
+	switch request.State {
+	case "", models.ReportStateOpen, models.ReportStateClaimed, models.ReportStateResolved:
+	default:
+		return models.ReportsResponse{}, fmt.Errorf("%w: state must be %s, %s or %s", ErrInvalid,
+			models.ReportStateOpen, models.ReportStateClaimed, models.ReportStateResolved)
+	}
+
+	reports, err := r.storage.Reports().GetList(ctx, request)
+	if err != nil {
+		r.log.Error("error in service layer while getting reports", logger.Error(err))
+		return models.ReportsResponse{}, err
+	}
+
+	return reports, nil
+}
+
+func (r reportsService) Get(ctx context.Context, key models.PrimaryKey, moderatorID string) (models.Report, error) {
+	if err := ensureModerator(ctx, r.storage, moderatorID); err != nil {
+		return models.Report{}, err
+	}
+
+	report, err := r.storage.Reports().GetByID(ctx, key)
+	if err != nil {
+		r.log.Error("error in service layer while getting report", logger.Error(err))
+		return models.Report{}, err
+	}
+
+	return report, nil
+}
+
+// Claim assigns the report to the moderator, who then resolves it. Claims
+// nobody acted on for reportClaimLease can be taken over.
+func (r reportsService) Claim(ctx context.Context, key models.PrimaryKey, moderatorID string) (models.Report, error) {
+	if err := ensureModerator(ctx, r.storage, moderatorID); err != nil {
+		return models.Report{}, err
+	}
+
+	if _, err := r.storage.Reports().GetByID(ctx, key); err != nil {
+		r.log.Error("error in service layer while getting report", logger.Error(err))
+		return models.Report{}, err
+	}
+
+	report, err := r.storage.Reports().Claim(ctx, key.ID, moderatorID, reportClaimLease)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Report{}, fmt.Errorf("%w: the report is resolved or claimed by another moderator", ErrInvalid)
+		}
+		r.log.Error("error in service layer while claiming report", logger.Error(err))
+		return models.Report{}, err
+	}
+
+	return report, nil
+}
+
+// ClaimNext claims the oldest report waiting for review.
+func (r reportsService) ClaimNext(ctx context.Context, moderatorID string) (models.Report, error) {
+	if err := ensureModerator(ctx, r.storage, moderatorID); err != nil {
+		return models.Report{}, err
+	}
+
+	report, err := r.storage.Reports().ClaimNext(ctx, moderatorID, reportClaimLease)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Report{}, fmt.Errorf("%w: no report is waiting for review", ErrNotFound)
+		}
+		r.log.Error("error in service layer while claiming next report", logger.Error(err))
+		return models.Report{}, err
+	}
+
+	return report, nil
+}
+
+// Resolve closes a report the moderator claimed, hiding the reported tweet,
+// suspending the author of the reported content or dismissing the report.
+// The action is recorded in the moderation audit log.
+func (r reportsService) Resolve(ctx context.Context, request models.ResolveReport) (models.Report, error) {
+	if err := ensureModerator(ctx, r.storage, request.ModeratorUserID); err != nil {
+		return models.Report{}, err
+	}
+
+	request.Note = strings.TrimSpace(request.Note)
+	if utf8.RuneCountInString(request.Note) > maxModerationNoteLength {
+		return models.Report{}, fmt.Errorf("%w: note is at most %d characters", ErrInvalid, maxModerationNoteLength)
+	}
+
+	report, err := r.storage.Reports().GetByID(ctx, models.PrimaryKey{ID: request.ReportID})
+	if err != nil {
+		r.log.Error("error in service layer while getting report", logger.Error(err))
+		return models.Report{}, err
+	}
+
+	action := models.ModerationAction{
+		ModeratorUserID: request.ModeratorUserID,
+		ReportID:        &report.ID,
+		Action:          request.Action,
+		TargetType:      report.TargetType,
+		TargetID:        report.TargetID,
+		Note:            request.Note,
+	}
+
+	switch request.Action {
+	case models.ModerationActionDismiss:
+	case models.ModerationActionHideTweet:
+		if report.TargetType != models.ReportTargetTweet {
+			return models.Report{}, fmt.Errorf("%w: only reported tweets can be hidden", ErrInvalid)
+		}
+	case models.ModerationActionSuspendUser:
+		if report.TargetUserID == nil {
+			return models.Report{}, fmt.Errorf("%w: the reported content has no author left to suspend", ErrInvalid)
+		}
+
+		role, err := r.storage.User().GetRole(ctx, *report.TargetUserID)
+		if err != nil {
+			r.log.Error("error in service layer while getting user role", logger.Error(err))
+			return models.Report{}, err
+		}
+
+		if isModeratorRole(role) {
+			return models.Report{}, fmt.Errorf("%w: moderators cannot be suspended", ErrForbidden)
+		}
+		action.TargetType = models.ReportTargetUser
+		action.TargetID = *report.TargetUserID
+	default:
+		return models.Report{}, fmt.Errorf("%w: action must be %s, %s or %s", ErrInvalid,
+			models.ModerationActionHideTweet, models.ModerationActionSuspendUser, models.ModerationActionDismiss)
+	}
+
+	if err = r.storage.Reports().Resolve(ctx, action); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Report{}, fmt.Errorf("%w: claim the report before resolving it", ErrInvalid)
+		}
+		r.log.Error("error in service layer while resolving report", logger.Error(err))
+		return models.Report{}, err
+	}
+
+	resolvedReport, err := r.storage.Reports().GetByID(ctx, models.PrimaryKey{ID: report.ID})
+	if err != nil {
+		r.log.Error("error in service layer while getting resolved report by id", logger.Error(err))
+		return models.Report{}, err
+	}
+
+	return resolvedReport, nil
+}
+
+// GetActions pages through the moderation audit log.
+func (r reportsService) GetActions(ctx context.Context, moderatorID string, request models.GetModerationActionsRequest) (models.ModerationActionsResponse, error) {
+	if err := ensureModerator(ctx, r.storage, moderatorID); err != nil {
+		return models.ModerationActionsResponse{}, err
+	}
+
+	actions, err := r.storage.Reports().GetActions(ctx, request)
+	if err != nil {
+		r.log.Error("error in service layer while getting moderation actions", logger.Error(err))
+		return models.ModerationActionsResponse{}, err
+	}
+
+	return actions, nil
+}
+
+// ensureModerator returns ErrForbidden unless userID moderates. The role is
+// read from the database rather than the access token, so that revoking it
+// takes effect right away.
+func ensureModerator(ctx context.Context, store storage.IStorage, userID string) error {
+	role, err := store.User().GetRole(ctx, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("%w: only moderators can do this", ErrForbidden)
+		}
+		return err
+	}
+
+	if !isModeratorRole(role) {
+		return fmt.Errorf("%w: only moderators can do this", ErrForbidden)
+	}
+
+	return nil
+}
+
+func isModeratorRole(role string) bool {
+	return role == models.UserRoleModerator || role == models.UserRoleAdmin
+}
@@ -2,33 +2,124 @@ package service
 
 import (
 	"context"
+	"fmt"
 	"test/api/models"
 	"test/pkg/logger"
+	"test/pkg/pubsub"
 	"test/storage"
 )
 
 type retweetsService struct {
-	storage storage.IStorage
-	log     logger.ILogger
+	storage   storage.IStorage
+	publisher pubsub.Publisher
+	log       logger.ILogger
 }
 
-func NewretweetsSerice(storage storage.IStorage, log logger.ILogger) retweetsService {
-	return retweetsService{storage: storage, log: log}
+func NewretweetsSerice(storage storage.IStorage, publisher pubsub.Publisher, log logger.ILogger) retweetsService {
+	return retweetsService{storage: storage, publisher: publisher, log: log}
 }
 
 func (r retweetsService) Create(ctx context.Context, retweet models.CreateRetweet) (string, error) {
 	r.log.Info("retweetsService create service layer", logger.Any("retweet", retweet))
 
+	tweet, err := getVisibleTweet(ctx, r.storage, retweet.OriginalTweetID, retweet.UserID)
+	if err != nil {
+		r.log.Error("error in service layer while getting retweeted tweet", logger.Error(err))
+		return "", err
+	}
+
 	id, err := r.storage.Retweets().Create(ctx, retweet)
 	if err != nil {
 		r.log.Error("error in service layer while creating retweet", logger.Error(err))
 		return "", err
 	}
 
+	notify(ctx, r.storage, r.publisher, r.log, models.CreateNotification{
+		UserID:      tweet.UserID,
+		ActorUserID: retweet.UserID,
+		Type:        models.NotificationTypeRetweet,
+		TweetID:     &retweet.OriginalTweetID,
+	})
+	publishCounters(ctx, r.storage, r.publisher, r.log, tweet, retweet.UserID)
+
 	return id, nil
 }
 
-func (r retweetsService) Delete(ctx context.Context, key models.PrimaryKey) error {
-	err := r.storage.Retweets().Delete(ctx, key)
-	return err
-}
\ No newline at end of file
+// Delete removes a retweet of the user, as Unretweet does.
+func (r retweetsService) Delete(ctx context.Context, key models.PrimaryKey, userID string) error {
+	retweet, err := r.storage.Retweets().GetByID(ctx, key)
+	if err != nil {
+		r.log.Error("error in service layer while getting retweet by id", logger.Error(err))
+		return err
+	}
+
+	if retweet.UserID != userID {
+		return fmt.Errorf("%w: you can only remove your own retweets", ErrForbidden)
+	}
+
+	return r.Unretweet(ctx, models.CreateRetweet{OriginalTweetID: retweet.OriginalTweetID, UserID: userID})
+}
+
+func (r retweetsService) Retweet(ctx context.Context, retweet models.CreateRetweet) (models.Retweet, error) {
+	tweet, err := getVisibleTweet(ctx, r.storage, retweet.OriginalTweetID, retweet.UserID)
+	if err != nil {
+		r.log.Error("error in service layer while getting retweeted tweet", logger.Error(err))
+		return models.Retweet{}, err
+	}
+
+	id, err := r.storage.Retweets().Upsert(ctx, retweet)
+	if err != nil {
+		r.log.Error("error in service layer while retweeting tweet", logger.Error(err))
+		return models.Retweet{}, err
+	}
+
+	notify(ctx, r.storage, r.publisher, r.log, models.CreateNotification{
+		UserID:      tweet.UserID,
+		ActorUserID: retweet.UserID,
+		Type:        models.NotificationTypeRetweet,
+		TweetID:     &retweet.OriginalTweetID,
+	})
+	publishCounters(ctx, r.storage, r.publisher, r.log, tweet, retweet.UserID)
+
+	createdRetweet, err := r.storage.Retweets().GetByID(ctx, models.PrimaryKey{ID: id})
+	if err != nil {
+		r.log.Error("error in service layer while getting retweet by id", logger.Error(err))
+		return models.Retweet{}, err
+	}
+
+	return createdRetweet, nil
+}
+
+func (r retweetsService) Unretweet(ctx context.Context, retweet models.CreateRetweet) error {
+	if err := r.storage.Retweets().DeleteByTweetAndUser(ctx, retweet); err != nil {
+		r.log.Error("error in service layer while unretweeting tweet", logger.Error(err))
+		return err
+	}
+
+	if tweet, err := r.storage.Tweets().GetByID(ctx, models.PrimaryKey{ID: retweet.OriginalTweetID}); err == nil {
+		unnotify(ctx, r.storage, r.log, models.CreateNotification{
+			UserID:      tweet.UserID,
+			ActorUserID: retweet.UserID,
+			Type:        models.NotificationTypeRetweet,
+			TweetID:     &retweet.OriginalTweetID,
+		})
+		publishCounters(ctx, r.storage, r.publisher, r.log, tweet, retweet.UserID)
+	}
+
+	return nil
+}
+
+func (r retweetsService) GetRetweetingUsers(ctx context.Context, request models.GetListRequest) (models.UserSummariesResponse, error) {
+	if _, err := getVisibleTweet(ctx, r.storage, request.TweetID, request.ViewerID); err != nil {
+		r.log.Error("error in service layer while getting retweeted tweet", logger.Error(err))
+		return models.UserSummariesResponse{}, err
+	}
+
+	users, err := r.storage.Retweets().GetRetweetingUsers(ctx, request)
+	if err != nil {
+		r.log.Error("error in service layer while getting retweeting users", logger.Error(err))
+		return models.UserSummariesResponse{}, err
+	}
+
+	return users, nil
+}
@@ -1,7 +1,11 @@
 package service
 
 import (
+	"test/pkg/blob"
+	"test/pkg/cache"
 	"test/pkg/logger"
+	"test/pkg/pubsub"
+	"test/pkg/trends"
 	"test/storage"
 )
 
@@ -12,26 +16,62 @@ type IServiceManager interface {
 	Likes() likesService
 	Retweets() retweetsService
 	AuthService() authService
+	Blocks() blocksService
+	Mutes() mutesService
+	Notifications() notificationsService
+	Messages() messagesService
+	Bookmarks() bookmarksService
+	Lists() listsService
+	Trends() trendsService
+	Suggestions() suggestionsService
+	Media() mediaService
+	Polls() pollsService
+	Drafts() draftsService
+	Reports() reportsService
 }
 
 type Service struct {
-	userService      userService
-	tweetsService    tweetService
-	followersService followersService
-	likesService     likesService
-    retweetsService   retweetsService
-	authService authService
+	userService          userService
+	tweetsService        tweetService
+	followersService     followersService
+	likesService         likesService
+	retweetsService      retweetsService
+	authService          authService
+	blocksService        blocksService
+	mutesService         mutesService
+	notificationsService notificationsService
+	messagesService      messagesService
+	bookmarksService     bookmarksService
+	listsService         listsService
+	trendsService        trendsService
+	suggestionsService   suggestionsService
+	mediaService         mediaService
+	pollsService         pollsService
+	draftsService        draftsService
+	reportsService       reportsService
 }
 
-func New(storage storage.IStorage, log logger.ILogger) Service {
+func New(storage storage.IStorage, log logger.ILogger, publisher pubsub.Publisher, cache cache.Cache, tracker *trends.Tracker, blobs blob.Store, edits TweetEditPolicy) Service {
 	services := Service{}
-	services.tweetsService = NewTweetService(storage, log)
-	services.followersService = NewfollowersService(storage, log)
-	services.likesService = NewlikesService(storage, log)
-    services.retweetsService=NewretweetsSerice(storage,log)
+	services.tweetsService = NewTweetService(storage, publisher, tracker, blobs, edits, log)
+	services.followersService = NewfollowersService(storage, publisher, log)
+	services.likesService = NewlikesService(storage, publisher, log)
+	services.retweetsService = NewretweetsSerice(storage, publisher, log)
 
-	services.userService = NewuserService(storage, log)
+	services.userService = NewuserService(storage, cache, log)
 	services.authService = NewAuthService(storage, log)
+	services.blocksService = NewBlocksService(storage, log)
+	services.mutesService = NewMutesService(storage, log)
+	services.notificationsService = NewNotificationsService(storage, log)
+	services.messagesService = NewMessagesService(storage, publisher, log)
+	services.bookmarksService = NewBookmarksService(storage, log)
+	services.listsService = NewListsService(storage, log)
+	services.trendsService = NewTrendsService(tracker, log)
+	services.suggestionsService = NewSuggestionsService(storage, log)
+	services.mediaService = NewMediaService(storage, blobs, log)
+	services.pollsService = NewPollsService(storage, publisher, log)
+	services.draftsService = NewDraftsService(storage, services.tweetsService, log)
+	services.reportsService = NewReportsService(storage, log)
 	return services
 }
 
@@ -55,6 +95,54 @@ func (s Service) AuthService() authService {
 	return s.authService
 }
 
-func (s Service) Retweets() retweetsService  {
+func (s Service) Retweets() retweetsService {
 	return s.retweetsService
-}
\ No newline at end of file
+}
+
+func (s Service) Blocks() blocksService {
+	return s.blocksService
+}
+
+func (s Service) Mutes() mutesService {
+	return s.mutesService
+}
+
+func (s Service) Notifications() notificationsService {
+	return s.notificationsService
+}
+
+func (s Service) Messages() messagesService {
+	return s.messagesService
+}
+
+func (s Service) Bookmarks() bookmarksService {
+	return s.bookmarksService
+}
+
+func (s Service) Lists() listsService {
+	return s.listsService
+}
+
+func (s Service) Trends() trendsService {
+	return s.trendsService
+}
+
+func (s Service) Suggestions() suggestionsService {
+	return s.suggestionsService
+}
+
+func (s Service) Media() mediaService {
+	return s.mediaService
+}
+
+func (s Service) Polls() pollsService {
+	return s.pollsService
+}
+
+func (s Service) Drafts() draftsService {
+	return s.draftsService
+}
+
+func (s Service) Reports() reportsService {
+	return s.reportsService
+}
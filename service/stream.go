@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+	"test/api/models"
+	"test/pkg/logger"
+	"test/pkg/pubsub"
+	"test/storage"
+)
+
+// publish pushes the event to the connected users. Like notifications, pushes
+// are a side effect, so failing to publish is logged but not returned.
+func publish(ctx context.Context, publisher pubsub.Publisher, log logger.ILogger, userIDs []string, eventType string, data interface{}) {
+	if err := publisher.Publish(ctx, userIDs, eventType, data); err != nil {
+		log.Error("error while publishing event", logger.String("type", eventType), logger.Error(err))
+	}
+}
+
+// publishTweet pushes a new tweet to the home timelines of its audience.
+func publishTweet(ctx context.Context, store storage.IStorage, publisher pubsub.Publisher, log logger.ILogger, tweet models.Tweet) {
+	userIDs, err := store.Tweets().GetAudience(ctx, tweet.ID)
+	if err != nil {
+		log.Error("error while getting tweet audience", logger.Error(err))
+		return
+	}
+
+	publish(ctx, publisher, log, userIDs, models.EventTypeTweet, tweet)
+}
+
+// publishCounters pushes the current interaction counts of the tweet to its
+// author and the given users.
+func publishCounters(ctx context.Context, store storage.IStorage, publisher pubsub.Publisher, log logger.ILogger, tweet models.Tweet, userIDs ...string) {
+	counters, err := store.Tweets().GetCounters(ctx, tweet.ID)
+	if err != nil {
+		log.Error("error while getting tweet counters", logger.Error(err))
+		return
+	}
+
+	publish(ctx, publisher, log, append(userIDs, tweet.UserID), models.EventTypeCounters, counters)
+}
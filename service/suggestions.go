@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"test/api/models"
+	"test/pkg/logger"
+	"test/storage"
+	"time"
+)
+
+const (
+	suggestionsRefreshInterval = 5 * time.Minute
+	suggestionsMaxAge          = 6 * time.Hour
+	suggestionsBatchSize       = 100
+)
+
+type suggestionsService struct {
+	storage storage.IStorage
+	log     logger.ILogger
+}
+
+func NewSuggestionsService(storage storage.IStorage, log logger.ILogger) suggestionsService {
+	return suggestionsService{storage: storage, log: log}
+}
+
+// GetList lists the accounts suggested for the user to follow, each with the
+// reason it is suggested. Suggestions are computed by RunJob; the ones of a
+// user never computed yet are computed on the spot.
+func (s suggestionsService) GetList(ctx context.Context, request models.GetListRequest) (models.UserSuggestionsResponse, error) {
+	computed, err := s.storage.Suggestions().IsComputed(ctx, request.UserID)
+	if err != nil {
+		s.log.Error("error in service layer while checking user suggestions", logger.Error(err))
+		return models.UserSuggestionsResponse{}, err
+	}
+
+	if !computed {
+		if err = s.storage.Suggestions().Refresh(ctx, request.UserID); err != nil {
+			s.log.Error("error in service layer while computing user suggestions", logger.Error(err))
+			return models.UserSuggestionsResponse{}, err
+		}
+	}
+
+	suggestions, err := s.storage.Suggestions().GetList(ctx, request)
+	if err != nil {
+		s.log.Error("error in service layer while getting user suggestions", logger.Error(err))
+		return models.UserSuggestionsResponse{}, err
+	}
+
+	for i := range suggestions.Suggestions {
+		suggestions.Suggestions[i].Reason = suggestionReason(suggestions.Suggestions[i])
+	}
+
+	return suggestions, nil
+}
+
+// RunJob recomputes the suggestions of the users whose suggestions are older
+// than suggestionsMaxAge, a batch every suggestionsRefreshInterval, until ctx
+// is done.
+func (s suggestionsService) RunJob(ctx context.Context) error {
+	ticker := time.NewTicker(suggestionsRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.refreshStale(ctx); err != nil {
+			s.log.Error("error while refreshing user suggestions", logger.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s suggestionsService) refreshStale(ctx context.Context) error {
+	userIDs, err := s.storage.Suggestions().GetStaleUsers(ctx, time.Now().Add(-suggestionsMaxAge), suggestionsBatchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, userID := range userIDs {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		// one failing user should not hold back the rest of the batch
+		if err = s.storage.Suggestions().Refresh(ctx, userID); err != nil {
+			s.log.Error("error while refreshing suggestions of user", logger.String("user_id", userID), logger.Error(err))
+		}
+	}
+
+	return nil
+}
+
+// suggestionReason explains the suggestion with the strongest signal it has.
+func suggestionReason(suggestion models.UserSuggestion) string {
+	if len(suggestion.FollowedBy) > 0 {
+		name := suggestion.FollowedBy[0].Name
+		if name == "" {
+			name = suggestion.FollowedBy[0].Username
+		}
+
+		switch others := suggestion.FollowedByCount - 1; {
+		case others <= 0:
+			return fmt.Sprintf("Followed by %s", name)
+		case others == 1:
+			return fmt.Sprintf("Followed by %s and 1 other", name)
+		default:
+			return fmt.Sprintf("Followed by %s and %d others", name, others)
+		}
+	}
+
+	if suggestion.FollowsYou {
+		return "Follows you"
+	}
+
+	if len(suggestion.SharedHashtags) > 0 {
+		return fmt.Sprintf("Also tweets about #%s", suggestion.SharedHashtags[0])
+	}
+
+	return "Popular on the platform"
+}
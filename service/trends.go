@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"test/api/models"
+	"test/pkg/logger"
+	"test/pkg/trends"
+	"test/storage"
+	"time"
+)
+
+type trendsService struct {
+	tracker *trends.Tracker
+	log     logger.ILogger
+}
+
+func NewTrendsService(tracker *trends.Tracker, log logger.ILogger) trendsService {
+	return trendsService{tracker: tracker, log: log}
+}
+
+// GetList returns the hashtags and phrases trending at the last refresh.
+func (t trendsService) GetList(ctx context.Context) (models.TrendsResponse, error) {
+	current, updatedAt := t.tracker.Trends()
+
+	response := models.TrendsResponse{
+		Trends:    make([]models.Trend, 0, len(current)),
+		UpdatedAt: updatedAt,
+	}
+	for _, trend := range current {
+		trendType := models.TrendTypePhrase
+		if trend.Hashtag {
+			trendType = models.TrendTypeHashtag
+		}
+
+		response.Trends = append(response.Trends, models.Trend{
+			Term:       trend.Term,
+			Type:       trendType,
+			TweetCount: trend.Count,
+		})
+	}
+
+	return response, nil
+}
+
+// recordTrends counts the terms of a new tweet towards the trends. Tweets of
+// protected accounts are not counted, failures are only logged.
+func recordTrends(ctx context.Context, store storage.IStorage, tracker *trends.Tracker, log logger.ILogger, tweet models.Tweet) {
+	author, err := store.User().GetByID(ctx, models.PrimaryKey{ID: tweet.UserID})
+	if err != nil {
+		log.Error("error while getting tweet author for trends", logger.Error(err))
+		return
+	}
+
+	if author.Protected {
+		return
+	}
+
+	if err = tracker.Record(ctx, tweet.UserID, tweet.Content, time.Now()); err != nil {
+		log.Error("error while recording trends", logger.Error(err))
+	}
+}
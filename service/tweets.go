@@ -2,23 +2,102 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"test/api/models"
+	"test/pkg/blob"
 	"test/pkg/logger"
+	"test/pkg/pubsub"
+	"test/pkg/search"
+	"test/pkg/text"
+	"test/pkg/trends"
 	"test/storage"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jackc/pgx/v5"
 )
 
+const maxHashtagLength = 100
+
+// TweetEditPolicy limits how long after posting and how many times a tweet can
+// be edited.
+type TweetEditPolicy struct {
+	Window   time.Duration
+	MaxEdits int
+}
+
 type tweetService struct {
-	storage storage.IStorage
-	log     logger.ILogger
+	storage   storage.IStorage
+	publisher pubsub.Publisher
+	trends    *trends.Tracker
+	blobs     blob.Store
+	edits     TweetEditPolicy
+	log       logger.ILogger
 }
 
-func NewTweetService(storage storage.IStorage, log logger.ILogger) tweetService {
-	return tweetService{storage: storage, log: log}
+func NewTweetService(storage storage.IStorage, publisher pubsub.Publisher, trends *trends.Tracker, blobs blob.Store, edits TweetEditPolicy, log logger.ILogger) tweetService {
+	return tweetService{storage: storage, publisher: publisher, trends: trends, blobs: blobs, edits: edits, log: log}
 }
 
 func (t tweetService) Create(ctx context.Context, tweet models.CreateTweet) (models.Tweet, error) {
 	t.log.Info("tweet create service layer", logger.Any("tweet", tweet))
 
+	// drafts are published by the scheduler, after the author may have been
+	// suspended
+	if err := ensureActive(ctx, t.storage, tweet.UserID); err != nil {
+		t.log.Error("error in service layer while checking author suspension", logger.Error(err))
+		return models.Tweet{}, err
+	}
+
+	var replied models.Tweet
+	if tweet.ReplyToTweetID != nil {
+		parent, err := t.storage.Tweets().GetByID(ctx, models.PrimaryKey{ID: *tweet.ReplyToTweetID})
+		if err != nil {
+			t.log.Error("error in service layer while getting replied tweet", logger.Error(err))
+			return models.Tweet{}, err
+		}
+
+		if err = ensureNotBlocked(ctx, t.storage, parent.UserID, tweet.UserID, "reply to"); err != nil {
+			t.log.Error("error in service layer while checking block", logger.Error(err))
+			return models.Tweet{}, err
+		}
+
+		if err = ensureTweetVisible(ctx, t.storage, parent, tweet.UserID); err != nil {
+			t.log.Error("error in service layer while checking replied tweet visibility", logger.Error(err))
+			return models.Tweet{}, err
+		}
+
+		if err = ensureCanReply(ctx, t.storage, parent, tweet.UserID); err != nil {
+			t.log.Error("error in service layer while checking reply audience", logger.Error(err))
+			return models.Tweet{}, err
+		}
+		replied = parent
+	}
+
+	mentionedIDs, err := mentionedUserIDs(ctx, t.storage, tweet.Content, tweet.UserID)
+	if err != nil {
+		t.log.Error("error in service layer while getting mentioned users", logger.Error(err))
+		return models.Tweet{}, err
+	}
+	tweet.MentionedUserIDs = mentionedIDs
+	tweet.Hashtags = hashtags(tweet.Content)
+
+	if err = checkReplyAudience(&tweet.ReplyAudience); err != nil {
+		t.log.Error("error in service layer while checking reply audience", logger.Error(err))
+		return models.Tweet{}, err
+	}
+
+	if err = checkTweetPoll(tweet); err != nil {
+		t.log.Error("error in service layer while checking tweet poll", logger.Error(err))
+		return models.Tweet{}, err
+	}
+
+	if err = checkTweetMedia(ctx, t.storage, tweet); err != nil {
+		t.log.Error("error in service layer while checking tweet media", logger.Error(err))
+		return models.Tweet{}, err
+	}
+
 	id, err := t.storage.Tweets().Create(ctx, tweet)
 	if err != nil {
 		t.log.Error("error in service layer while creating tweet", logger.Error(err))
@@ -31,22 +110,91 @@ func (t tweetService) Create(ctx context.Context, tweet models.CreateTweet) (mod
 		return models.Tweet{}, err
 	}
 
+	// hydrated for no viewer in particular, as it is streamed to followers
+	if err = hydrateTweet(ctx, t.storage, "", &createdTweet); err != nil {
+		t.log.Error("error in service layer while hydrating tweet", logger.Error(err))
+		return models.Tweet{}, err
+	}
+
+	publishTweet(ctx, t.storage, t.publisher, t.log, createdTweet)
+	recordTrends(ctx, t.storage, t.trends, t.log, createdTweet)
+
+	if tweet.ReplyToTweetID != nil {
+		notify(ctx, t.storage, t.publisher, t.log, models.CreateNotification{
+			UserID:      replied.UserID,
+			ActorUserID: tweet.UserID,
+			Type:        models.NotificationTypeReply,
+			TweetID:     &id,
+		})
+		publishCounters(ctx, t.storage, t.publisher, t.log, replied)
+	}
+
+	for _, userID := range mentionedIDs {
+		// the replied user is already notified about the reply
+		if userID == replied.UserID {
+			continue
+		}
+
+		notify(ctx, t.storage, t.publisher, t.log, models.CreateNotification{
+			UserID:      userID,
+			ActorUserID: tweet.UserID,
+			Type:        models.NotificationTypeMention,
+			TweetID:     &id,
+		})
+	}
+
 	return createdTweet, nil
 }
 
-func (t tweetService) Get(ctx context.Context, id string) (models.Tweet, error) {
-	tweet, err := t.storage.Tweets().GetByID(ctx, models.PrimaryKey{ID: id})
+func (t tweetService) Get(ctx context.Context, id, viewerID string) (models.Tweet, error) {
+	tweet, err := getVisibleTweet(ctx, t.storage, id, viewerID)
 	if err != nil {
 		t.log.Error("error in service layer while getting tweet by id", logger.Error(err))
 		return models.Tweet{}, err
 	}
 
+	if err = hydrateTweet(ctx, t.storage, viewerID, &tweet); err != nil {
+		t.log.Error("error in service layer while hydrating tweet", logger.Error(err))
+		return models.Tweet{}, err
+	}
+
 	return tweet, nil
 }
 
+// Update edits the content of the user's tweet within the edit window and up
+// to the maximum number of edits, keeping every revision.
 func (t tweetService) Update(ctx context.Context, tweet models.UpdateTweet) (models.Tweet, error) {
-	id, err := t.storage.Tweets().Update(ctx, tweet)
+	if tweet.Content == nil {
+		return models.Tweet{}, fmt.Errorf("%w: content is required", ErrInvalid)
+	}
+
+	current, err := t.storage.Tweets().GetByID(ctx, models.PrimaryKey{ID: tweet.ID})
 	if err != nil {
+		t.log.Error("error in service layer while getting tweet by id", logger.Error(err))
+		return models.Tweet{}, err
+	}
+
+	switch {
+	case current.UserID != tweet.UserID:
+		return models.Tweet{}, fmt.Errorf("%w: you can only edit your own tweets", ErrForbidden)
+	case current.Content == *tweet.Content:
+		return models.Tweet{}, fmt.Errorf("%w: content is unchanged", ErrInvalid)
+	case current.EditCount >= t.edits.MaxEdits:
+		return models.Tweet{}, fmt.Errorf("%w: a tweet can be edited at most %d times", ErrForbidden, t.edits.MaxEdits)
+	}
+
+	tweet.MentionedUserIDs, err = mentionedUserIDs(ctx, t.storage, *tweet.Content, tweet.UserID)
+	if err != nil {
+		t.log.Error("error in service layer while getting mentioned users", logger.Error(err))
+		return models.Tweet{}, err
+	}
+	tweet.Hashtags = hashtags(*tweet.Content)
+
+	id, err := t.storage.Tweets().Update(ctx, tweet, t.edits.Window, t.edits.MaxEdits)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Tweet{}, fmt.Errorf("%w: a tweet can only be edited within %s of posting", ErrForbidden, t.edits.Window)
+		}
 		t.log.Error("error in service layer while updating tweet", logger.Error(err))
 		return models.Tweet{}, err
 	}
@@ -57,21 +205,189 @@ func (t tweetService) Update(ctx context.Context, tweet models.UpdateTweet) (mod
 		return models.Tweet{}, err
 	}
 
+	// the edit is streamed to followers hydrated for no viewer in particular,
+	// and returned to the author hydrated for them
+	streamedTweet := updatedTweet
+	if err = hydrateTweet(ctx, t.storage, "", &streamedTweet); err != nil {
+		t.log.Error("error in service layer while hydrating tweet", logger.Error(err))
+		return models.Tweet{}, err
+	}
+	publishTweet(ctx, t.storage, t.publisher, t.log, streamedTweet)
+
+	if err = hydrateTweet(ctx, t.storage, tweet.UserID, &updatedTweet); err != nil {
+		t.log.Error("error in service layer while hydrating tweet", logger.Error(err))
+		return models.Tweet{}, err
+	}
+
 	return updatedTweet, nil
 }
 
-func (t tweetService) Delete(ctx context.Context, key models.PrimaryKey) error {
-	err := t.storage.Tweets().Delete(ctx, key)
-	return err
+// UpdateReplyAudience changes who can reply to the user's tweet. Replies
+// already posted are kept.
+func (t tweetService) UpdateReplyAudience(ctx context.Context, request models.UpdateReplyAudience) (models.Tweet, error) {
+	if err := checkReplyAudience(&request.ReplyAudience); err != nil {
+		return models.Tweet{}, err
+	}
+
+	current, err := t.storage.Tweets().GetByID(ctx, models.PrimaryKey{ID: request.TweetID})
+	if err != nil {
+		t.log.Error("error in service layer while getting tweet by id", logger.Error(err))
+		return models.Tweet{}, err
+	}
+
+	if current.UserID != request.UserID {
+		return models.Tweet{}, fmt.Errorf("%w: you can only change who can reply to your own tweets", ErrForbidden)
+	}
+
+	if err = t.storage.Tweets().UpdateReplyAudience(ctx, request); err != nil {
+		t.log.Error("error in service layer while updating tweet reply audience", logger.Error(err))
+		return models.Tweet{}, err
+	}
+
+	updatedTweet, err := t.storage.Tweets().GetByID(ctx, models.PrimaryKey{ID: request.TweetID})
+	if err != nil {
+		t.log.Error("error in service layer while getting updated tweet by id", logger.Error(err))
+		return models.Tweet{}, err
+	}
+
+	if err = hydrateTweet(ctx, t.storage, request.UserID, &updatedTweet); err != nil {
+		t.log.Error("error in service layer while hydrating tweet", logger.Error(err))
+		return models.Tweet{}, err
+	}
+
+	return updatedTweet, nil
+}
+
+// GetHistory lists the revisions of a tweet the viewer can see, the original
+// first and the current one last.
+func (t tweetService) GetHistory(ctx context.Context, id, viewerID string) (models.TweetHistoryResponse, error) {
+	tweet, err := getVisibleTweet(ctx, t.storage, id, viewerID)
+	if err != nil {
+		t.log.Error("error in service layer while getting tweet by id", logger.Error(err))
+		return models.TweetHistoryResponse{}, err
+	}
+
+	revisions, err := t.storage.Tweets().GetRevisions(ctx, tweet.ID)
+	if err != nil {
+		t.log.Error("error in service layer while getting tweet revisions", logger.Error(err))
+		return models.TweetHistoryResponse{}, err
+	}
+
+	// revisions are only recorded once a tweet is edited
+	if len(revisions) == 0 {
+		revisions = []models.TweetRevision{{Content: tweet.Content, CreatedAt: tweet.CreatedAt}}
+	}
+
+	return models.TweetHistoryResponse{
+		Revisions: revisions,
+		Count:     len(revisions),
+	}, nil
 }
+
+// Delete deletes the user's tweet along with the files of its media.
+func (t tweetService) Delete(ctx context.Context, key models.PrimaryKey, userID string) error {
+	tweet, err := t.storage.Tweets().GetByID(ctx, key)
+	if err != nil {
+		t.log.Error("error in service layer while getting tweet by id", logger.Error(err))
+		return err
+	}
+
+	if tweet.UserID != userID {
+		return fmt.Errorf("%w: you can only delete your own tweets", ErrForbidden)
+	}
+
+	media, err := t.storage.Media().GetByTweetIDs(ctx, []string{key.ID})
+	if err != nil {
+		t.log.Error("error in service layer while getting tweet media", logger.Error(err))
+		return err
+	}
+
+	if err = t.storage.Tweets().Delete(ctx, key); err != nil {
+		return err
+	}
+
+	for _, item := range media {
+		deleteMediaFiles(ctx, t.blobs, t.log, item)
+	}
+
+	return nil
+}
+
+// GetList lists the tweets, matching the search query when one is given.
 func (t tweetService) GetList(ctx context.Context, request models.GetListRequest) (models.TweetsResponse, error) {
 	t.log.Info("tweet get list service layer", logger.Any("request", request))
 
-	tweets, err := t.storage.Tweets().GetList(ctx, request)
+	query, err := search.Parse(request.Search)
+	if err != nil {
+		return models.TweetsResponse{}, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+
+	var tweets models.TweetsResponse
+	if query.Empty() {
+		tweets, err = t.storage.Tweets().GetList(ctx, request)
+	} else {
+		tweets, err = t.storage.Tweets().Search(ctx, query, request)
+	}
 	if err != nil {
 		t.log.Error("error in service layer while getting list of tweets", logger.Error(err))
 		return models.TweetsResponse{}, err
 	}
 
+	if err = hydrateTweets(ctx, t.storage, request.ViewerID, tweets.Tweets); err != nil {
+		t.log.Error("error in service layer while hydrating tweets", logger.Error(err))
+		return models.TweetsResponse{}, err
+	}
+
 	return tweets, nil
 }
+
+func (t tweetService) GetMentions(ctx context.Context, request models.GetListRequest) (models.TweetsResponse, error) {
+	tweets, err := t.storage.Tweets().GetMentions(ctx, request)
+	if err != nil {
+		t.log.Error("error in service layer while getting mentions", logger.Error(err))
+		return models.TweetsResponse{}, err
+	}
+
+	if err = hydrateTweets(ctx, t.storage, request.ViewerID, tweets.Tweets); err != nil {
+		t.log.Error("error in service layer while hydrating tweets", logger.Error(err))
+		return models.TweetsResponse{}, err
+	}
+
+	return tweets, nil
+}
+
+// mentionedUserIDs returns the users the content mentions, refusing to
+// mention users who blocked the author or whom the author blocked.
+func mentionedUserIDs(ctx context.Context, store storage.IStorage, content, authorID string) ([]string, error) {
+	usernames := text.Mentions(content)
+	if len(usernames) == 0 {
+		return nil, nil
+	}
+
+	mentioned, err := store.User().GetByUsernames(ctx, usernames)
+	if err != nil {
+		return nil, err
+	}
+
+	userIDs := make([]string, 0, len(mentioned))
+	for _, user := range mentioned {
+		if err = ensureNotBlocked(ctx, store, user.ID, authorID, "mention"); err != nil {
+			return nil, err
+		}
+		userIDs = append(userIDs, user.ID)
+	}
+
+	return userIDs, nil
+}
+
+// hashtags returns the hashtags of content short enough to be recorded.
+func hashtags(content string) []string {
+	result := []string{}
+	for _, hashtag := range text.Hashtags(content) {
+		if utf8.RuneCountInString(hashtag) <= maxHashtagLength {
+			result = append(result, hashtag)
+		}
+	}
+
+	return result
+}
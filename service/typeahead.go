@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"test/api/models"
+	"test/pkg/logger"
+	"time"
+)
+
+const (
+	maxTypeaheadQueryLength = 50
+	typeaheadCandidates     = 50
+	typeaheadCacheTTL       = time.Minute * 5
+)
+
+// Typeahead suggests users for a partially typed username or name. The best
+// matches for a prefix are the same for everyone and are cached, so a prefix
+// typed by many users costs a single ranking query per viewer. New accounts
+// may take typeaheadCacheTTL to show up for a cached prefix.
+func (u userService) Typeahead(ctx context.Context, request models.TypeaheadRequest) (models.TypeaheadResponse, error) {
+	prefix := []rune(strings.ToLower(strings.TrimPrefix(strings.TrimSpace(request.Query), "@")))
+	if len(prefix) == 0 {
+		return models.TypeaheadResponse{}, fmt.Errorf("%w: q is required", ErrInvalid)
+	}
+
+	if len(prefix) > maxTypeaheadQueryLength {
+		prefix = prefix[:maxTypeaheadQueryLength]
+	}
+	request.Query = string(prefix)
+
+	candidateIDs, err := u.getTypeaheadCandidates(ctx, request.Query)
+	if err != nil {
+		u.log.Error("error in service layer while getting typeahead candidates", logger.Error(err))
+		return models.TypeaheadResponse{}, err
+	}
+
+	users, err := u.storage.User().Typeahead(ctx, request, candidateIDs)
+	if err != nil {
+		u.log.Error("error in service layer while ranking typeahead users", logger.Error(err))
+		return models.TypeaheadResponse{}, err
+	}
+
+	return models.TypeaheadResponse{Users: users}, nil
+}
+
+// getTypeaheadCandidates reads the candidates of the prefix from the cache,
+// falling back to the database when the cache misses or fails.
+func (u userService) getTypeaheadCandidates(ctx context.Context, prefix string) ([]string, error) {
+	key := "typeahead:" + prefix
+
+	cached, found, err := u.cache.Get(ctx, key)
+	if err != nil {
+		u.log.Error("error in service layer while reading typeahead cache", logger.Error(err))
+	}
+
+	if found {
+		candidateIDs := []string{}
+		if err = json.Unmarshal(cached, &candidateIDs); err == nil {
+			return candidateIDs, nil
+		}
+		u.log.Error("error in service layer while decoding typeahead cache", logger.Error(err))
+	}
+
+	candidateIDs, err := u.storage.User().GetTypeaheadCandidates(ctx, prefix, typeaheadCandidates)
+	if err != nil {
+		return nil, err
+	}
+
+	encoded, err := json.Marshal(candidateIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = u.cache.Set(ctx, key, encoded, typeaheadCacheTTL); err != nil {
+		u.log.Error("error in service layer while writing typeahead cache", logger.Error(err))
+	}
+
+	return candidateIDs, nil
+}
@@ -3,7 +3,9 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
 	"test/api/models"
+	"test/pkg/cache"
 	"test/pkg/check"
 	"test/pkg/logger"
 	"test/pkg/security"
@@ -14,11 +16,12 @@ import (
 
 type userService struct {
 	storage storage.IStorage
+	cache   cache.Cache
 	log     logger.ILogger
 }
 
-func NewuserService(storage storage.IStorage, log logger.ILogger) userService {
-	return userService{storage: storage, log: log}
+func NewuserService(storage storage.IStorage, cache cache.Cache, log logger.ILogger) userService {
+	return userService{storage: storage, cache: cache, log: log}
 }
 
 func (u userService) Create(ctx context.Context, createUser models.CreateUser) (string, error) {
@@ -50,6 +53,70 @@ func (u userService) GetByID(ctx context.Context, id models.PrimaryKey) (models.
 	return u.storage.User().GetByID(ctx, id)
 }
 
+// GetProfile loads the user with their pinned tweet, left out when the viewer
+// cannot see it.
+func (u userService) GetProfile(ctx context.Context, id models.PrimaryKey, viewerID string) (models.User, error) {
+	user, err := u.storage.User().GetByID(ctx, id)
+	if err != nil {
+		u.log.Error("error in service layer while getting user by id", logger.Error(err))
+		return models.User{}, err
+	}
+
+	if user.PinnedTweetID == nil {
+		return user, nil
+	}
+
+	pinned, err := getVisibleTweet(ctx, u.storage, *user.PinnedTweetID, viewerID)
+	switch {
+	case err == nil:
+	case errors.Is(err, ErrNotFound), errors.Is(err, pgx.ErrNoRows):
+		return user, nil
+	default:
+		u.log.Error("error in service layer while getting pinned tweet", logger.Error(err))
+		return models.User{}, err
+	}
+
+	if err = hydrateTweet(ctx, u.storage, viewerID, &pinned); err != nil {
+		u.log.Error("error in service layer while hydrating pinned tweet", logger.Error(err))
+		return models.User{}, err
+	}
+	user.PinnedTweet = &pinned
+
+	return user, nil
+}
+
+// PinTweet pins one of the user's tweets to their profile, replacing the one
+// pinned before.
+func (u userService) PinTweet(ctx context.Context, request models.PinTweet) (models.User, error) {
+	tweet, err := u.storage.Tweets().GetByID(ctx, models.PrimaryKey{ID: request.TweetID})
+	if err != nil {
+		u.log.Error("error in service layer while getting tweet to pin", logger.Error(err))
+		return models.User{}, err
+	}
+
+	if tweet.UserID != request.UserID {
+		return models.User{}, fmt.Errorf("%w: you can only pin your own tweets", ErrForbidden)
+	}
+
+	// the tweet may have been deleted meanwhile
+	if err = u.storage.User().SetPinnedTweet(ctx, request.UserID, &request.TweetID); err != nil {
+		u.log.Error("error in service layer while pinning tweet", logger.Error(err))
+		return models.User{}, err
+	}
+
+	return u.GetProfile(ctx, models.PrimaryKey{ID: request.UserID}, request.UserID)
+}
+
+// UnpinTweet unpins the tweet pinned to the user's profile, if any.
+func (u userService) UnpinTweet(ctx context.Context, userID string) error {
+	if err := u.storage.User().SetPinnedTweet(ctx, userID, nil); err != nil {
+		u.log.Error("error in service layer while unpinning tweet", logger.Error(err))
+		return err
+	}
+
+	return nil
+}
+
 func (u userService) GetList(ctx context.Context, request models.GetListRequest) (models.UsersResponse, error) {
 	u.log.Info("Get user list service layer", logger.Any("request", request))
 
@@ -130,7 +197,6 @@ func (u userService) GetUserCredentialsByLogin(ctx context.Context, login string
 	return user, nil
 }
 
-
 func (u userService) GetPassword(ctx context.Context, id models.PrimaryKey) (string, error) {
 	user, err := u.storage.User().GetByID(ctx, id)
 	if err != nil {
@@ -139,3 +205,39 @@ func (u userService) GetPassword(ctx context.Context, id models.PrimaryKey) (str
 	}
 	return user.PasswordHash, nil // Ensure `Password` field is accessible
 }
+
+func (u userService) GetSettings(ctx context.Context, id models.PrimaryKey) (models.UserSettings, error) {
+	settings, err := u.storage.User().GetSettings(ctx, id)
+	if err != nil {
+		u.log.Error("Error while getting user settings", logger.Error(err))
+		return models.UserSettings{}, err
+	}
+
+	return settings, nil
+}
+
+// UpdateSettings changes the user's account settings. Making a protected
+// account public accepts the follow requests that are still pending.
+func (u userService) UpdateSettings(ctx context.Context, request models.UpdateUserSettings) (models.UserSettings, error) {
+	if reminder := request.AltTextReminder; reminder != nil {
+		switch *reminder {
+		case models.AltTextReminderOff, models.AltTextReminderNudge, models.AltTextReminderRequire:
+		default:
+			return models.UserSettings{}, fmt.Errorf("%w: alt_text_reminder must be off, nudge or require", ErrInvalid)
+		}
+	}
+
+	if err := u.storage.User().UpdateSettings(ctx, request); err != nil {
+		u.log.Error("Error while updating user settings", logger.Error(err))
+		return models.UserSettings{}, err
+	}
+
+	if request.Protected != nil && !*request.Protected {
+		if err := u.storage.FollowRequests().AcceptAll(ctx, models.PrimaryKey{ID: request.ID}); err != nil {
+			u.log.Error("Error while accepting pending follow requests", logger.Error(err))
+			return models.UserSettings{}, err
+		}
+	}
+
+	return u.GetSettings(ctx, models.PrimaryKey{ID: request.ID})
+}
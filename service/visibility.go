@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"test/api/models"
+	"test/storage"
+)
+
+// ensureVisible returns ErrNotFound when viewerID may not see the content of
+// authorID, so hidden content is indistinguishable from missing content.
+func ensureVisible(ctx context.Context, store storage.IStorage, authorID, viewerID string) error {
+	visible, err := store.User().CanView(ctx, authorID, viewerID)
+	if err != nil {
+		return err
+	}
+
+	if !visible {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// getVisibleTweet loads the tweet if viewerID may see it.
+func getVisibleTweet(ctx context.Context, store storage.IStorage, tweetID, viewerID string) (models.Tweet, error) {
+	tweet, err := store.Tweets().GetByID(ctx, models.PrimaryKey{ID: tweetID})
+	if err != nil {
+		return models.Tweet{}, err
+	}
+
+	if err = ensureTweetVisible(ctx, store, tweet, viewerID); err != nil {
+		return models.Tweet{}, err
+	}
+
+	return tweet, nil
+}
+
+// ensureTweetVisible is ensureVisible for a tweet, which is also hidden from
+// everyone but its author once moderators hid it.
+func ensureTweetVisible(ctx context.Context, store storage.IStorage, tweet models.Tweet, viewerID string) error {
+	if tweet.Hidden && tweet.UserID != viewerID {
+		return ErrNotFound
+	}
+
+	return ensureVisible(ctx, store, tweet.UserID, viewerID)
+}
+
+// ensureCanReply returns ErrForbidden, explained by the reply audience, when
+// userID may not reply to the tweet.
+func ensureCanReply(ctx context.Context, store storage.IStorage, tweet models.Tweet, userID string) error {
+	ids, err := store.Tweets().GetRepliableIDs(ctx, []string{tweet.ID}, userID)
+	if err != nil {
+		return err
+	}
+
+	if len(ids) > 0 {
+		return nil
+	}
+
+	switch tweet.ReplyAudience {
+	case models.ReplyAudienceFollowing:
+		return fmt.Errorf("%w: only people the author follows or mentioned can reply to this tweet", ErrForbidden)
+	case models.ReplyAudienceMentioned:
+		return fmt.Errorf("%w: only people the author mentioned can reply to this tweet", ErrForbidden)
+	default:
+		return fmt.Errorf("%w: you cannot reply to this tweet", ErrForbidden)
+	}
+}
+
+// checkReplyAudience checks the reply audience of a tweet, defaulting it to
+// everyone.
+func checkReplyAudience(audience *string) error {
+	switch *audience {
+	case "":
+		*audience = models.ReplyAudienceEveryone
+	case models.ReplyAudienceEveryone, models.ReplyAudienceFollowing, models.ReplyAudienceMentioned:
+	default:
+		return fmt.Errorf("%w: reply_audience must be %s, %s or %s", ErrInvalid,
+			models.ReplyAudienceEveryone, models.ReplyAudienceFollowing, models.ReplyAudienceMentioned)
+	}
+
+	return nil
+}
+
+// ensureNotBlocked returns ErrForbidden, explained by action, when either user
+// has blocked the other.
+func ensureNotBlocked(ctx context.Context, store storage.IStorage, userID, otherUserID, action string) error {
+	blocked, err := store.Blocks().Exists(ctx, userID, otherUserID)
+	if err != nil {
+		return err
+	}
+
+	if blocked {
+		return fmt.Errorf("%w: you cannot %s this user", ErrForbidden, action)
+	}
+
+	return nil
+}
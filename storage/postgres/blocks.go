@@ -0,0 +1,180 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"test/api/models"
+	"test/pkg/logger"
+	"test/storage"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type blockRepo struct {
+	db  *pgxpool.Pool
+	log logger.ILogger
+}
+
+func NewBlocksRepo(db *pgxpool.Pool, log logger.ILogger) storage.IBlocksStorage {
+	return &blockRepo{
+		db:  db,
+		log: log,
+	}
+}
+
+// Create blocks the user and removes every follow relationship, pending
+// follow request and list membership or subscription between the two users.
+// Blocking an already blocked user returns the existing block.
+func (b *blockRepo) Create(ctx context.Context, block models.CreateBlock) (string, error) {
+	if block.UserID == block.BlockedUserID {
+		err := errors.New("users cannot block themselves")
+		b.log.Error("validation error", logger.Error(err))
+		return "", err
+	}
+
+	tx, err := b.db.Begin(ctx)
+	if err != nil {
+		b.log.Error("error while beginning block transaction", logger.Error(err))
+		return "", err
+	}
+	defer tx.Rollback(ctx)
+
+	var id string
+	query := `
+		INSERT INTO blocks (block_id, user_id, blocked_user_id) VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, blocked_user_id) DO NOTHING
+		RETURNING block_id
+	`
+	switch err = tx.QueryRow(ctx, query, uuid.New(), block.UserID, block.BlockedUserID).Scan(&id); {
+	case errors.Is(err, pgx.ErrNoRows):
+		// already blocked, nothing was inserted
+		query = `SELECT block_id FROM blocks WHERE user_id = $1 AND blocked_user_id = $2`
+		if err = tx.QueryRow(ctx, query, block.UserID, block.BlockedUserID).Scan(&id); err != nil {
+			b.log.Error("error while selecting existing block", logger.Error(err))
+			return "", err
+		}
+	case err != nil:
+		b.log.Error("error while inserting block", logger.Error(err))
+		return "", err
+	}
+
+	query = `
+		DELETE FROM followers
+		WHERE (user_id = $1 AND follower_user_id = $2)
+		OR (user_id = $2 AND follower_user_id = $1)
+	`
+	if _, err = tx.Exec(ctx, query, block.UserID, block.BlockedUserID); err != nil {
+		b.log.Error("error while deleting followers of blocked user", logger.Error(err))
+		return "", err
+	}
+
+	query = `
+		DELETE FROM follow_requests
+		WHERE (user_id = $1 AND requester_user_id = $2)
+		OR (user_id = $2 AND requester_user_id = $1)
+	`
+	if _, err = tx.Exec(ctx, query, block.UserID, block.BlockedUserID); err != nil {
+		b.log.Error("error while deleting follow requests of blocked user", logger.Error(err))
+		return "", err
+	}
+
+	for _, table := range []string{"list_members", "list_subscribers"} {
+		query = `
+			DELETE FROM ` + table + ` lu USING lists l
+			WHERE lu.list_id = l.list_id
+			AND ((l.owner_user_id = $1 AND lu.user_id = $2) OR (l.owner_user_id = $2 AND lu.user_id = $1))
+		`
+		if _, err = tx.Exec(ctx, query, block.UserID, block.BlockedUserID); err != nil {
+			b.log.Error("error while deleting list memberships of blocked user", logger.Error(err))
+			return "", err
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		b.log.Error("error while committing block transaction", logger.Error(err))
+		return "", err
+	}
+
+	return id, nil
+}
+
+func (b *blockRepo) GetByID(ctx context.Context, key models.PrimaryKey) (models.Block, error) {
+	block := models.Block{}
+	query := `SELECT block_id, user_id, blocked_user_id, created_at FROM blocks WHERE block_id = $1`
+	if err := b.db.QueryRow(ctx, query, key.ID).Scan(&block.BlockID, &block.UserID, &block.BlockedUserID, &block.CreatedAt); err != nil {
+		b.log.Error("error while selecting block", logger.Error(err))
+		return models.Block{}, err
+	}
+
+	return block, nil
+}
+
+// GetList lists the users req.UserID has blocked, most recent first.
+func (b *blockRepo) GetList(ctx context.Context, req models.GetListRequest) (models.UserSummariesResponse, error) {
+	var (
+		count  = 0
+		offset = (req.Page - 1) * req.Limit
+	)
+
+	countQuery := `SELECT COUNT(1) FROM blocks WHERE user_id = $1`
+	if err := b.db.QueryRow(ctx, countQuery, req.UserID).Scan(&count); err != nil {
+		b.log.Error("error while counting blocked users", logger.Error(err))
+		return models.UserSummariesResponse{}, err
+	}
+
+	query := `
+		SELECT ` + userSummaryColumns + `
+		FROM blocks b
+		JOIN users u ON u.user_id = b.blocked_user_id
+		WHERE b.user_id = $1
+		ORDER BY b.created_at DESC LIMIT $2 OFFSET $3
+	`
+	rows, err := b.db.Query(ctx, query, req.UserID, req.Limit, offset)
+	if err != nil {
+		b.log.Error("error while selecting blocked users", logger.Error(err))
+		return models.UserSummariesResponse{}, err
+	}
+
+	users, err := scanUserSummaries(rows)
+	if err != nil {
+		b.log.Error("error while scanning blocked users", logger.Error(err))
+		return models.UserSummariesResponse{}, err
+	}
+
+	return models.UserSummariesResponse{
+		Users: users,
+		Count: count,
+	}, nil
+}
+
+// Delete unblocks the user. Unblocking a user that is not blocked is not an
+// error.
+func (b *blockRepo) Delete(ctx context.Context, block models.CreateBlock) error {
+	query := `DELETE FROM blocks WHERE user_id = $1 AND blocked_user_id = $2`
+	if _, err := b.db.Exec(ctx, query, block.UserID, block.BlockedUserID); err != nil {
+		b.log.Error("error while deleting block", logger.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// Exists reports whether either user has blocked the other.
+func (b *blockRepo) Exists(ctx context.Context, userID, otherUserID string) (bool, error) {
+	var exists bool
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM blocks
+			WHERE (user_id = $1 AND blocked_user_id = $2)
+			OR (user_id = $2 AND blocked_user_id = $1)
+		)
+	`
+	if err := b.db.QueryRow(ctx, query, userID, otherUserID).Scan(&exists); err != nil {
+		b.log.Error("error while checking block", logger.Error(err))
+		return false, err
+	}
+
+	return exists, nil
+}
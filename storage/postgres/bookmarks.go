@@ -0,0 +1,254 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"test/api/models"
+	"test/pkg/cursor"
+	"test/pkg/logger"
+	"test/storage"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type bookmarkRepo struct {
+	db  *pgxpool.Pool
+	log logger.ILogger
+}
+
+func NewBookmarksRepo(db *pgxpool.Pool, log logger.ILogger) storage.IBookmarksStorage {
+	return &bookmarkRepo{
+		db:  db,
+		log: log,
+	}
+}
+
+// Upsert bookmarks the tweet. Bookmarking it again moves it to the given folder
+// or keeps it where it is when no folder is given.
+func (b *bookmarkRepo) Upsert(ctx context.Context, bookmark models.CreateBookmark) (string, error) {
+	var id string
+	query := `
+		INSERT INTO bookmarks (bookmark_id, user_id, tweet_id, folder_id) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id, tweet_id) DO UPDATE SET folder_id = COALESCE(EXCLUDED.folder_id, bookmarks.folder_id)
+		RETURNING bookmark_id
+	`
+	if err := b.db.QueryRow(ctx, query, uuid.New(), bookmark.UserID, bookmark.TweetID, bookmark.FolderID).Scan(&id); err != nil {
+		b.log.Error("error while upserting bookmark", logger.Error(err))
+		return "", err
+	}
+
+	return id, nil
+}
+
+func (b *bookmarkRepo) GetByID(ctx context.Context, key models.PrimaryKey) (models.Bookmark, error) {
+	bookmark := models.Bookmark{}
+	query := `
+		SELECT b.bookmark_id, b.folder_id, b.created_at, ` + tweetColumns + `
+		FROM bookmarks b
+		JOIN tweets t ON t.tweet_id = b.tweet_id
+		WHERE b.bookmark_id = $1
+	`
+	if err := b.db.QueryRow(ctx, query, key.ID).Scan(
+		&bookmark.BookmarkID, &bookmark.FolderID, &bookmark.CreatedAt,
+		&bookmark.Tweet.ID, &bookmark.Tweet.UserID, &bookmark.Tweet.Content, &bookmark.Tweet.ImageURL, &bookmark.Tweet.VideoURL,
+		&bookmark.Tweet.ReplyToTweetID, &bookmark.Tweet.ConversationID, &bookmark.Tweet.ReplyAudience, &bookmark.Tweet.EditCount, &bookmark.Tweet.Hidden, &bookmark.Tweet.CreatedAt, &bookmark.Tweet.UpdatedAt,
+	); err != nil {
+		b.log.Error("error while selecting bookmark", logger.Error(err))
+		return models.Bookmark{}, err
+	}
+
+	return bookmark, nil
+}
+
+// Delete removes the bookmark. Removing a tweet that is not bookmarked is not
+// an error.
+func (b *bookmarkRepo) Delete(ctx context.Context, bookmark models.CreateBookmark) error {
+	query := `DELETE FROM bookmarks WHERE user_id = $1 AND tweet_id = $2`
+	if _, err := b.db.Exec(ctx, query, bookmark.UserID, bookmark.TweetID); err != nil {
+		b.log.Error("error while deleting bookmark", logger.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// GetList pages through the bookmarks of req.UserID, most recent first,
+// leaving out tweets the user may no longer see.
+func (b *bookmarkRepo) GetList(ctx context.Context, req models.GetBookmarksRequest) (models.BookmarksResponse, error) {
+	bookmarks := []models.Bookmark{}
+
+	query := `
+		SELECT b.bookmark_id, b.folder_id, b.created_at, ` + tweetColumns + `
+		FROM bookmarks b
+		JOIN tweets t ON t.tweet_id = b.tweet_id
+		WHERE b.user_id = $1
+		AND ($2 = '' OR b.folder_id = NULLIF($2, '')::uuid)
+		AND ($3::timestamp IS NULL OR (b.created_at, b.bookmark_id) < ($3::timestamp, NULLIF($4, '')::uuid))
+		AND ` + tweetVisibleToViewer("$1") + `
+		ORDER BY b.created_at DESC, b.bookmark_id DESC
+		LIMIT $5
+	`
+	rows, err := b.db.Query(ctx, query, req.UserID, req.FolderID, req.CursorCreatedAt, req.CursorID, req.Limit+1)
+	if err != nil {
+		b.log.Error("error while selecting bookmarks", logger.Error(err))
+		return models.BookmarksResponse{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		bookmark := models.Bookmark{}
+		if err = rows.Scan(
+			&bookmark.BookmarkID, &bookmark.FolderID, &bookmark.CreatedAt,
+			&bookmark.Tweet.ID, &bookmark.Tweet.UserID, &bookmark.Tweet.Content, &bookmark.Tweet.ImageURL, &bookmark.Tweet.VideoURL,
+			&bookmark.Tweet.ReplyToTweetID, &bookmark.Tweet.ConversationID, &bookmark.Tweet.ReplyAudience, &bookmark.Tweet.EditCount, &bookmark.Tweet.Hidden, &bookmark.Tweet.CreatedAt, &bookmark.Tweet.UpdatedAt,
+		); err != nil {
+			b.log.Error("error while scanning bookmark", logger.Error(err))
+			return models.BookmarksResponse{}, err
+		}
+		bookmarks = append(bookmarks, bookmark)
+	}
+
+	// one extra row is selected to know whether another page follows
+	response := models.BookmarksResponse{Bookmarks: bookmarks}
+	if len(bookmarks) > req.Limit {
+		response.Bookmarks = bookmarks[:req.Limit]
+		last := response.Bookmarks[req.Limit-1]
+		response.NextCursor = cursor.Encode(last.CreatedAt, last.BookmarkID)
+	}
+
+	return response, nil
+}
+
+// GetBookmarkedTweetIDs reports which of the tweets userID has bookmarked.
+func (b *bookmarkRepo) GetBookmarkedTweetIDs(ctx context.Context, userID string, tweetIDs []string) ([]string, error) {
+	ids := []string{}
+	query := `SELECT tweet_id FROM bookmarks WHERE user_id = $1 AND tweet_id = ANY($2::uuid[])`
+	rows, err := b.db.Query(ctx, query, userID, tweetIDs)
+	if err != nil {
+		b.log.Error("error while selecting bookmarked tweets", logger.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+		if err = rows.Scan(&id); err != nil {
+			b.log.Error("error while scanning bookmarked tweet", logger.Error(err))
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// CreateFolder adds a folder after the user's other folders.
+func (b *bookmarkRepo) CreateFolder(ctx context.Context, folder models.CreateBookmarkFolder) (string, error) {
+	var id string
+	query := `
+		INSERT INTO bookmark_folders (folder_id, user_id, name, position)
+		SELECT $1::uuid, $2::uuid, $3::text, COALESCE(MAX(position) + 1, 0) FROM bookmark_folders WHERE user_id = $2
+		RETURNING folder_id
+	`
+	if err := b.db.QueryRow(ctx, query, uuid.New(), folder.UserID, folder.Name).Scan(&id); err != nil {
+		b.log.Error("error while inserting bookmark folder", logger.Error(err))
+		return "", err
+	}
+
+	return id, nil
+}
+
+func (b *bookmarkRepo) GetFolderByID(ctx context.Context, key models.PrimaryKey) (models.BookmarkFolder, error) {
+	folder := models.BookmarkFolder{}
+	query := `SELECT folder_id, user_id, name, position, created_at, updated_at FROM bookmark_folders WHERE folder_id = $1`
+	if err := b.db.QueryRow(ctx, query, key.ID).Scan(
+		&folder.FolderID, &folder.UserID, &folder.Name, &folder.Position, &folder.CreatedAt, &folder.UpdatedAt,
+	); err != nil {
+		b.log.Error("error while selecting bookmark folder", logger.Error(err))
+		return models.BookmarkFolder{}, err
+	}
+
+	return folder, nil
+}
+
+// GetFolders lists every folder of the user in the user's order.
+func (b *bookmarkRepo) GetFolders(ctx context.Context, userID string) (models.BookmarkFoldersResponse, error) {
+	folders := []models.BookmarkFolder{}
+	query := `
+		SELECT folder_id, user_id, name, position, created_at, updated_at
+		FROM bookmark_folders
+		WHERE user_id = $1
+		ORDER BY position, created_at
+	`
+	rows, err := b.db.Query(ctx, query, userID)
+	if err != nil {
+		b.log.Error("error while selecting bookmark folders", logger.Error(err))
+		return models.BookmarkFoldersResponse{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		folder := models.BookmarkFolder{}
+		if err = rows.Scan(&folder.FolderID, &folder.UserID, &folder.Name, &folder.Position, &folder.CreatedAt, &folder.UpdatedAt); err != nil {
+			b.log.Error("error while scanning bookmark folder", logger.Error(err))
+			return models.BookmarkFoldersResponse{}, err
+		}
+		folders = append(folders, folder)
+	}
+
+	return models.BookmarkFoldersResponse{
+		Folders: folders,
+		Count:   len(folders),
+	}, nil
+}
+
+// UpdateFolder renames the folder if it belongs to the user.
+func (b *bookmarkRepo) UpdateFolder(ctx context.Context, folder models.UpdateBookmarkFolder) error {
+	query := `UPDATE bookmark_folders SET name = $1, updated_at = NOW() WHERE folder_id = $2 AND user_id = $3`
+	cmdTag, err := b.db.Exec(ctx, query, folder.Name, folder.FolderID, folder.UserID)
+	if err != nil {
+		b.log.Error("error while updating bookmark folder", logger.Error(err))
+		return err
+	}
+
+	if cmdTag.RowsAffected() == 0 {
+		b.log.Error("no rows affected while updating bookmark folder")
+		return fmt.Errorf("no rows affected")
+	}
+
+	return nil
+}
+
+// DeleteFolder removes the folder if it belongs to the user, its bookmarks are
+// kept outside of any folder.
+func (b *bookmarkRepo) DeleteFolder(ctx context.Context, key models.PrimaryKey, userID string) error {
+	query := `DELETE FROM bookmark_folders WHERE folder_id = $1 AND user_id = $2`
+	cmdTag, err := b.db.Exec(ctx, query, key.ID, userID)
+	if err != nil {
+		b.log.Error("error while deleting bookmark folder", logger.Error(err))
+		return err
+	}
+
+	if cmdTag.RowsAffected() == 0 {
+		b.log.Error("no rows affected while deleting bookmark folder")
+		return fmt.Errorf("no rows affected")
+	}
+
+	return nil
+}
+
+// ReorderFolders positions the user's folders in the given order.
+func (b *bookmarkRepo) ReorderFolders(ctx context.Context, request models.ReorderBookmarkFolders) error {
+	query := `
+		UPDATE bookmark_folders f SET position = o.position - 1, updated_at = NOW()
+		FROM unnest($2::uuid[]) WITH ORDINALITY AS o(folder_id, position)
+		WHERE f.folder_id = o.folder_id AND f.user_id = $1
+	`
+	if _, err := b.db.Exec(ctx, query, request.UserID, request.FolderIDs); err != nil {
+		b.log.Error("error while reordering bookmark folders", logger.Error(err))
+		return err
+	}
+
+	return nil
+}
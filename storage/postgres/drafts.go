@@ -0,0 +1,263 @@
+package postgres
+
+import (
+	"context"
+	"test/api/models"
+	"test/pkg/logger"
+	"test/storage"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const draftColumns = `draft_id, user_id, content, media_ids, reply_to_tweet_id, poll, reply_audience, ignore_alt_text_reminder,
+	state, publish_at, attempts, last_error, created_at, updated_at`
+
+type draftRepo struct {
+	db  *pgxpool.Pool
+	log logger.ILogger
+}
+
+func NewDraftsRepo(db *pgxpool.Pool, log logger.ILogger) storage.IDraftsStorage {
+	return &draftRepo{
+		db:  db,
+		log: log,
+	}
+}
+
+func scanDraft(row pgx.Row, draft *models.Draft) error {
+	return row.Scan(
+		&draft.ID, &draft.UserID, &draft.Content, &draft.MediaIDs, &draft.ReplyToTweetID, &draft.Poll, &draft.ReplyAudience, &draft.IgnoreAltTextReminder,
+		&draft.State, &draft.PublishAt, &draft.Attempts, &draft.LastError, &draft.CreatedAt, &draft.UpdatedAt,
+	)
+}
+
+func scanDraftRows(rows pgx.Rows) ([]models.Draft, error) {
+	defer rows.Close()
+
+	drafts := []models.Draft{}
+	for rows.Next() {
+		draft := models.Draft{}
+		if err := scanDraft(rows, &draft); err != nil {
+			return nil, err
+		}
+		drafts = append(drafts, draft)
+	}
+
+	return drafts, rows.Err()
+}
+
+// draftState is the state of a draft scheduled at publishAt, if at all.
+func draftState(publishAt *time.Time) string {
+	if publishAt == nil {
+		return models.DraftStateDraft
+	}
+
+	return models.DraftStateScheduled
+}
+
+func (d *draftRepo) Create(ctx context.Context, draft models.CreateDraft) (string, error) {
+	var id string
+	query := `
+		INSERT INTO drafts (user_id, content, media_ids, reply_to_tweet_id, poll, reply_audience, ignore_alt_text_reminder, state, publish_at)
+		VALUES ($1, $2, $3::uuid[], $4, $5, $6, $7, $8, $9::timestamptz)
+		RETURNING draft_id
+	`
+	if err := d.db.QueryRow(ctx, query,
+		draft.UserID, draft.Content, mediaIDs(draft.MediaIDs), draft.ReplyToTweetID, draft.Poll, draft.ReplyAudience, draft.IgnoreAltTextReminder,
+		draftState(draft.PublishAt), draft.PublishAt,
+	).Scan(&id); err != nil {
+		d.log.Error("error while inserting draft", logger.Error(err))
+		return "", err
+	}
+
+	return id, nil
+}
+
+func (d *draftRepo) GetByID(ctx context.Context, key models.PrimaryKey) (models.Draft, error) {
+	draft := models.Draft{}
+	query := `SELECT ` + draftColumns + ` FROM drafts WHERE draft_id = $1`
+	if err := scanDraft(d.db.QueryRow(ctx, query, key.ID), &draft); err != nil {
+		d.log.Error("error while selecting draft", logger.Error(err))
+		return models.Draft{}, err
+	}
+
+	return draft, nil
+}
+
+// GetList lists the drafts of req.UserID. Scheduled drafts come in the order
+// they are published, the others most recently edited first.
+func (d *draftRepo) GetList(ctx context.Context, req models.GetDraftsRequest) (models.DraftsResponse, error) {
+	var (
+		count  = 0
+		offset = (req.Page - 1) * req.Limit
+	)
+
+	filter := `
+		FROM drafts
+		WHERE user_id = $1
+		AND ($2::boolean IS NULL OR (state IN ('` + models.DraftStateScheduled + `', '` + models.DraftStatePublishing + `')) = $2::boolean)
+	`
+
+	countQuery := `SELECT COUNT(1)` + filter
+	if err := d.db.QueryRow(ctx, countQuery, req.UserID, req.Scheduled).Scan(&count); err != nil {
+		d.log.Error("error while counting drafts", logger.Error(err))
+		return models.DraftsResponse{}, err
+	}
+
+	query := `SELECT ` + draftColumns + filter + `
+		ORDER BY publish_at NULLS LAST, updated_at DESC, draft_id
+		LIMIT $3 OFFSET $4
+	`
+	rows, err := d.db.Query(ctx, query, req.UserID, req.Scheduled, req.Limit, offset)
+	if err != nil {
+		d.log.Error("error while selecting drafts", logger.Error(err))
+		return models.DraftsResponse{}, err
+	}
+
+	drafts, err := scanDraftRows(rows)
+	if err != nil {
+		d.log.Error("error while scanning drafts", logger.Error(err))
+		return models.DraftsResponse{}, err
+	}
+
+	return models.DraftsResponse{
+		Drafts: drafts,
+		Count:  count,
+	}, nil
+}
+
+// Update replaces the draft if it belongs to draft.UserID and is not being
+// published, clearing any failure.
+func (d *draftRepo) Update(ctx context.Context, draft models.UpdateDraft) error {
+	query := `
+		UPDATE drafts
+		SET content = $3, media_ids = $4::uuid[], reply_to_tweet_id = $5, poll = $6, reply_audience = $7, ignore_alt_text_reminder = $8,
+			state = $9, publish_at = $10::timestamptz, attempts = 0, lease_until = NULL, last_error = NULL, updated_at = NOW()
+		WHERE draft_id = $1 AND user_id = $2 AND state <> $11
+	`
+	cmdTag, err := d.db.Exec(ctx, query,
+		draft.ID, draft.UserID, draft.Content, mediaIDs(draft.MediaIDs), draft.ReplyToTweetID, draft.Poll, draft.ReplyAudience, draft.IgnoreAltTextReminder,
+		draftState(draft.PublishAt), draft.PublishAt, models.DraftStatePublishing,
+	)
+	if err != nil {
+		d.log.Error("error while updating draft", logger.Error(err))
+		return err
+	}
+
+	if cmdTag.RowsAffected() == 0 {
+		d.log.Error("no rows affected while updating draft")
+		return pgx.ErrNoRows
+	}
+
+	return nil
+}
+
+// Delete removes the draft, cancelling it if scheduled, if it belongs to
+// userID and is not being published.
+func (d *draftRepo) Delete(ctx context.Context, key models.PrimaryKey, userID string) error {
+	query := `DELETE FROM drafts WHERE draft_id = $1 AND user_id = $2 AND state <> $3`
+	cmdTag, err := d.db.Exec(ctx, query, key.ID, userID, models.DraftStatePublishing)
+	if err != nil {
+		d.log.Error("error while deleting draft", logger.Error(err))
+		return err
+	}
+
+	if cmdTag.RowsAffected() == 0 {
+		d.log.Error("no rows affected while deleting draft")
+		return pgx.ErrNoRows
+	}
+
+	return nil
+}
+
+// Claim starts publishing the draft of userID right away, unless it is being
+// published already. The claim lasts for lease.
+func (d *draftRepo) Claim(ctx context.Context, key models.PrimaryKey, userID string, lease time.Duration) (models.Draft, error) {
+	draft := models.Draft{}
+	query := `
+		UPDATE drafts
+		SET state = $3, attempts = attempts + 1, lease_until = NOW() + $4::float8 * INTERVAL '1 second', updated_at = NOW()
+		WHERE draft_id = $1 AND user_id = $2 AND state <> $3
+		RETURNING ` + draftColumns
+	if err := scanDraft(d.db.QueryRow(ctx, query, key.ID, userID, models.DraftStatePublishing, lease.Seconds()), &draft); err != nil {
+		d.log.Error("error while claiming draft", logger.Error(err))
+		return models.Draft{}, err
+	}
+
+	return draft, nil
+}
+
+// ClaimDue claims up to limit scheduled drafts that are due, along with the
+// ones whose publishing lease expired, for lease. Drafts claimed by others are
+// skipped, so that each is published by a single instance.
+func (d *draftRepo) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]models.Draft, error) {
+	query := `
+		UPDATE drafts d
+		SET state = $1, attempts = d.attempts + 1, lease_until = NOW() + $4::float8 * INTERVAL '1 second', updated_at = NOW()
+		WHERE d.draft_id IN (
+			SELECT draft_id FROM drafts
+			WHERE (state = $2 AND publish_at <= NOW()) OR (state = $1 AND lease_until <= NOW())
+			ORDER BY COALESCE(lease_until, publish_at)
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + draftColumns
+	rows, err := d.db.Query(ctx, query, models.DraftStatePublishing, models.DraftStateScheduled, limit, lease.Seconds())
+	if err != nil {
+		d.log.Error("error while claiming due drafts", logger.Error(err))
+		return nil, err
+	}
+
+	drafts, err := scanDraftRows(rows)
+	if err != nil {
+		d.log.Error("error while scanning due drafts", logger.Error(err))
+		return nil, err
+	}
+
+	return drafts, nil
+}
+
+// RetryPublishing keeps the draft claimed until retryAt, when it is claimed
+// again.
+func (d *draftRepo) RetryPublishing(ctx context.Context, draftID, reason string, retryAt time.Time) error {
+	query := `UPDATE drafts SET lease_until = $3, last_error = $2, updated_at = NOW() WHERE draft_id = $1 AND state = $4`
+	if _, err := d.db.Exec(ctx, query, draftID, reason, retryAt, models.DraftStatePublishing); err != nil {
+		d.log.Error("error while scheduling draft publishing retry", logger.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// Fail gives up publishing the draft, which stays for its author to fix.
+func (d *draftRepo) Fail(ctx context.Context, draftID, reason string) error {
+	query := `UPDATE drafts SET state = $2, last_error = $3, lease_until = NULL, updated_at = NOW() WHERE draft_id = $1`
+	if _, err := d.db.Exec(ctx, query, draftID, models.DraftStateFailed, reason); err != nil {
+		d.log.Error("error while marking draft failed", logger.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// Published removes the draft once its tweet is published.
+func (d *draftRepo) Published(ctx context.Context, draftID string) error {
+	query := `DELETE FROM drafts WHERE draft_id = $1`
+	if _, err := d.db.Exec(ctx, query, draftID); err != nil {
+		d.log.Error("error while removing published draft", logger.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// mediaIDs stores drafts without media as an empty array rather than NULL.
+func mediaIDs(ids []string) []string {
+	if ids == nil {
+		return []string{}
+	}
+
+	return ids
+}
@@ -0,0 +1,178 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"test/api/models"
+	"test/pkg/logger"
+	"test/storage"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type followRequestRepo struct {
+	db  *pgxpool.Pool
+	log logger.ILogger
+}
+
+func NewFollowRequestsRepo(db *pgxpool.Pool, log logger.ILogger) storage.IFollowRequestsStorage {
+	return &followRequestRepo{
+		db:  db,
+		log: log,
+	}
+}
+
+func (f *followRequestRepo) Create(ctx context.Context, request models.CreateFollower) (string, error) {
+	if request.UserID == request.FollowerUserID {
+		err := errors.New("users cannot follow themselves")
+		f.log.Error("validation error", logger.Error(err))
+		return "", err
+	}
+
+	id := uuid.New()
+
+	query := `INSERT INTO follow_requests (follow_request_id, user_id, requester_user_id) VALUES ($1, $2, $3)`
+	if _, err := f.db.Exec(ctx, query, id, request.UserID, request.FollowerUserID); err != nil {
+		if isUniqueViolation(err) {
+			err = errors.New("follow request is already sent")
+		}
+		f.log.Error("error while inserting follow request", logger.Error(err))
+		return "", err
+	}
+
+	return id.String(), nil
+}
+
+func (f *followRequestRepo) GetByID(ctx context.Context, key models.PrimaryKey) (models.FollowRequest, error) {
+	request := models.FollowRequest{}
+	query := `
+		SELECT fr.follow_request_id, fr.user_id, fr.requester_user_id, fr.created_at, ` + userSummaryColumns + `
+		FROM follow_requests fr
+		JOIN users u ON u.user_id = fr.requester_user_id
+		WHERE fr.follow_request_id = $1
+	`
+	if err := f.db.QueryRow(ctx, query, key.ID).Scan(
+		&request.FollowRequestID, &request.UserID, &request.RequesterUserID, &request.CreatedAt,
+		&request.User.ID, &request.User.Username, &request.User.Name, &request.User.ProfilePicture, &request.User.Protected,
+	); err != nil {
+		f.log.Error("error while selecting follow request", logger.Error(err))
+		return models.FollowRequest{}, err
+	}
+
+	return request, nil
+}
+
+// GetIncoming lists the pending requests to follow req.UserID along with the
+// requesting users.
+func (f *followRequestRepo) GetIncoming(ctx context.Context, req models.GetListRequest) (models.FollowRequestsResponse, error) {
+	return f.list(ctx, "fr.user_id", "fr.requester_user_id", req)
+}
+
+// GetOutgoing lists the pending requests sent by req.UserID along with the
+// requested users.
+func (f *followRequestRepo) GetOutgoing(ctx context.Context, req models.GetListRequest) (models.FollowRequestsResponse, error) {
+	return f.list(ctx, "fr.requester_user_id", "fr.user_id", req)
+}
+
+// Accept turns the follow request into a follower relationship.
+func (f *followRequestRepo) Accept(ctx context.Context, key models.PrimaryKey) error {
+	query := `
+		WITH accepted AS (
+			DELETE FROM follow_requests WHERE follow_request_id = $1
+			RETURNING user_id, requester_user_id
+		)
+		INSERT INTO followers (user_id, follower_user_id)
+		SELECT user_id, requester_user_id FROM accepted
+		ON CONFLICT (user_id, follower_user_id) DO NOTHING
+	`
+	if _, err := f.db.Exec(ctx, query, key.ID); err != nil {
+		f.log.Error("error while accepting follow request", logger.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// AcceptAll accepts every pending request to follow the user.
+func (f *followRequestRepo) AcceptAll(ctx context.Context, key models.PrimaryKey) error {
+	query := `
+		WITH accepted AS (
+			DELETE FROM follow_requests WHERE user_id = $1
+			RETURNING user_id, requester_user_id
+		)
+		INSERT INTO followers (user_id, follower_user_id)
+		SELECT user_id, requester_user_id FROM accepted
+		ON CONFLICT (user_id, follower_user_id) DO NOTHING
+	`
+	if _, err := f.db.Exec(ctx, query, key.ID); err != nil {
+		f.log.Error("error while accepting all follow requests", logger.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+func (f *followRequestRepo) Delete(ctx context.Context, key models.PrimaryKey) error {
+	query := `DELETE FROM follow_requests WHERE follow_request_id = $1`
+	cmdTag, err := f.db.Exec(ctx, query, key.ID)
+	if err != nil {
+		f.log.Error("error while deleting follow request", logger.Error(err))
+		return err
+	}
+
+	if cmdTag.RowsAffected() == 0 {
+		f.log.Error("no rows affected while deleting follow request")
+		return fmt.Errorf("no rows affected")
+	}
+
+	return nil
+}
+
+// list pages through follow requests where ownerColumn is req.UserID, joining
+// the user in otherColumn as the other side of the request.
+func (f *followRequestRepo) list(ctx context.Context, ownerColumn, otherColumn string, req models.GetListRequest) (models.FollowRequestsResponse, error) {
+	var (
+		requests = []models.FollowRequest{}
+		count    = 0
+		offset   = (req.Page - 1) * req.Limit
+	)
+
+	countQuery := `SELECT COUNT(1) FROM follow_requests fr WHERE ` + ownerColumn + ` = $1`
+	if err := f.db.QueryRow(ctx, countQuery, req.UserID).Scan(&count); err != nil {
+		f.log.Error("error while counting follow requests", logger.Error(err))
+		return models.FollowRequestsResponse{}, err
+	}
+
+	query := `
+		SELECT fr.follow_request_id, fr.user_id, fr.requester_user_id, fr.created_at, ` + userSummaryColumns + `
+		FROM follow_requests fr
+		JOIN users u ON u.user_id = ` + otherColumn + `
+		WHERE ` + ownerColumn + ` = $1
+		ORDER BY fr.created_at DESC LIMIT $2 OFFSET $3
+	`
+	rows, err := f.db.Query(ctx, query, req.UserID, req.Limit, offset)
+	if err != nil {
+		f.log.Error("error while selecting follow requests", logger.Error(err))
+		return models.FollowRequestsResponse{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		request := models.FollowRequest{}
+		if err = rows.Scan(
+			&request.FollowRequestID, &request.UserID, &request.RequesterUserID, &request.CreatedAt,
+			&request.User.ID, &request.User.Username, &request.User.Name, &request.User.ProfilePicture, &request.User.Protected,
+		); err != nil {
+			f.log.Error("error while scanning follow request", logger.Error(err))
+			return models.FollowRequestsResponse{}, err
+		}
+		requests = append(requests, request)
+	}
+
+	return models.FollowRequestsResponse{
+		FollowRequests: requests,
+		Count:          count,
+	}, nil
+}
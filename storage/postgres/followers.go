@@ -34,10 +34,9 @@ func (b *followerRepo) Create(ctx context.Context, follower models.CreateFollowe
 	id := uuid.New()
 
 	query := `INSERT INTO followers (follower_id, user_id, follower_user_id) VALUES ($1, $2, $3)`
-	if rowsAffected, err := b.db.Exec(ctx, query, id, follower.UserID, follower.FollowerUserID); err != nil {
-		if r := rowsAffected.RowsAffected(); r == 0 {
-			b.log.Error("no rows affected while inserting follower", logger.Error(err))
-			return "", err
+	if _, err := b.db.Exec(ctx, query, id, follower.UserID, follower.FollowerUserID); err != nil {
+		if isUniqueViolation(err) {
+			err = errors.New("user is already followed")
 		}
 		b.log.Error("error while inserting follower data", logger.Error(err))
 		return "", err
@@ -61,29 +60,25 @@ func (b *followerRepo) GetList(ctx context.Context, req models.GetListRequest) (
 	var (
 		followers = []models.Follower{}
 		count     = 0
-		query     string
-		filter    string
-		page      = req.Page
-		offset    = (page - 1) * req.Limit
+		offset    = (req.Page - 1) * req.Limit
 	)
 
-	if req.UserID != "" {
-		filter += fmt.Sprintf(" AND user_id = '%s'", req.UserID)
-	}
+	filter := ` WHERE ($1 = '' OR user_id = NULLIF($1, '')::uuid)`
 
-	countQuery := `SELECT COUNT(1) FROM followers WHERE TRUE ` + filter
-	if err := b.db.QueryRow(ctx, countQuery).Scan(&count); err != nil {
+	countQuery := `SELECT COUNT(1) FROM followers` + filter
+	if err := b.db.QueryRow(ctx, countQuery, req.UserID).Scan(&count); err != nil {
 		b.log.Error("error while selecting count", logger.Error(err))
 		return models.FollowersResponse{}, err
 	}
 
-	query = `SELECT follower_id, user_id, follower_user_id, created_at FROM followers WHERE TRUE ` + filter
-	query += ` ORDER BY created_at DESC LIMIT $1 OFFSET $2`
-	rows, err := b.db.Query(ctx, query, req.Limit, offset)
+	query := `SELECT follower_id, user_id, follower_user_id, created_at FROM followers` + filter
+	query += ` ORDER BY created_at DESC LIMIT $2 OFFSET $3`
+	rows, err := b.db.Query(ctx, query, req.UserID, req.Limit, offset)
 	if err != nil {
 		b.log.Error("error while selecting followers", logger.Error(err))
 		return models.FollowersResponse{}, err
 	}
+	defer rows.Close()
 
 	for rows.Next() {
 		follower := models.Follower{}
@@ -102,12 +97,137 @@ func (b *followerRepo) GetList(ctx context.Context, req models.GetListRequest) (
 
 func (b *followerRepo) Delete(ctx context.Context, key models.PrimaryKey) error {
 	query := `DELETE FROM followers WHERE follower_id = $1`
-	if rowsAffected, err := b.db.Exec(ctx, query, key.ID); err != nil {
-		if r := rowsAffected.RowsAffected(); r == 0 {
-			b.log.Error("error while deleting follower", logger.Error(err))
-			return err
-		}
+	cmdTag, err := b.db.Exec(ctx, query, key.ID)
+	if err != nil {
+		b.log.Error("error while deleting follower", logger.Error(err))
 		return err
 	}
+
+	if cmdTag.RowsAffected() == 0 {
+		b.log.Error("no rows affected while deleting follower")
+		return fmt.Errorf("no rows affected")
+	}
+
 	return nil
 }
+
+// DeleteByUsers stops follower.FollowerUserID following follower.UserID.
+// Removing a follow that does not exist is not an error.
+func (b *followerRepo) DeleteByUsers(ctx context.Context, follower models.CreateFollower) error {
+	query := `DELETE FROM followers WHERE user_id = $1 AND follower_user_id = $2`
+	if _, err := b.db.Exec(ctx, query, follower.UserID, follower.FollowerUserID); err != nil {
+		b.log.Error("error while deleting follower by users", logger.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// GetFollowers lists the users following req.UserID.
+func (b *followerRepo) GetFollowers(ctx context.Context, req models.GetListRequest) (models.UserSummariesResponse, error) {
+	filter := `
+		WHERE f.user_id = $1
+		AND ` + notBlocked("f.follower_user_id", "$2")
+
+	countQuery := `SELECT COUNT(1) FROM followers f` + filter
+	query := `
+		SELECT ` + userSummaryColumns + `
+		FROM followers f
+		JOIN users u ON u.user_id = f.follower_user_id` + filter + `
+		ORDER BY f.created_at DESC LIMIT $3 OFFSET $4
+	`
+
+	return b.listUsers(ctx, countQuery, query, req)
+}
+
+// GetFollowing lists the users req.UserID follows.
+func (b *followerRepo) GetFollowing(ctx context.Context, req models.GetListRequest) (models.UserSummariesResponse, error) {
+	filter := `
+		WHERE f.follower_user_id = $1
+		AND ` + notBlocked("f.user_id", "$2")
+
+	countQuery := `SELECT COUNT(1) FROM followers f` + filter
+	query := `
+		SELECT ` + userSummaryColumns + `
+		FROM followers f
+		JOIN users u ON u.user_id = f.user_id` + filter + `
+		ORDER BY f.created_at DESC LIMIT $3 OFFSET $4
+	`
+
+	return b.listUsers(ctx, countQuery, query, req)
+}
+
+// GetMutuals lists the users who follow req.UserID and are followed back.
+func (b *followerRepo) GetMutuals(ctx context.Context, req models.GetListRequest) (models.UserSummariesResponse, error) {
+	from := `
+		FROM followers f
+		JOIN followers back ON back.user_id = f.follower_user_id AND back.follower_user_id = f.user_id`
+	filter := `
+		WHERE f.user_id = $1
+		AND ` + notBlocked("f.follower_user_id", "$2")
+
+	countQuery := `SELECT COUNT(1)` + from + filter
+	query := `
+		SELECT ` + userSummaryColumns + from + `
+		JOIN users u ON u.user_id = f.follower_user_id` + filter + `
+		ORDER BY GREATEST(f.created_at, back.created_at) DESC LIMIT $3 OFFSET $4
+	`
+
+	return b.listUsers(ctx, countQuery, query, req)
+}
+
+// GetRelationship describes how req.SourceID relates to req.TargetID. It
+// includes the blocks and mutes of req.SourceID, which are private, so
+// req.SourceID must be the user asking.
+func (b *followerRepo) GetRelationship(ctx context.Context, req models.RelationshipRequest) (models.Relationship, error) {
+	relationship := models.Relationship{
+		SourceID: req.SourceID,
+		TargetID: req.TargetID,
+	}
+
+	query := `
+		SELECT
+			EXISTS (SELECT 1 FROM followers WHERE user_id = $2 AND follower_user_id = $1),
+			EXISTS (SELECT 1 FROM followers WHERE user_id = $1 AND follower_user_id = $2),
+			EXISTS (SELECT 1 FROM blocks WHERE user_id = $1 AND blocked_user_id = $2),
+			EXISTS (SELECT 1 FROM mutes WHERE user_id = $1 AND muted_user_id = $2)
+	`
+	if err := b.db.QueryRow(ctx, query, req.SourceID, req.TargetID).Scan(&relationship.Following, &relationship.FollowedBy, &relationship.Blocking, &relationship.Muting); err != nil {
+		b.log.Error("error while selecting relationship", logger.Error(err))
+		return models.Relationship{}, err
+	}
+
+	return relationship, nil
+}
+
+// listUsers runs a count and a page query that both take req.UserID as $1
+// and req.ViewerID as $2, the page query additionally taking limit and offset
+// as $3 and $4.
+func (b *followerRepo) listUsers(ctx context.Context, countQuery, query string, req models.GetListRequest) (models.UserSummariesResponse, error) {
+	var (
+		count  = 0
+		offset = (req.Page - 1) * req.Limit
+	)
+
+	if err := b.db.QueryRow(ctx, countQuery, req.UserID, req.ViewerID).Scan(&count); err != nil {
+		b.log.Error("error while counting users", logger.Error(err))
+		return models.UserSummariesResponse{}, err
+	}
+
+	rows, err := b.db.Query(ctx, query, req.UserID, req.ViewerID, req.Limit, offset)
+	if err != nil {
+		b.log.Error("error while selecting users", logger.Error(err))
+		return models.UserSummariesResponse{}, err
+	}
+
+	users, err := scanUserSummaries(rows)
+	if err != nil {
+		b.log.Error("error while scanning users", logger.Error(err))
+		return models.UserSummariesResponse{}, err
+	}
+
+	return models.UserSummariesResponse{
+		Users: users,
+		Count: count,
+	}, nil
+}
@@ -0,0 +1,149 @@
+package postgres
+
+import (
+	"errors"
+	"test/api/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const (
+	// avatarURLColumn and bannerURLColumn are the stable urls the avatar and
+	// banner of a user are served from, whatever media they currently are.
+	avatarURLColumn    = `CASE WHEN u.avatar_media_id IS NULL THEN '' ELSE '/user/' || u.user_id || '/avatar' END`
+	bannerURLColumn    = `CASE WHEN u.banner_media_id IS NULL THEN '' ELSE '/user/' || u.user_id || '/banner' END`
+	userSummaryColumns = `u.user_id, u.username, COALESCE(u.name, ''), ` + avatarURLColumn + `, u.protected`
+	tweetColumns       = `t.tweet_id, t.user_id, t.content, t.image_url, t.video_url, t.reply_to_tweet_id, t.conversation_id, t.reply_audience, t.edit_count, t.hidden_at IS NOT NULL, t.created_at, t.updated_at`
+)
+
+// isUniqueViolation reports whether err was caused by a unique constraint.
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+// visibleToViewer returns a condition that holds when the viewer bound to the
+// viewerParam placeholder may see content authored by authorColumn. An empty
+// viewer is anonymous and only sees content of public accounts. Content of
+// suspended accounts is seen by no one.
+func visibleToViewer(authorColumn, viewerParam string) string {
+	viewer := `NULLIF(` + viewerParam + `::text, '')::uuid`
+
+	return `(
+		NOT EXISTS (SELECT 1 FROM users vs WHERE vs.user_id = ` + authorColumn + ` AND vs.suspended_at IS NOT NULL)
+		AND (
+			NOT EXISTS (SELECT 1 FROM users va WHERE va.user_id = ` + authorColumn + ` AND va.protected)
+			OR ` + authorColumn + ` = ` + viewer + `
+			OR EXISTS (SELECT 1 FROM followers vf WHERE vf.user_id = ` + authorColumn + ` AND vf.follower_user_id = ` + viewer + `)
+		)
+		AND ` + notBlocked(authorColumn, viewerParam) + `
+	)`
+}
+
+// tweetVisibleToViewer returns a condition that holds when the viewer bound to
+// viewerParam may see the tweet aliased t: its author is visible to them and
+// moderators have not hidden it, unless the viewer wrote it.
+func tweetVisibleToViewer(viewerParam string) string {
+	viewer := `NULLIF(` + viewerParam + `::text, '')::uuid`
+
+	return `(
+		(t.hidden_at IS NULL OR t.user_id = ` + viewer + `)
+		AND ` + visibleToViewer("t.user_id", viewerParam) + `
+	)`
+}
+
+// replyAllowed returns a condition that holds when the user bound to userParam
+// may reply to the tweet aliased t as its reply audience says. Authors reply
+// to their own tweets, and the people they mention always can. Mentions are
+// those of the current content, as edits replace them.
+func replyAllowed(userParam string) string {
+	user := `NULLIF(` + userParam + `::text, '')::uuid`
+
+	return `(
+		t.user_id = ` + user + `
+		OR t.reply_audience = '` + models.ReplyAudienceEveryone + `'
+		OR EXISTS (SELECT 1 FROM tweet_mentions rm WHERE rm.tweet_id = t.tweet_id AND rm.user_id = ` + user + `)
+		OR (
+			t.reply_audience = '` + models.ReplyAudienceFollowing + `'
+			AND EXISTS (SELECT 1 FROM followers rf WHERE rf.user_id = ` + user + ` AND rf.follower_user_id = t.user_id)
+		)
+	)`
+}
+
+// notBlocked returns a condition that holds unless the user in userColumn and
+// the viewer bound to viewerParam have blocked each other in either direction.
+func notBlocked(userColumn, viewerParam string) string {
+	viewer := `NULLIF(` + viewerParam + `::text, '')::uuid`
+
+	return `NOT EXISTS (
+		SELECT 1 FROM blocks vb
+		WHERE (vb.user_id = ` + userColumn + ` AND vb.blocked_user_id = ` + viewer + `)
+		OR (vb.user_id = ` + viewer + ` AND vb.blocked_user_id = ` + userColumn + `)
+	)`
+}
+
+// notMuted returns a condition that holds unless the viewer bound to
+// viewerParam muted the author in authorColumn or an active keyword found in
+// contentColumn.
+func notMuted(authorColumn, contentColumn, viewerParam string) string {
+	viewer := `NULLIF(` + viewerParam + `::text, '')::uuid`
+
+	return `(
+		NOT EXISTS (SELECT 1 FROM mutes vm WHERE vm.user_id = ` + viewer + ` AND vm.muted_user_id = ` + authorColumn + `)
+		AND NOT EXISTS (
+			SELECT 1 FROM muted_keywords mk
+			WHERE mk.user_id = ` + viewer + `
+			AND (mk.expires_at IS NULL OR mk.expires_at > NOW())
+			AND lower(` + contentColumn + `) ~ ('(^|[^[:alnum:]_])' || mk.pattern || '($|[^[:alnum:]_])')
+		)
+	)`
+}
+
+// notMutedConversation returns a condition that holds unless the viewer bound
+// to viewerParam muted the conversation in conversationColumn.
+func notMutedConversation(conversationColumn, viewerParam string) string {
+	viewer := `NULLIF(` + viewerParam + `::text, '')::uuid`
+
+	return `NOT EXISTS (
+		SELECT 1 FROM muted_conversations mc
+		WHERE mc.user_id = ` + viewer + ` AND mc.conversation_id = ` + conversationColumn + `
+	)`
+}
+
+// scanUserSummaries reads rows selected with userSummaryColumns.
+func scanUserSummaries(rows pgx.Rows) ([]models.UserSummary, error) {
+	defer rows.Close()
+
+	users := []models.UserSummary{}
+	for rows.Next() {
+		user := models.UserSummary{}
+		if err := rows.Scan(&user.ID, &user.Username, &user.Name, &user.ProfilePicture, &user.Protected); err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	return users, rows.Err()
+}
+
+// scanTweet reads a row selected with tweetColumns.
+func scanTweet(row pgx.Row, tweet *models.Tweet) error {
+	return row.Scan(&tweet.ID, &tweet.UserID, &tweet.Content, &tweet.ImageURL, &tweet.VideoURL, &tweet.ReplyToTweetID, &tweet.ConversationID, &tweet.ReplyAudience, &tweet.EditCount, &tweet.Hidden, &tweet.CreatedAt, &tweet.UpdatedAt)
+}
+
+// scanTweets reads rows selected with tweetColumns.
+func scanTweets(rows pgx.Rows) ([]models.Tweet, error) {
+	defer rows.Close()
+
+	tweets := []models.Tweet{}
+	for rows.Next() {
+		tweet := models.Tweet{}
+		if err := scanTweet(rows, &tweet); err != nil {
+			return nil, err
+		}
+		tweets = append(tweets, tweet)
+	}
+
+	return tweets, rows.Err()
+}
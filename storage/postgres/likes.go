@@ -2,12 +2,14 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"test/api/models"
 	"test/pkg/logger"
 	"test/storage"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -24,24 +26,13 @@ func NewLikesRepo(db *pgxpool.Pool, log logger.ILogger) storage.ILikesStorage {
 }
 
 func (l *likeRepo) Create(ctx context.Context, like models.CreateLike) (string, error) {
-	// Check if the like already exists
-	var count int
-	checkQuery := `SELECT COUNT(1) FROM likes WHERE tweet_id = $1 AND user_id = $2`
-	err := l.db.QueryRow(ctx, checkQuery, like.TweetID, like.UserID).Scan(&count)
-	if err != nil {
-		l.log.Error("Error while checking if like exists", logger.Error(err))
-		return "", err
-	}
-	if count > 0 {
-		err := fmt.Errorf("user has already liked this tweet")
-		l.log.Error(err.Error())
-		return "", err
-	}
-
 	id := uuid.New()
 	query := `INSERT INTO likes (like_id, tweet_id, user_id) VALUES ($1, $2, $3)`
 	cmdTag, err := l.db.Exec(ctx, query, id, like.TweetID, like.UserID)
 	if err != nil {
+		if isUniqueViolation(err) {
+			err = fmt.Errorf("user has already liked this tweet")
+		}
 		l.log.Error("Error while inserting like data", logger.Error(err))
 		return "", err
 	}
@@ -55,10 +46,39 @@ func (l *likeRepo) Create(ctx context.Context, like models.CreateLike) (string,
 	return id.String(), nil
 }
 
+// Upsert likes the tweet on behalf of the user and returns the id of the
+// like, whether it was just created or already existed.
+func (l *likeRepo) Upsert(ctx context.Context, like models.CreateLike) (string, error) {
+	var id string
+	query := `
+		INSERT INTO likes (like_id, tweet_id, user_id) VALUES ($1, $2, $3)
+		ON CONFLICT (tweet_id, user_id) DO NOTHING
+		RETURNING like_id
+	`
+	err := l.db.QueryRow(ctx, query, uuid.New(), like.TweetID, like.UserID).Scan(&id)
+	if err == nil {
+		return id, nil
+	}
+
+	if !errors.Is(err, pgx.ErrNoRows) {
+		l.log.Error("Error while upserting like", logger.Error(err))
+		return "", err
+	}
+
+	// already liked, nothing was inserted
+	query = `SELECT like_id FROM likes WHERE tweet_id = $1 AND user_id = $2`
+	if err = l.db.QueryRow(ctx, query, like.TweetID, like.UserID).Scan(&id); err != nil {
+		l.log.Error("Error while selecting existing like", logger.Error(err))
+		return "", err
+	}
+
+	return id, nil
+}
+
 func (l *likeRepo) GetByID(ctx context.Context, likeID models.PrimaryKey) (models.Like, error) {
 	var like models.Like
 	query := `SELECT like_id, tweet_id, user_id, created_at FROM likes WHERE like_id = $1`
-	err := l.db.QueryRow(ctx, query, likeID).Scan(&like.LikeID, &like.TweetID, &like.UserID, &like.CreatedAt)
+	err := l.db.QueryRow(ctx, query, likeID.ID).Scan(&like.LikeID, &like.TweetID, &like.UserID, &like.CreatedAt)
 	if err != nil {
 		l.log.Error("Error while selecting like", logger.Error(err))
 		return models.Like{}, err
@@ -71,7 +91,7 @@ func (l *likeRepo) Delete(ctx context.Context, likeID models.PrimaryKey) error {
 	// Check if the like exists
 	var count int
 	checkQuery := `SELECT COUNT(1) FROM likes WHERE like_id = $1`
-	err := l.db.QueryRow(ctx, checkQuery, likeID).Scan(&count)
+	err := l.db.QueryRow(ctx, checkQuery, likeID.ID).Scan(&count)
 	if err != nil {
 		l.log.Error("Error while checking if like exists", logger.Error(err))
 		return err
@@ -83,7 +103,7 @@ func (l *likeRepo) Delete(ctx context.Context, likeID models.PrimaryKey) error {
 	}
 
 	query := `DELETE FROM likes WHERE like_id = $1`
-	cmdTag, err := l.db.Exec(ctx, query, likeID)
+	cmdTag, err := l.db.Exec(ctx, query, likeID.ID)
 	if err != nil {
 		l.log.Error("Error while deleting like", logger.Error(err))
 		return err
@@ -97,3 +117,97 @@ func (l *likeRepo) Delete(ctx context.Context, likeID models.PrimaryKey) error {
 
 	return nil
 }
+
+// DeleteByTweetAndUser removes the user's like from the tweet. Removing a like
+// that does not exist is not an error.
+func (l *likeRepo) DeleteByTweetAndUser(ctx context.Context, like models.CreateLike) error {
+	query := `DELETE FROM likes WHERE tweet_id = $1 AND user_id = $2`
+	if _, err := l.db.Exec(ctx, query, like.TweetID, like.UserID); err != nil {
+		l.log.Error("Error while deleting like by tweet and user", logger.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// GetLikingUsers lists the users who liked request.TweetID, most recent first.
+func (l *likeRepo) GetLikingUsers(ctx context.Context, request models.GetListRequest) (models.UserSummariesResponse, error) {
+	var (
+		count  = 0
+		offset = (request.Page - 1) * request.Limit
+	)
+
+	filter := `
+		WHERE l.tweet_id = $1
+		AND ` + notBlocked("l.user_id", "$2")
+
+	countQuery := `SELECT COUNT(1) FROM likes l` + filter
+	if err := l.db.QueryRow(ctx, countQuery, request.TweetID, request.ViewerID).Scan(&count); err != nil {
+		l.log.Error("Error while counting liking users", logger.Error(err))
+		return models.UserSummariesResponse{}, err
+	}
+
+	query := `
+		SELECT ` + userSummaryColumns + `
+		FROM likes l
+		JOIN users u ON u.user_id = l.user_id` + filter + `
+		ORDER BY l.created_at DESC LIMIT $3 OFFSET $4
+	`
+	rows, err := l.db.Query(ctx, query, request.TweetID, request.ViewerID, request.Limit, offset)
+	if err != nil {
+		l.log.Error("Error while selecting liking users", logger.Error(err))
+		return models.UserSummariesResponse{}, err
+	}
+
+	users, err := scanUserSummaries(rows)
+	if err != nil {
+		l.log.Error("Error while scanning liking users", logger.Error(err))
+		return models.UserSummariesResponse{}, err
+	}
+
+	return models.UserSummariesResponse{
+		Users: users,
+		Count: count,
+	}, nil
+}
+
+// GetLikedTweets lists the tweets request.UserID liked, most recently liked first.
+func (l *likeRepo) GetLikedTweets(ctx context.Context, request models.GetListRequest) (models.TweetsResponse, error) {
+	var (
+		count  = 0
+		offset = (request.Page - 1) * request.Limit
+	)
+
+	filter := `
+		WHERE l.user_id = $1
+		AND ` + tweetVisibleToViewer("$2")
+
+	countQuery := `SELECT COUNT(1) FROM likes l JOIN tweets t ON t.tweet_id = l.tweet_id` + filter
+	if err := l.db.QueryRow(ctx, countQuery, request.UserID, request.ViewerID).Scan(&count); err != nil {
+		l.log.Error("Error while counting liked tweets", logger.Error(err))
+		return models.TweetsResponse{}, err
+	}
+
+	query := `
+		SELECT ` + tweetColumns + `
+		FROM likes l
+		JOIN tweets t ON t.tweet_id = l.tweet_id` + filter + `
+		ORDER BY l.created_at DESC LIMIT $3 OFFSET $4
+	`
+	rows, err := l.db.Query(ctx, query, request.UserID, request.ViewerID, request.Limit, offset)
+	if err != nil {
+		l.log.Error("Error while selecting liked tweets", logger.Error(err))
+		return models.TweetsResponse{}, err
+	}
+
+	tweets, err := scanTweets(rows)
+	if err != nil {
+		l.log.Error("Error while scanning liked tweets", logger.Error(err))
+		return models.TweetsResponse{}, err
+	}
+
+	return models.TweetsResponse{
+		Tweets: tweets,
+		Count:  count,
+	}, nil
+}
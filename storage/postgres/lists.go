@@ -0,0 +1,334 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"test/api/models"
+	"test/pkg/logger"
+	"test/storage"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type listRepo struct {
+	db  *pgxpool.Pool
+	log logger.ILogger
+}
+
+func NewListsRepo(db *pgxpool.Pool, log logger.ILogger) storage.IListsStorage {
+	return &listRepo{
+		db:  db,
+		log: log,
+	}
+}
+
+// listColumns selects a list aliased l with its counts and whether the viewer
+// bound to viewerParam is subscribed to it.
+func listColumns(viewerParam string) string {
+	return `l.list_id, l.owner_user_id, l.name, l.description, l.private,
+		(SELECT COUNT(1) FROM list_members lm WHERE lm.list_id = l.list_id),
+		(SELECT COUNT(1) FROM list_subscribers ls WHERE ls.list_id = l.list_id),
+		EXISTS (SELECT 1 FROM list_subscribers ls WHERE ls.list_id = l.list_id AND ls.user_id = NULLIF(` + viewerParam + `::text, '')::uuid),
+		l.created_at, l.updated_at`
+}
+
+// listVisibleToViewer returns a condition that holds when the viewer bound to
+// viewerParam may see the list aliased l. Private lists are only visible to
+// their owner.
+func listVisibleToViewer(viewerParam string) string {
+	return `(
+		(NOT l.private OR l.owner_user_id = NULLIF(` + viewerParam + `::text, '')::uuid)
+		AND ` + notBlocked("l.owner_user_id", viewerParam) + `
+	)`
+}
+
+func scanList(row pgx.Row, list *models.List) error {
+	return row.Scan(
+		&list.ListID, &list.OwnerUserID, &list.Name, &list.Description, &list.Private,
+		&list.MemberCount, &list.SubscriberCount, &list.Subscribed, &list.CreatedAt, &list.UpdatedAt,
+	)
+}
+
+func scanLists(rows pgx.Rows) ([]models.List, error) {
+	defer rows.Close()
+
+	lists := []models.List{}
+	for rows.Next() {
+		list := models.List{}
+		if err := scanList(rows, &list); err != nil {
+			return nil, err
+		}
+		lists = append(lists, list)
+	}
+
+	return lists, rows.Err()
+}
+
+func (l *listRepo) Create(ctx context.Context, list models.CreateList) (string, error) {
+	var id string
+	query := `
+		INSERT INTO lists (list_id, owner_user_id, name, description, private) VALUES ($1, $2, $3, $4, $5)
+		RETURNING list_id
+	`
+	if err := l.db.QueryRow(ctx, query, uuid.New(), list.OwnerUserID, list.Name, list.Description, list.Private).Scan(&id); err != nil {
+		l.log.Error("error while inserting list", logger.Error(err))
+		return "", err
+	}
+
+	return id, nil
+}
+
+// GetByID loads the list if viewerID may see it.
+func (l *listRepo) GetByID(ctx context.Context, listID, viewerID string) (models.List, error) {
+	list := models.List{}
+	query := `SELECT ` + listColumns("$2") + ` FROM lists l WHERE l.list_id = $1 AND ` + listVisibleToViewer("$2")
+	if err := scanList(l.db.QueryRow(ctx, query, listID, viewerID), &list); err != nil {
+		l.log.Error("error while selecting list", logger.Error(err))
+		return models.List{}, err
+	}
+
+	return list, nil
+}
+
+// Update changes the list if it belongs to list.OwnerUserID.
+func (l *listRepo) Update(ctx context.Context, list models.UpdateList) error {
+	query := `
+		UPDATE lists SET name = $1, description = $2, private = $3, updated_at = NOW()
+		WHERE list_id = $4 AND owner_user_id = $5
+	`
+	cmdTag, err := l.db.Exec(ctx, query, list.Name, list.Description, list.Private, list.ListID, list.OwnerUserID)
+	if err != nil {
+		l.log.Error("error while updating list", logger.Error(err))
+		return err
+	}
+
+	if cmdTag.RowsAffected() == 0 {
+		l.log.Error("no rows affected while updating list")
+		return fmt.Errorf("no rows affected")
+	}
+
+	return nil
+}
+
+// Delete removes the list if it belongs to ownerID.
+func (l *listRepo) Delete(ctx context.Context, key models.PrimaryKey, ownerID string) error {
+	query := `DELETE FROM lists WHERE list_id = $1 AND owner_user_id = $2`
+	cmdTag, err := l.db.Exec(ctx, query, key.ID, ownerID)
+	if err != nil {
+		l.log.Error("error while deleting list", logger.Error(err))
+		return err
+	}
+
+	if cmdTag.RowsAffected() == 0 {
+		l.log.Error("no rows affected while deleting list")
+		return fmt.Errorf("no rows affected")
+	}
+
+	return nil
+}
+
+// GetUserLists lists the lists owned by req.UserID that req.ViewerID may see,
+// most recent first.
+func (l *listRepo) GetUserLists(ctx context.Context, req models.GetListRequest) (models.ListsResponse, error) {
+	var (
+		count  = 0
+		offset = (req.Page - 1) * req.Limit
+	)
+
+	filter := ` WHERE l.owner_user_id = $1 AND ` + listVisibleToViewer("$2")
+
+	countQuery := `SELECT COUNT(1) FROM lists l` + filter
+	if err := l.db.QueryRow(ctx, countQuery, req.UserID, req.ViewerID).Scan(&count); err != nil {
+		l.log.Error("error while counting user lists", logger.Error(err))
+		return models.ListsResponse{}, err
+	}
+
+	query := `SELECT ` + listColumns("$2") + ` FROM lists l` + filter + `
+		ORDER BY l.created_at DESC LIMIT $3 OFFSET $4
+	`
+	rows, err := l.db.Query(ctx, query, req.UserID, req.ViewerID, req.Limit, offset)
+	if err != nil {
+		l.log.Error("error while selecting user lists", logger.Error(err))
+		return models.ListsResponse{}, err
+	}
+
+	lists, err := scanLists(rows)
+	if err != nil {
+		l.log.Error("error while scanning user lists", logger.Error(err))
+		return models.ListsResponse{}, err
+	}
+
+	return models.ListsResponse{
+		Lists: lists,
+		Count: count,
+	}, nil
+}
+
+// GetSubscribedLists lists the lists req.UserID subscribed to and can still
+// see, most recently subscribed first.
+func (l *listRepo) GetSubscribedLists(ctx context.Context, req models.GetListRequest) (models.ListsResponse, error) {
+	var (
+		count  = 0
+		offset = (req.Page - 1) * req.Limit
+	)
+
+	filter := `
+		FROM list_subscribers s
+		JOIN lists l ON l.list_id = s.list_id
+		WHERE s.user_id = $1 AND ` + listVisibleToViewer("$1")
+
+	countQuery := `SELECT COUNT(1)` + filter
+	if err := l.db.QueryRow(ctx, countQuery, req.UserID).Scan(&count); err != nil {
+		l.log.Error("error while counting subscribed lists", logger.Error(err))
+		return models.ListsResponse{}, err
+	}
+
+	query := `SELECT ` + listColumns("$1") + filter + `
+		ORDER BY s.created_at DESC LIMIT $2 OFFSET $3
+	`
+	rows, err := l.db.Query(ctx, query, req.UserID, req.Limit, offset)
+	if err != nil {
+		l.log.Error("error while selecting subscribed lists", logger.Error(err))
+		return models.ListsResponse{}, err
+	}
+
+	lists, err := scanLists(rows)
+	if err != nil {
+		l.log.Error("error while scanning subscribed lists", logger.Error(err))
+		return models.ListsResponse{}, err
+	}
+
+	return models.ListsResponse{
+		Lists: lists,
+		Count: count,
+	}, nil
+}
+
+// AddMember adds the user to the list, adding a member twice is not an error.
+func (l *listRepo) AddMember(ctx context.Context, member models.ListMember) error {
+	query := `INSERT INTO list_members (list_id, user_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`
+	if _, err := l.db.Exec(ctx, query, member.ListID, member.UserID); err != nil {
+		l.log.Error("error while inserting list member", logger.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// RemoveMember removes the user from the list, removing a user that is not a
+// member is not an error.
+func (l *listRepo) RemoveMember(ctx context.Context, member models.ListMember) error {
+	query := `DELETE FROM list_members WHERE list_id = $1 AND user_id = $2`
+	if _, err := l.db.Exec(ctx, query, member.ListID, member.UserID); err != nil {
+		l.log.Error("error while deleting list member", logger.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// GetMembers lists the members of req.ListID, most recently added first,
+// leaving out users who blocked or were blocked by req.ViewerID.
+func (l *listRepo) GetMembers(ctx context.Context, req models.GetListRequest) (models.UserSummariesResponse, error) {
+	var (
+		count  = 0
+		offset = (req.Page - 1) * req.Limit
+	)
+
+	filter := `
+		FROM list_members m
+		JOIN users u ON u.user_id = m.user_id
+		WHERE m.list_id = $1 AND ` + notBlocked("u.user_id", "$2")
+
+	countQuery := `SELECT COUNT(1)` + filter
+	if err := l.db.QueryRow(ctx, countQuery, req.ListID, req.ViewerID).Scan(&count); err != nil {
+		l.log.Error("error while counting list members", logger.Error(err))
+		return models.UserSummariesResponse{}, err
+	}
+
+	query := `SELECT ` + userSummaryColumns + filter + `
+		ORDER BY m.created_at DESC LIMIT $3 OFFSET $4
+	`
+	rows, err := l.db.Query(ctx, query, req.ListID, req.ViewerID, req.Limit, offset)
+	if err != nil {
+		l.log.Error("error while selecting list members", logger.Error(err))
+		return models.UserSummariesResponse{}, err
+	}
+
+	users, err := scanUserSummaries(rows)
+	if err != nil {
+		l.log.Error("error while scanning list members", logger.Error(err))
+		return models.UserSummariesResponse{}, err
+	}
+
+	return models.UserSummariesResponse{
+		Users: users,
+		Count: count,
+	}, nil
+}
+
+// Subscribe subscribes the user to the list, subscribing twice is not an
+// error.
+func (l *listRepo) Subscribe(ctx context.Context, subscriber models.ListMember) error {
+	query := `INSERT INTO list_subscribers (list_id, user_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`
+	if _, err := l.db.Exec(ctx, query, subscriber.ListID, subscriber.UserID); err != nil {
+		l.log.Error("error while inserting list subscriber", logger.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// Unsubscribe unsubscribes the user from the list, unsubscribing twice is not
+// an error.
+func (l *listRepo) Unsubscribe(ctx context.Context, subscriber models.ListMember) error {
+	query := `DELETE FROM list_subscribers WHERE list_id = $1 AND user_id = $2`
+	if _, err := l.db.Exec(ctx, query, subscriber.ListID, subscriber.UserID); err != nil {
+		l.log.Error("error while deleting list subscriber", logger.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// GetTimeline lists the tweets of the members of req.ListID that req.ViewerID
+// may see and has not muted, most recent first.
+func (l *listRepo) GetTimeline(ctx context.Context, req models.GetListRequest) (models.TweetsResponse, error) {
+	var (
+		count  = 0
+		offset = (req.Page - 1) * req.Limit
+	)
+
+	filter := `
+		WHERE t.user_id IN (SELECT m.user_id FROM list_members m WHERE m.list_id = $1)
+		AND ` + tweetVisibleToViewer("$2") + `
+		AND ` + notMuted("t.user_id", "t.content", "$2")
+
+	countQuery := `SELECT COUNT(1) FROM tweets t` + filter
+	if err := l.db.QueryRow(ctx, countQuery, req.ListID, req.ViewerID).Scan(&count); err != nil {
+		l.log.Error("error while counting list timeline", logger.Error(err))
+		return models.TweetsResponse{}, err
+	}
+
+	query := `SELECT ` + tweetColumns + ` FROM tweets t` + filter + `
+		ORDER BY t.created_at DESC LIMIT $3 OFFSET $4
+	`
+	rows, err := l.db.Query(ctx, query, req.ListID, req.ViewerID, req.Limit, offset)
+	if err != nil {
+		l.log.Error("error while selecting list timeline", logger.Error(err))
+		return models.TweetsResponse{}, err
+	}
+
+	tweets, err := scanTweets(rows)
+	if err != nil {
+		l.log.Error("error while scanning list timeline", logger.Error(err))
+		return models.TweetsResponse{}, err
+	}
+
+	return models.TweetsResponse{
+		Tweets: tweets,
+		Count:  count,
+	}, nil
+}
@@ -0,0 +1,494 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"test/api/models"
+	"test/pkg/logger"
+	"test/storage"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const mediaColumns = `m.media_id, m.user_id, m.tweet_id, m.purpose, m.type, m.mime_type, m.size, m.received_size, m.state, COALESCE(m.url, ''), COALESCE(m.alt_text, ''),
+	COALESCE(m.width, 0), COALESCE(m.height, 0), COALESCE(m.blurhash, ''), m.attempts, COALESCE(m.storage_key, ''), m.created_at, m.updated_at`
+
+type mediaRepo struct {
+	db  *pgxpool.Pool
+	log logger.ILogger
+}
+
+func NewMediaRepo(db *pgxpool.Pool, log logger.ILogger) storage.IMediaStorage {
+	return &mediaRepo{
+		db:  db,
+		log: log,
+	}
+}
+
+func scanMedia(row pgx.Row, media *models.Media) error {
+	return row.Scan(
+		&media.ID, &media.UserID, &media.TweetID, &media.Purpose, &media.Type, &media.MimeType, &media.Size, &media.ReceivedSize,
+		&media.State, &media.URL, &media.AltText, &media.Width, &media.Height, &media.Blurhash, &media.Attempts, &media.StorageKey,
+		&media.CreatedAt, &media.UpdatedAt,
+	)
+}
+
+func scanMediaRows(rows pgx.Rows) ([]models.Media, error) {
+	defer rows.Close()
+
+	media := []models.Media{}
+	for rows.Next() {
+		item := models.Media{}
+		if err := scanMedia(rows, &item); err != nil {
+			return nil, err
+		}
+		media = append(media, item)
+	}
+
+	return media, rows.Err()
+}
+
+// Create records media about to be uploaded.
+func (m *mediaRepo) Create(ctx context.Context, media models.CreateMedia) (string, error) {
+	var id string
+	query := `
+		INSERT INTO media (user_id, purpose, type, mime_type, size, state)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING media_id
+	`
+	if err := m.db.QueryRow(ctx, query, media.UserID, media.Purpose, media.Type, media.MimeType, media.Size, models.MediaStateUploading).Scan(&id); err != nil {
+		m.log.Error("error while inserting media", logger.Error(err))
+		return "", err
+	}
+
+	return id, nil
+}
+
+func (m *mediaRepo) GetByID(ctx context.Context, key models.PrimaryKey) (models.Media, error) {
+	media := models.Media{}
+	query := `SELECT ` + mediaColumns + ` FROM media m WHERE m.media_id = $1`
+	if err := scanMedia(m.db.QueryRow(ctx, query, key.ID), &media); err != nil {
+		m.log.Error("error while selecting media", logger.Error(err))
+		return models.Media{}, err
+	}
+
+	items := []models.Media{media}
+	if err := m.loadVariants(ctx, items); err != nil {
+		return models.Media{}, err
+	}
+
+	return items[0], nil
+}
+
+func (m *mediaRepo) GetByIDs(ctx context.Context, ids []string) ([]models.Media, error) {
+	query := `SELECT ` + mediaColumns + ` FROM media m WHERE m.media_id = ANY($1::uuid[])`
+	rows, err := m.db.Query(ctx, query, ids)
+	if err != nil {
+		m.log.Error("error while selecting media by ids", logger.Error(err))
+		return nil, err
+	}
+
+	media, err := scanMediaRows(rows)
+	if err != nil {
+		m.log.Error("error while scanning media", logger.Error(err))
+		return nil, err
+	}
+
+	if err = m.loadVariants(ctx, media); err != nil {
+		return nil, err
+	}
+
+	return media, nil
+}
+
+// GetByTweetIDs returns the media attached to the tweets, in the order they
+// were attached.
+func (m *mediaRepo) GetByTweetIDs(ctx context.Context, tweetIDs []string) ([]models.Media, error) {
+	query := `SELECT ` + mediaColumns + ` FROM media m WHERE m.tweet_id = ANY($1::uuid[]) ORDER BY m.tweet_id, m.position`
+	rows, err := m.db.Query(ctx, query, tweetIDs)
+	if err != nil {
+		m.log.Error("error while selecting tweet media", logger.Error(err))
+		return nil, err
+	}
+
+	media, err := scanMediaRows(rows)
+	if err != nil {
+		m.log.Error("error while scanning tweet media", logger.Error(err))
+		return nil, err
+	}
+
+	if err = m.loadVariants(ctx, media); err != nil {
+		return nil, err
+	}
+
+	return media, nil
+}
+
+// AddChunk records an uploaded chunk of the user's media. The chunk must
+// start where the upload stopped and fit in the declared size, otherwise
+// pgx.ErrNoRows is returned, so concurrent uploads of the same chunk cannot
+// both be recorded.
+func (m *mediaRepo) AddChunk(ctx context.Context, chunk models.MediaChunk) error {
+	tx, err := m.db.Begin(ctx)
+	if err != nil {
+		m.log.Error("error while beginning media chunk transaction", logger.Error(err))
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		UPDATE media
+		SET received_size = received_size + $4, updated_at = NOW()
+		WHERE media_id = $1 AND user_id = $2 AND state = $5
+		AND received_size = $3 AND received_size + $4 <= size
+	`
+	cmdTag, err := tx.Exec(ctx, query, chunk.MediaID, chunk.UserID, chunk.Offset, chunk.Size, models.MediaStateUploading)
+	if err != nil {
+		m.log.Error("error while updating received media size", logger.Error(err))
+		return err
+	}
+
+	if cmdTag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+
+	query = `INSERT INTO media_chunks (media_id, start_offset, size, storage_key) VALUES ($1, $2, $3, $4)`
+	if _, err = tx.Exec(ctx, query, chunk.MediaID, chunk.Offset, chunk.Size, chunk.StorageKey); err != nil {
+		m.log.Error("error while inserting media chunk", logger.Error(err))
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		m.log.Error("error while committing media chunk transaction", logger.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// GetChunks returns the uploaded chunks of the media in order.
+func (m *mediaRepo) GetChunks(ctx context.Context, mediaID string) ([]models.MediaChunk, error) {
+	query := `SELECT media_id, start_offset, size, storage_key FROM media_chunks WHERE media_id = $1 ORDER BY start_offset`
+	rows, err := m.db.Query(ctx, query, mediaID)
+	if err != nil {
+		m.log.Error("error while selecting media chunks", logger.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	chunks := []models.MediaChunk{}
+	for rows.Next() {
+		chunk := models.MediaChunk{}
+		if err = rows.Scan(&chunk.MediaID, &chunk.Offset, &chunk.Size, &chunk.StorageKey); err != nil {
+			m.log.Error("error while scanning media chunk", logger.Error(err))
+			return nil, err
+		}
+		chunks = append(chunks, chunk)
+	}
+
+	return chunks, rows.Err()
+}
+
+// Complete records where the fully uploaded media is stored, moves it to the
+// given state and forgets its chunks. Media moved to processing is processed
+// right away.
+func (m *mediaRepo) Complete(ctx context.Context, media models.CompleteMedia) error {
+	tx, err := m.db.Begin(ctx)
+	if err != nil {
+		m.log.Error("error while beginning media completion transaction", logger.Error(err))
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		UPDATE media
+		SET mime_type = $2, storage_key = $3, url = NULLIF($4, ''), received_size = size, state = $5::text,
+			next_attempt_at = CASE WHEN $5::text = $7::text THEN NOW() END, updated_at = NOW()
+		WHERE media_id = $1 AND state = $6
+	`
+	cmdTag, err := tx.Exec(ctx, query, media.ID, media.MimeType, media.StorageKey, media.URL, media.State, models.MediaStateUploading, models.MediaStateProcessing)
+	if err != nil {
+		m.log.Error("error while completing media", logger.Error(err))
+		return err
+	}
+
+	if cmdTag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+
+	if _, err = tx.Exec(ctx, `DELETE FROM media_chunks WHERE media_id = $1`, media.ID); err != nil {
+		m.log.Error("error while deleting media chunks", logger.Error(err))
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		m.log.Error("error while committing media completion transaction", logger.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// UpdateAltText sets the alt text of the user's media, removing it when
+// empty.
+func (m *mediaRepo) UpdateAltText(ctx context.Context, request models.UpdateMediaAltText) error {
+	query := `UPDATE media SET alt_text = NULLIF($3, ''), updated_at = NOW() WHERE media_id = $1 AND user_id = $2`
+	cmdTag, err := m.db.Exec(ctx, query, request.MediaID, request.UserID, request.AltText)
+	if err != nil {
+		m.log.Error("error while updating media alt text", logger.Error(err))
+		return err
+	}
+
+	if cmdTag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+
+	return nil
+}
+
+// ClaimProcessing hands out up to limit images due for processing, for lease
+// before they are handed out again should their processing not finish. Each
+// claim counts as an attempt.
+func (m *mediaRepo) ClaimProcessing(ctx context.Context, limit int, lease time.Duration) ([]models.Media, error) {
+	query := `
+		UPDATE media m
+		SET attempts = m.attempts + 1, next_attempt_at = NOW() + $3::float8 * INTERVAL '1 second', updated_at = NOW()
+		WHERE m.media_id IN (
+			SELECT media_id FROM media
+			WHERE state = $1 AND next_attempt_at <= NOW()
+			ORDER BY next_attempt_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + mediaColumns
+	rows, err := m.db.Query(ctx, query, models.MediaStateProcessing, limit, lease.Seconds())
+	if err != nil {
+		m.log.Error("error while claiming media to process", logger.Error(err))
+		return nil, err
+	}
+
+	media, err := scanMediaRows(rows)
+	if err != nil {
+		m.log.Error("error while scanning media to process", logger.Error(err))
+		return nil, err
+	}
+
+	return media, nil
+}
+
+// FinishProcessing replaces the content of the processed image with the
+// result and makes it ready. Avatars and banners are set on the profile of
+// their owner, returning the id of the media they replace, if any.
+func (m *mediaRepo) FinishProcessing(ctx context.Context, media models.ProcessedMedia) (string, error) {
+	tx, err := m.db.Begin(ctx)
+	if err != nil {
+		m.log.Error("error while beginning media processing transaction", logger.Error(err))
+		return "", err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		UPDATE media
+		SET mime_type = $2, size = $3, received_size = $3, storage_key = $4, url = $5, width = $6, height = $7, blurhash = $8,
+			state = $9, next_attempt_at = NULL, last_error = NULL, updated_at = NOW()
+		WHERE media_id = $1 AND state = $10
+	`
+	cmdTag, err := tx.Exec(ctx, query,
+		media.ID, media.MimeType, media.Size, media.StorageKey, media.URL, media.Width, media.Height, media.Blurhash,
+		models.MediaStateReady, models.MediaStateProcessing,
+	)
+	if err != nil {
+		m.log.Error("error while updating processed media", logger.Error(err))
+		return "", err
+	}
+
+	if cmdTag.RowsAffected() == 0 {
+		return "", pgx.ErrNoRows
+	}
+
+	if _, err = tx.Exec(ctx, `DELETE FROM media_variants WHERE media_id = $1`, media.ID); err != nil {
+		m.log.Error("error while deleting media variants", logger.Error(err))
+		return "", err
+	}
+
+	query = `INSERT INTO media_variants (media_id, name, width, height, storage_key, url) VALUES ($1, $2, $3, $4, $5, $6)`
+	for _, variant := range media.Variants {
+		if _, err = tx.Exec(ctx, query, media.ID, variant.Name, variant.Width, variant.Height, variant.StorageKey, variant.URL); err != nil {
+			m.log.Error("error while inserting media variant", logger.Error(err))
+			return "", err
+		}
+	}
+
+	var replacedID string
+	if media.Purpose != models.MediaPurposeTweet {
+		mediaID := media.ID
+		if replacedID, err = setProfileMedia(ctx, tx, media.UserID, media.Purpose, &mediaID); err != nil {
+			m.log.Error("error while setting profile media", logger.Error(err))
+			return "", err
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		m.log.Error("error while committing media processing transaction", logger.Error(err))
+		return "", err
+	}
+
+	return replacedID, nil
+}
+
+// RetryProcessing records why processing the image failed and when to try
+// again.
+func (m *mediaRepo) RetryProcessing(ctx context.Context, mediaID, reason string, retryAt time.Time) error {
+	query := `UPDATE media SET next_attempt_at = $3, last_error = $2, updated_at = NOW() WHERE media_id = $1 AND state = $4`
+	if _, err := m.db.Exec(ctx, query, mediaID, reason, retryAt, models.MediaStateProcessing); err != nil {
+		m.log.Error("error while scheduling media processing retry", logger.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// Fail marks the media failed for the reason given.
+func (m *mediaRepo) Fail(ctx context.Context, mediaID, reason string) error {
+	query := `UPDATE media SET state = $2, last_error = $3, next_attempt_at = NULL, updated_at = NOW() WHERE media_id = $1`
+	if _, err := m.db.Exec(ctx, query, mediaID, models.MediaStateFailed, reason); err != nil {
+		m.log.Error("error while marking media failed", logger.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// SetProfileMedia sets or, when mediaID is nil, removes the avatar or banner
+// of the user, returning the id of the media it replaces, if any.
+func (m *mediaRepo) SetProfileMedia(ctx context.Context, userID, purpose string, mediaID *string) (string, error) {
+	replacedID, err := setProfileMedia(ctx, m.db, userID, purpose, mediaID)
+	if err != nil {
+		m.log.Error("error while setting profile media", logger.Error(err))
+		return "", err
+	}
+
+	return replacedID, nil
+}
+
+// GetProfileMedia returns the current avatar or banner of the user.
+func (m *mediaRepo) GetProfileMedia(ctx context.Context, userID, purpose string) (models.Media, error) {
+	column, err := profileMediaColumn(purpose)
+	if err != nil {
+		return models.Media{}, err
+	}
+
+	media := models.Media{}
+	query := `SELECT ` + mediaColumns + ` FROM users u JOIN media m ON m.media_id = u.` + column + ` WHERE u.user_id = $1`
+	if err = scanMedia(m.db.QueryRow(ctx, query, userID), &media); err != nil {
+		m.log.Error("error while selecting profile media", logger.Error(err))
+		return models.Media{}, err
+	}
+
+	items := []models.Media{media}
+	if err = m.loadVariants(ctx, items); err != nil {
+		return models.Media{}, err
+	}
+
+	return items[0], nil
+}
+
+// Delete deletes the media with its variants, leaving its files to the
+// caller.
+func (m *mediaRepo) Delete(ctx context.Context, key models.PrimaryKey) error {
+	if _, err := m.db.Exec(ctx, `DELETE FROM media WHERE media_id = $1`, key.ID); err != nil {
+		m.log.Error("error while deleting media", logger.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+type rowQuerier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+func profileMediaColumn(purpose string) (string, error) {
+	switch purpose {
+	case models.MediaPurposeAvatar:
+		return "avatar_media_id", nil
+	case models.MediaPurposeBanner:
+		return "banner_media_id", nil
+	default:
+		return "", fmt.Errorf("media purpose %q is not a profile media", purpose)
+	}
+}
+
+func setProfileMedia(ctx context.Context, db rowQuerier, userID, purpose string, mediaID *string) (string, error) {
+	column, err := profileMediaColumn(purpose)
+	if err != nil {
+		return "", err
+	}
+
+	var replacedID *string
+	query := `
+		UPDATE users u SET ` + column + ` = $2, updated_at = NOW()
+		FROM (SELECT user_id, ` + column + ` AS replaced_id FROM users WHERE user_id = $1 FOR UPDATE) p
+		WHERE u.user_id = p.user_id
+		RETURNING p.replaced_id
+	`
+	if err = db.QueryRow(ctx, query, userID, mediaID).Scan(&replacedID); err != nil {
+		return "", err
+	}
+
+	if replacedID == nil || (mediaID != nil && *replacedID == *mediaID) {
+		return "", nil
+	}
+
+	return *replacedID, nil
+}
+
+// loadVariants fills in the variants of the media, smallest first.
+func (m *mediaRepo) loadVariants(ctx context.Context, media []models.Media) error {
+	if len(media) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(media))
+	for _, item := range media {
+		ids = append(ids, item.ID)
+	}
+
+	query := `
+		SELECT media_id, name, width, height, storage_key, url
+		FROM media_variants
+		WHERE media_id = ANY($1::uuid[])
+		ORDER BY media_id, width * height
+	`
+	rows, err := m.db.Query(ctx, query, ids)
+	if err != nil {
+		m.log.Error("error while selecting media variants", logger.Error(err))
+		return err
+	}
+	defer rows.Close()
+
+	variants := make(map[string][]models.MediaVariant, len(media))
+	for rows.Next() {
+		var (
+			mediaID string
+			variant models.MediaVariant
+		)
+		if err = rows.Scan(&mediaID, &variant.Name, &variant.Width, &variant.Height, &variant.StorageKey, &variant.URL); err != nil {
+			m.log.Error("error while scanning media variant", logger.Error(err))
+			return err
+		}
+		variants[mediaID] = append(variants[mediaID], variant)
+	}
+	if err = rows.Err(); err != nil {
+		m.log.Error("error while reading media variants", logger.Error(err))
+		return err
+	}
+
+	for i := range media {
+		media[i].Variants = variants[media[i].ID]
+	}
+
+	return nil
+}
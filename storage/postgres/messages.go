@@ -0,0 +1,427 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"test/api/models"
+	"test/pkg/logger"
+	"test/storage"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// conversationsQuery selects the conversations the member bound to $1 has not
+// left, with the member's unread count and the latest message.
+const conversationsQuery = `
+	SELECT c.conversation_id, c.is_group, c.name, c.created_at, c.updated_at,
+		(
+			SELECT COUNT(1) FROM dm_messages msg
+			WHERE msg.conversation_id = c.conversation_id
+			AND msg.sender_user_id IS DISTINCT FROM me.user_id
+			AND msg.created_at > COALESCE(me.last_read_at, '-infinity')
+		),
+		lm.message_id, lm.sender_user_id, lm.content, lm.created_at
+	FROM dm_members me
+	JOIN dm_conversations c ON c.conversation_id = me.conversation_id
+	LEFT JOIN LATERAL (
+		SELECT message_id, sender_user_id, content, created_at
+		FROM dm_messages
+		WHERE conversation_id = c.conversation_id
+		ORDER BY created_at DESC LIMIT 1
+	) lm ON true
+	WHERE me.user_id = $1 AND me.left_at IS NULL
+`
+
+type messageRepo struct {
+	db  *pgxpool.Pool
+	log logger.ILogger
+}
+
+func NewMessagesRepo(db *pgxpool.Pool, log logger.ILogger) storage.IMessagesStorage {
+	return &messageRepo{
+		db:  db,
+		log: log,
+	}
+}
+
+// CreateConversation starts a conversation between the creator and the users.
+// Conversations with a DirectKey are one to one, creating one again returns
+// the existing conversation.
+func (m *messageRepo) CreateConversation(ctx context.Context, conversation models.CreateConversation) (string, error) {
+	tx, err := m.db.Begin(ctx)
+	if err != nil {
+		m.log.Error("error while beginning conversation transaction", logger.Error(err))
+		return "", err
+	}
+	defer tx.Rollback(ctx)
+
+	var (
+		id        string
+		directKey *string
+	)
+	if conversation.DirectKey != "" {
+		directKey = &conversation.DirectKey
+	}
+
+	query := `
+		INSERT INTO dm_conversations (conversation_id, is_group, name, direct_key, created_by)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (direct_key) DO NOTHING
+		RETURNING conversation_id
+	`
+	switch err = tx.QueryRow(ctx, query, uuid.New(), directKey == nil, conversation.Name, directKey, conversation.CreatorID).Scan(&id); {
+	case errors.Is(err, pgx.ErrNoRows):
+		// the direct conversation already exists, nothing was inserted
+		query = `SELECT conversation_id FROM dm_conversations WHERE direct_key = $1`
+		if err = tx.QueryRow(ctx, query, directKey).Scan(&id); err != nil {
+			m.log.Error("error while selecting existing conversation", logger.Error(err))
+			return "", err
+		}
+	case err != nil:
+		m.log.Error("error while inserting conversation", logger.Error(err))
+		return "", err
+	}
+
+	query = `
+		INSERT INTO dm_members (conversation_id, user_id)
+		SELECT $1, unnest($2::uuid[])
+		ON CONFLICT (conversation_id, user_id) DO NOTHING
+	`
+	memberIDs := append([]string{conversation.CreatorID}, conversation.UserIDs...)
+	if _, err = tx.Exec(ctx, query, id, memberIDs); err != nil {
+		m.log.Error("error while inserting conversation members", logger.Error(err))
+		return "", err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		m.log.Error("error while committing conversation transaction", logger.Error(err))
+		return "", err
+	}
+
+	return id, nil
+}
+
+// GetConversation loads the conversation as seen by userID, who must be a
+// member that has not left.
+func (m *messageRepo) GetConversation(ctx context.Context, conversationID, userID string) (models.Conversation, error) {
+	rows, err := m.db.Query(ctx, conversationsQuery+` AND c.conversation_id = $2`, userID, conversationID)
+	if err != nil {
+		m.log.Error("error while selecting conversation", logger.Error(err))
+		return models.Conversation{}, err
+	}
+
+	conversations, err := m.scanConversations(ctx, rows)
+	if err != nil {
+		return models.Conversation{}, err
+	}
+
+	if len(conversations) == 0 {
+		return models.Conversation{}, pgx.ErrNoRows
+	}
+
+	return conversations[0], nil
+}
+
+// GetConversations lists the conversations of req.UserID, most recently active
+// first.
+func (m *messageRepo) GetConversations(ctx context.Context, req models.GetListRequest) (models.ConversationsResponse, error) {
+	var (
+		count  = 0
+		offset = (req.Page - 1) * req.Limit
+	)
+
+	countQuery := `SELECT COUNT(1) FROM dm_members WHERE user_id = $1 AND left_at IS NULL`
+	if err := m.db.QueryRow(ctx, countQuery, req.UserID).Scan(&count); err != nil {
+		m.log.Error("error while counting conversations", logger.Error(err))
+		return models.ConversationsResponse{}, err
+	}
+
+	rows, err := m.db.Query(ctx, conversationsQuery+` ORDER BY c.updated_at DESC LIMIT $2 OFFSET $3`, req.UserID, req.Limit, offset)
+	if err != nil {
+		m.log.Error("error while selecting conversations", logger.Error(err))
+		return models.ConversationsResponse{}, err
+	}
+
+	conversations, err := m.scanConversations(ctx, rows)
+	if err != nil {
+		return models.ConversationsResponse{}, err
+	}
+
+	return models.ConversationsResponse{
+		Conversations: conversations,
+		Count:         count,
+	}, nil
+}
+
+// GetMemberIDs lists the members of the conversation that have not left.
+func (m *messageRepo) GetMemberIDs(ctx context.Context, conversationID string) ([]string, error) {
+	userIDs := []string{}
+	query := `SELECT user_id FROM dm_members WHERE conversation_id = $1 AND left_at IS NULL`
+	rows, err := m.db.Query(ctx, query, conversationID)
+	if err != nil {
+		m.log.Error("error while selecting conversation members", logger.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var userID string
+		if err = rows.Scan(&userID); err != nil {
+			m.log.Error("error while scanning conversation member", logger.Error(err))
+			return nil, err
+		}
+		userIDs = append(userIDs, userID)
+	}
+
+	return userIDs, nil
+}
+
+// CanMessage reports whether senderID may message userID under userID's
+// privacy settings.
+func (m *messageRepo) CanMessage(ctx context.Context, userID, senderID string) (bool, error) {
+	var allowed bool
+	query := `
+		SELECT NOT u.dm_from_following_only
+			OR EXISTS (SELECT 1 FROM followers WHERE user_id = $2 AND follower_user_id = u.user_id)
+		FROM users u
+		WHERE u.user_id = $1
+	`
+	if err := m.db.QueryRow(ctx, query, userID, senderID).Scan(&allowed); err != nil {
+		m.log.Error("error while checking message privacy", logger.Error(err))
+		return false, err
+	}
+
+	return allowed, nil
+}
+
+// CreateMessage sends the message, which also counts as the sender having read
+// the conversation.
+func (m *messageRepo) CreateMessage(ctx context.Context, message models.CreateMessage) (string, error) {
+	tx, err := m.db.Begin(ctx)
+	if err != nil {
+		m.log.Error("error while beginning message transaction", logger.Error(err))
+		return "", err
+	}
+	defer tx.Rollback(ctx)
+
+	var (
+		id        = uuid.New()
+		createdAt time.Time
+	)
+
+	query := `
+		INSERT INTO dm_messages (message_id, conversation_id, sender_user_id, content) VALUES ($1, $2, $3, $4)
+		RETURNING created_at
+	`
+	if err = tx.QueryRow(ctx, query, id, message.ConversationID, message.SenderUserID, message.Content).Scan(&createdAt); err != nil {
+		m.log.Error("error while inserting message", logger.Error(err))
+		return "", err
+	}
+
+	query = `UPDATE dm_conversations SET updated_at = $2 WHERE conversation_id = $1`
+	if _, err = tx.Exec(ctx, query, message.ConversationID, createdAt); err != nil {
+		m.log.Error("error while updating conversation activity", logger.Error(err))
+		return "", err
+	}
+
+	query = `UPDATE dm_members SET last_read_at = $3 WHERE conversation_id = $1 AND user_id = $2`
+	if _, err = tx.Exec(ctx, query, message.ConversationID, message.SenderUserID, createdAt); err != nil {
+		m.log.Error("error while updating sender read receipt", logger.Error(err))
+		return "", err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		m.log.Error("error while committing message transaction", logger.Error(err))
+		return "", err
+	}
+
+	return id.String(), nil
+}
+
+func (m *messageRepo) GetMessageByID(ctx context.Context, key models.PrimaryKey) (models.Message, error) {
+	message := models.Message{}
+	query := `SELECT ` + messageColumns + ` FROM dm_messages msg WHERE msg.message_id = $1`
+	if err := m.db.QueryRow(ctx, query, key.ID).Scan(
+		&message.MessageID, &message.ConversationID, &message.SenderUserID, &message.Content, &message.CreatedAt, &message.ReadBy,
+	); err != nil {
+		m.log.Error("error while selecting message", logger.Error(err))
+		return models.Message{}, err
+	}
+
+	return message, nil
+}
+
+// GetMessages pages through the messages of the conversation, newest first,
+// with the members that have read each of them.
+func (m *messageRepo) GetMessages(ctx context.Context, req models.GetMessagesRequest) (models.MessagesResponse, error) {
+	var (
+		messages = []models.Message{}
+		count    = 0
+		offset   = (req.Page - 1) * req.Limit
+	)
+
+	countQuery := `SELECT COUNT(1) FROM dm_messages WHERE conversation_id = $1`
+	if err := m.db.QueryRow(ctx, countQuery, req.ConversationID).Scan(&count); err != nil {
+		m.log.Error("error while counting messages", logger.Error(err))
+		return models.MessagesResponse{}, err
+	}
+
+	query := `
+		SELECT ` + messageColumns + `
+		FROM dm_messages msg
+		WHERE msg.conversation_id = $1
+		ORDER BY msg.created_at DESC LIMIT $2 OFFSET $3
+	`
+	rows, err := m.db.Query(ctx, query, req.ConversationID, req.Limit, offset)
+	if err != nil {
+		m.log.Error("error while selecting messages", logger.Error(err))
+		return models.MessagesResponse{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		message := models.Message{}
+		if err = rows.Scan(
+			&message.MessageID, &message.ConversationID, &message.SenderUserID, &message.Content, &message.CreatedAt, &message.ReadBy,
+		); err != nil {
+			m.log.Error("error while scanning message", logger.Error(err))
+			return models.MessagesResponse{}, err
+		}
+		messages = append(messages, message)
+	}
+
+	return models.MessagesResponse{
+		Messages: messages,
+		Count:    count,
+	}, nil
+}
+
+// MarkRead marks every message of the conversation as read by userID.
+func (m *messageRepo) MarkRead(ctx context.Context, conversationID, userID string) error {
+	query := `UPDATE dm_members SET last_read_at = NOW() WHERE conversation_id = $1 AND user_id = $2 AND left_at IS NULL`
+	if _, err := m.db.Exec(ctx, query, conversationID, userID); err != nil {
+		m.log.Error("error while marking conversation read", logger.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// Leave removes userID from the group conversation.
+func (m *messageRepo) Leave(ctx context.Context, conversationID, userID string) error {
+	query := `
+		UPDATE dm_members SET left_at = NOW()
+		WHERE conversation_id = $1 AND user_id = $2 AND left_at IS NULL
+		AND EXISTS (SELECT 1 FROM dm_conversations WHERE conversation_id = $1 AND is_group)
+	`
+	cmdTag, err := m.db.Exec(ctx, query, conversationID, userID)
+	if err != nil {
+		m.log.Error("error while leaving conversation", logger.Error(err))
+		return err
+	}
+
+	if cmdTag.RowsAffected() == 0 {
+		m.log.Error("no rows affected while leaving conversation")
+		return fmt.Errorf("no rows affected")
+	}
+
+	return nil
+}
+
+// messageColumns selects a message aliased msg along with the members other
+// than the sender that have read it.
+const messageColumns = `
+	msg.message_id, msg.conversation_id, msg.sender_user_id, msg.content, msg.created_at,
+	ARRAY(
+		SELECT dm.user_id::text FROM dm_members dm
+		WHERE dm.conversation_id = msg.conversation_id
+		AND dm.user_id IS DISTINCT FROM msg.sender_user_id
+		AND dm.last_read_at >= msg.created_at
+	)
+`
+
+// scanConversations scans rows selected by conversationsQuery and loads the
+// members of every conversation.
+func (m *messageRepo) scanConversations(ctx context.Context, rows pgx.Rows) ([]models.Conversation, error) {
+	defer rows.Close()
+
+	var (
+		conversations   = []models.Conversation{}
+		conversationIDs = []string{}
+	)
+	for rows.Next() {
+		var (
+			conversation  = models.Conversation{}
+			messageID     *string
+			senderUserID  *string
+			content       *string
+			lastMessageAt *time.Time
+		)
+		if err := rows.Scan(
+			&conversation.ConversationID, &conversation.IsGroup, &conversation.Name, &conversation.CreatedAt, &conversation.UpdatedAt,
+			&conversation.UnreadCount, &messageID, &senderUserID, &content, &lastMessageAt,
+		); err != nil {
+			m.log.Error("error while scanning conversation", logger.Error(err))
+			return nil, err
+		}
+
+		if messageID != nil {
+			conversation.LastMessage = &models.Message{
+				MessageID:      *messageID,
+				ConversationID: conversation.ConversationID,
+				SenderUserID:   senderUserID,
+				Content:        *content,
+				ReadBy:         []string{},
+				CreatedAt:      *lastMessageAt,
+			}
+		}
+		conversation.Members = []models.UserSummary{}
+
+		conversations = append(conversations, conversation)
+		conversationIDs = append(conversationIDs, conversation.ConversationID)
+	}
+	rows.Close()
+
+	if len(conversationIDs) == 0 {
+		return conversations, nil
+	}
+
+	query := `
+		SELECT dm.conversation_id, ` + userSummaryColumns + `
+		FROM dm_members dm
+		JOIN users u ON u.user_id = dm.user_id
+		WHERE dm.conversation_id = ANY($1::uuid[]) AND dm.left_at IS NULL
+		ORDER BY dm.joined_at
+	`
+	memberRows, err := m.db.Query(ctx, query, conversationIDs)
+	if err != nil {
+		m.log.Error("error while selecting conversation members", logger.Error(err))
+		return nil, err
+	}
+	defer memberRows.Close()
+
+	members := make(map[string][]models.UserSummary, len(conversationIDs))
+	for memberRows.Next() {
+		var (
+			conversationID string
+			user           models.UserSummary
+		)
+		if err = memberRows.Scan(&conversationID, &user.ID, &user.Username, &user.Name, &user.ProfilePicture, &user.Protected); err != nil {
+			m.log.Error("error while scanning conversation member", logger.Error(err))
+			return nil, err
+		}
+		members[conversationID] = append(members[conversationID], user)
+	}
+
+	for i := range conversations {
+		if users, ok := members[conversations[i].ConversationID]; ok {
+			conversations[i].Members = users
+		}
+	}
+
+	return conversations, nil
+}
@@ -0,0 +1,286 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"test/api/models"
+	"test/pkg/logger"
+	"test/storage"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type muteRepo struct {
+	db  *pgxpool.Pool
+	log logger.ILogger
+}
+
+func NewMutesRepo(db *pgxpool.Pool, log logger.ILogger) storage.IMutesStorage {
+	return &muteRepo{
+		db:  db,
+		log: log,
+	}
+}
+
+// CreateUserMute mutes the user. Muting an already muted user returns the
+// existing mute.
+func (m *muteRepo) CreateUserMute(ctx context.Context, mute models.CreateMute) (string, error) {
+	if mute.UserID == mute.MutedUserID {
+		err := errors.New("users cannot mute themselves")
+		m.log.Error("validation error", logger.Error(err))
+		return "", err
+	}
+
+	var id string
+	query := `
+		INSERT INTO mutes (mute_id, user_id, muted_user_id) VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, muted_user_id) DO NOTHING
+		RETURNING mute_id
+	`
+	err := m.db.QueryRow(ctx, query, uuid.New(), mute.UserID, mute.MutedUserID).Scan(&id)
+	if err == nil {
+		return id, nil
+	}
+
+	if !errors.Is(err, pgx.ErrNoRows) {
+		m.log.Error("error while inserting mute", logger.Error(err))
+		return "", err
+	}
+
+	// already muted, nothing was inserted
+	query = `SELECT mute_id FROM mutes WHERE user_id = $1 AND muted_user_id = $2`
+	if err = m.db.QueryRow(ctx, query, mute.UserID, mute.MutedUserID).Scan(&id); err != nil {
+		m.log.Error("error while selecting existing mute", logger.Error(err))
+		return "", err
+	}
+
+	return id, nil
+}
+
+func (m *muteRepo) GetUserMuteByID(ctx context.Context, key models.PrimaryKey) (models.Mute, error) {
+	mute := models.Mute{}
+	query := `SELECT mute_id, user_id, muted_user_id, created_at FROM mutes WHERE mute_id = $1`
+	if err := m.db.QueryRow(ctx, query, key.ID).Scan(&mute.MuteID, &mute.UserID, &mute.MutedUserID, &mute.CreatedAt); err != nil {
+		m.log.Error("error while selecting mute", logger.Error(err))
+		return models.Mute{}, err
+	}
+
+	return mute, nil
+}
+
+// GetMutedUsers lists the users req.UserID has muted, most recent first.
+func (m *muteRepo) GetMutedUsers(ctx context.Context, req models.GetListRequest) (models.UserSummariesResponse, error) {
+	var (
+		count  = 0
+		offset = (req.Page - 1) * req.Limit
+	)
+
+	countQuery := `SELECT COUNT(1) FROM mutes WHERE user_id = $1`
+	if err := m.db.QueryRow(ctx, countQuery, req.UserID).Scan(&count); err != nil {
+		m.log.Error("error while counting muted users", logger.Error(err))
+		return models.UserSummariesResponse{}, err
+	}
+
+	query := `
+		SELECT ` + userSummaryColumns + `
+		FROM mutes m
+		JOIN users u ON u.user_id = m.muted_user_id
+		WHERE m.user_id = $1
+		ORDER BY m.created_at DESC LIMIT $2 OFFSET $3
+	`
+	rows, err := m.db.Query(ctx, query, req.UserID, req.Limit, offset)
+	if err != nil {
+		m.log.Error("error while selecting muted users", logger.Error(err))
+		return models.UserSummariesResponse{}, err
+	}
+
+	users, err := scanUserSummaries(rows)
+	if err != nil {
+		m.log.Error("error while scanning muted users", logger.Error(err))
+		return models.UserSummariesResponse{}, err
+	}
+
+	return models.UserSummariesResponse{
+		Users: users,
+		Count: count,
+	}, nil
+}
+
+// DeleteUserMute unmutes the user. Unmuting a user that is not muted is not an
+// error.
+func (m *muteRepo) DeleteUserMute(ctx context.Context, mute models.CreateMute) error {
+	query := `DELETE FROM mutes WHERE user_id = $1 AND muted_user_id = $2`
+	if _, err := m.db.Exec(ctx, query, mute.UserID, mute.MutedUserID); err != nil {
+		m.log.Error("error while deleting mute", logger.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// IsUserMuted reports whether userID has muted mutedUserID.
+func (m *muteRepo) IsUserMuted(ctx context.Context, userID, mutedUserID string) (bool, error) {
+	var exists bool
+	query := `SELECT EXISTS (SELECT 1 FROM mutes WHERE user_id = $1 AND muted_user_id = $2)`
+	if err := m.db.QueryRow(ctx, query, userID, mutedUserID).Scan(&exists); err != nil {
+		m.log.Error("error while checking mute", logger.Error(err))
+		return false, err
+	}
+
+	return exists, nil
+}
+
+// CreateKeyword mutes the keyword. Muting an already muted keyword updates its
+// expiry.
+func (m *muteRepo) CreateKeyword(ctx context.Context, keyword models.CreateMutedKeyword) (string, error) {
+	var id string
+	query := `
+		INSERT INTO muted_keywords (muted_keyword_id, user_id, keyword, pattern, expires_at) VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id, keyword) DO UPDATE SET expires_at = EXCLUDED.expires_at
+		RETURNING muted_keyword_id
+	`
+	if err := m.db.QueryRow(ctx, query, uuid.New(), keyword.UserID, keyword.Keyword, keyword.Pattern, keyword.ExpiresAt).Scan(&id); err != nil {
+		m.log.Error("error while inserting muted keyword", logger.Error(err))
+		return "", err
+	}
+
+	return id, nil
+}
+
+func (m *muteRepo) GetKeywordByID(ctx context.Context, key models.PrimaryKey) (models.MutedKeyword, error) {
+	keyword := models.MutedKeyword{}
+	query := `SELECT muted_keyword_id, user_id, keyword, expires_at, created_at FROM muted_keywords WHERE muted_keyword_id = $1`
+	if err := m.db.QueryRow(ctx, query, key.ID).Scan(&keyword.MutedKeywordID, &keyword.UserID, &keyword.Keyword, &keyword.ExpiresAt, &keyword.CreatedAt); err != nil {
+		m.log.Error("error while selecting muted keyword", logger.Error(err))
+		return models.MutedKeyword{}, err
+	}
+
+	return keyword, nil
+}
+
+// GetKeywords lists the keywords req.UserID has muted that have not expired.
+func (m *muteRepo) GetKeywords(ctx context.Context, req models.GetListRequest) (models.MutedKeywordsResponse, error) {
+	var (
+		keywords = []models.MutedKeyword{}
+		count    = 0
+		offset   = (req.Page - 1) * req.Limit
+	)
+
+	filter := ` WHERE user_id = $1 AND (expires_at IS NULL OR expires_at > NOW())`
+
+	countQuery := `SELECT COUNT(1) FROM muted_keywords` + filter
+	if err := m.db.QueryRow(ctx, countQuery, req.UserID).Scan(&count); err != nil {
+		m.log.Error("error while counting muted keywords", logger.Error(err))
+		return models.MutedKeywordsResponse{}, err
+	}
+
+	query := `
+		SELECT muted_keyword_id, user_id, keyword, expires_at, created_at
+		FROM muted_keywords` + filter + `
+		ORDER BY created_at DESC LIMIT $2 OFFSET $3
+	`
+	rows, err := m.db.Query(ctx, query, req.UserID, req.Limit, offset)
+	if err != nil {
+		m.log.Error("error while selecting muted keywords", logger.Error(err))
+		return models.MutedKeywordsResponse{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		keyword := models.MutedKeyword{}
+		if err = rows.Scan(&keyword.MutedKeywordID, &keyword.UserID, &keyword.Keyword, &keyword.ExpiresAt, &keyword.CreatedAt); err != nil {
+			m.log.Error("error while scanning muted keyword", logger.Error(err))
+			return models.MutedKeywordsResponse{}, err
+		}
+		keywords = append(keywords, keyword)
+	}
+
+	return models.MutedKeywordsResponse{
+		MutedKeywords: keywords,
+		Count:         count,
+	}, nil
+}
+
+// DeleteKeyword unmutes the keyword if it belongs to userID.
+func (m *muteRepo) DeleteKeyword(ctx context.Context, key models.PrimaryKey, userID string) error {
+	query := `DELETE FROM muted_keywords WHERE muted_keyword_id = $1 AND user_id = $2`
+	cmdTag, err := m.db.Exec(ctx, query, key.ID, userID)
+	if err != nil {
+		m.log.Error("error while deleting muted keyword", logger.Error(err))
+		return err
+	}
+
+	if cmdTag.RowsAffected() == 0 {
+		m.log.Error("no rows affected while deleting muted keyword")
+		return fmt.Errorf("no rows affected")
+	}
+
+	return nil
+}
+
+// CreateConversationMute mutes the conversation. Muting an already muted
+// conversation returns the existing mute.
+func (m *muteRepo) CreateConversationMute(ctx context.Context, mute models.CreateMutedConversation) (string, error) {
+	var id string
+	query := `
+		INSERT INTO muted_conversations (muted_conversation_id, user_id, conversation_id) VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, conversation_id) DO NOTHING
+		RETURNING muted_conversation_id
+	`
+	err := m.db.QueryRow(ctx, query, uuid.New(), mute.UserID, mute.ConversationID).Scan(&id)
+	if err == nil {
+		return id, nil
+	}
+
+	if !errors.Is(err, pgx.ErrNoRows) {
+		m.log.Error("error while inserting muted conversation", logger.Error(err))
+		return "", err
+	}
+
+	// already muted, nothing was inserted
+	query = `SELECT muted_conversation_id FROM muted_conversations WHERE user_id = $1 AND conversation_id = $2`
+	if err = m.db.QueryRow(ctx, query, mute.UserID, mute.ConversationID).Scan(&id); err != nil {
+		m.log.Error("error while selecting existing muted conversation", logger.Error(err))
+		return "", err
+	}
+
+	return id, nil
+}
+
+func (m *muteRepo) GetConversationMuteByID(ctx context.Context, key models.PrimaryKey) (models.MutedConversation, error) {
+	mute := models.MutedConversation{}
+	query := `SELECT muted_conversation_id, user_id, conversation_id, created_at FROM muted_conversations WHERE muted_conversation_id = $1`
+	if err := m.db.QueryRow(ctx, query, key.ID).Scan(&mute.MutedConversationID, &mute.UserID, &mute.ConversationID, &mute.CreatedAt); err != nil {
+		m.log.Error("error while selecting muted conversation", logger.Error(err))
+		return models.MutedConversation{}, err
+	}
+
+	return mute, nil
+}
+
+// DeleteConversationMute unmutes the conversation. Unmuting a conversation
+// that is not muted is not an error.
+func (m *muteRepo) DeleteConversationMute(ctx context.Context, mute models.CreateMutedConversation) error {
+	query := `DELETE FROM muted_conversations WHERE user_id = $1 AND conversation_id = $2`
+	if _, err := m.db.Exec(ctx, query, mute.UserID, mute.ConversationID); err != nil {
+		m.log.Error("error while deleting muted conversation", logger.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// IsConversationMuted reports whether userID has muted the conversation.
+func (m *muteRepo) IsConversationMuted(ctx context.Context, userID, conversationID string) (bool, error) {
+	var exists bool
+	query := `SELECT EXISTS (SELECT 1 FROM muted_conversations WHERE user_id = $1 AND conversation_id = $2)`
+	if err := m.db.QueryRow(ctx, query, userID, conversationID).Scan(&exists); err != nil {
+		m.log.Error("error while checking muted conversation", logger.Error(err))
+		return false, err
+	}
+
+	return exists, nil
+}
@@ -0,0 +1,214 @@
+package postgres
+
+import (
+	"context"
+	"test/api/models"
+	"test/pkg/logger"
+	"test/storage"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// maxNotificationActors is how many of the most recent actors are returned
+// with each grouped notification.
+const maxNotificationActors = 3
+
+// filteredNotifications selects the notifications of the user bound to $1
+// that pass the user's blocks and mutes.
+var filteredNotifications = `
+	WITH filtered AS (
+		SELECT n.type, n.tweet_id, n.actor_user_id, n.read_at, n.created_at
+		FROM notifications n
+		LEFT JOIN tweets t ON t.tweet_id = n.tweet_id
+		WHERE n.user_id = $1
+		AND ` + notificationAllowed("n.actor_user_id", "n.type", "$1") + `
+	)
+`
+
+type notificationRepo struct {
+	db  *pgxpool.Pool
+	log logger.ILogger
+}
+
+func NewNotificationsRepo(db *pgxpool.Pool, log logger.ILogger) storage.INotificationsStorage {
+	return &notificationRepo{
+		db:  db,
+		log: log,
+	}
+}
+
+// notificationAllowed returns a condition that holds unless the recipient bound
+// to recipientParam blocked or muted the actor in actorColumn, muted a keyword
+// of the mentioning or replying tweet, or muted the conversation of the tweet
+// aliased t.
+func notificationAllowed(actorColumn, typeColumn, recipientParam string) string {
+	content := `CASE WHEN ` + typeColumn + ` IN ('` + models.NotificationTypeMention + `', '` + models.NotificationTypeReply + `') THEN t.content END`
+
+	return notBlocked(actorColumn, recipientParam) + `
+		AND ` + notMuted(actorColumn, content, recipientParam) + `
+		AND ` + notMutedConversation("t.conversation_id", recipientParam)
+}
+
+// Create records the notification and reports whether it was stored. Nothing
+// is stored for events users trigger on their own content, for actors or
+// conversations the recipient filtered out, or for events already notified.
+func (n *notificationRepo) Create(ctx context.Context, notification models.CreateNotification) (bool, error) {
+	query := `
+		INSERT INTO notifications (notification_id, user_id, actor_user_id, type, tweet_id)
+		SELECT $1::uuid, $2::uuid, $3::uuid, $4::text, $5::uuid
+		FROM (SELECT 1) e
+		LEFT JOIN tweets t ON t.tweet_id = $5::uuid
+		WHERE $2::uuid <> $3::uuid
+		AND ` + notificationAllowed("$3::uuid", "$4::text", "$2") + `
+		ON CONFLICT DO NOTHING
+	`
+	cmdTag, err := n.db.Exec(ctx, query, uuid.New(), notification.UserID, notification.ActorUserID, notification.Type, notification.TweetID)
+	if err != nil {
+		n.log.Error("error while inserting notification", logger.Error(err))
+		return false, err
+	}
+
+	return cmdTag.RowsAffected() > 0, nil
+}
+
+// Delete removes the notification of an event that was undone.
+func (n *notificationRepo) Delete(ctx context.Context, notification models.CreateNotification) error {
+	query := `
+		DELETE FROM notifications
+		WHERE user_id = $1 AND actor_user_id = $2 AND type = $3
+		AND tweet_id IS NOT DISTINCT FROM $4::uuid
+	`
+	if _, err := n.db.Exec(ctx, query, notification.UserID, notification.ActorUserID, notification.Type, notification.TweetID); err != nil {
+		n.log.Error("error while deleting notification", logger.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// GetList pages through the notifications of req.UserID grouped by type and
+// tweet, most recent group first.
+func (n *notificationRepo) GetList(ctx context.Context, req models.GetListRequest) (models.NotificationsResponse, error) {
+	var (
+		notifications = []models.Notification{}
+		actorIDs      = [][]string{}
+		count         = 0
+		unreadCount   = 0
+		offset        = (req.Page - 1) * req.Limit
+	)
+
+	countQuery := filteredNotifications + `
+		SELECT
+			(SELECT COUNT(1) FROM (SELECT 1 FROM filtered GROUP BY type, tweet_id) g),
+			(SELECT COUNT(1) FROM filtered WHERE read_at IS NULL)
+	`
+	if err := n.db.QueryRow(ctx, countQuery, req.UserID).Scan(&count, &unreadCount); err != nil {
+		n.log.Error("error while counting notifications", logger.Error(err))
+		return models.NotificationsResponse{}, err
+	}
+
+	query := filteredNotifications + `
+		SELECT type, tweet_id, MAX(created_at), COUNT(DISTINCT actor_user_id), BOOL_OR(read_at IS NULL),
+			(array_agg(actor_user_id::text ORDER BY created_at DESC))[1:$4]
+		FROM filtered
+		GROUP BY type, tweet_id
+		ORDER BY MAX(created_at) DESC LIMIT $2 OFFSET $3
+	`
+	rows, err := n.db.Query(ctx, query, req.UserID, req.Limit, offset, maxNotificationActors)
+	if err != nil {
+		n.log.Error("error while selecting notifications", logger.Error(err))
+		return models.NotificationsResponse{}, err
+	}
+	defer rows.Close()
+
+	allActorIDs := []string{}
+	for rows.Next() {
+		var (
+			notification = models.Notification{}
+			ids          []string
+		)
+		if err = rows.Scan(&notification.Type, &notification.TweetID, &notification.CreatedAt, &notification.ActorCount, &notification.Unread, &ids); err != nil {
+			n.log.Error("error while scanning notification", logger.Error(err))
+			return models.NotificationsResponse{}, err
+		}
+		notifications = append(notifications, notification)
+		actorIDs = append(actorIDs, ids)
+		allActorIDs = append(allActorIDs, ids...)
+	}
+	rows.Close()
+
+	actors, err := n.getActors(ctx, allActorIDs)
+	if err != nil {
+		return models.NotificationsResponse{}, err
+	}
+
+	for i := range notifications {
+		notifications[i].Actors = []models.UserSummary{}
+		for _, id := range actorIDs[i] {
+			if actor, ok := actors[id]; ok {
+				notifications[i].Actors = append(notifications[i].Actors, actor)
+			}
+		}
+	}
+
+	return models.NotificationsResponse{
+		Notifications: notifications,
+		Count:         count,
+		UnreadCount:   unreadCount,
+	}, nil
+}
+
+// MarkRead marks the unread notifications of req.UserID created up to
+// req.Before, or all of them when it is not set, as read.
+func (n *notificationRepo) MarkRead(ctx context.Context, req models.MarkNotificationsRead) error {
+	query := `
+		UPDATE notifications SET read_at = NOW()
+		WHERE user_id = $1 AND read_at IS NULL
+		AND created_at <= COALESCE($2, NOW())
+	`
+	if _, err := n.db.Exec(ctx, query, req.UserID, req.Before); err != nil {
+		n.log.Error("error while marking notifications read", logger.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+func (n *notificationRepo) CountUnread(ctx context.Context, userID string) (int, error) {
+	count := 0
+	query := filteredNotifications + `SELECT COUNT(1) FROM filtered WHERE read_at IS NULL`
+	if err := n.db.QueryRow(ctx, query, userID).Scan(&count); err != nil {
+		n.log.Error("error while counting unread notifications", logger.Error(err))
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// getActors loads the summaries of the given users keyed by id.
+func (n *notificationRepo) getActors(ctx context.Context, ids []string) (map[string]models.UserSummary, error) {
+	actors := make(map[string]models.UserSummary, len(ids))
+	if len(ids) == 0 {
+		return actors, nil
+	}
+
+	query := `SELECT ` + userSummaryColumns + ` FROM users u WHERE u.user_id = ANY($1::uuid[])`
+	rows, err := n.db.Query(ctx, query, ids)
+	if err != nil {
+		n.log.Error("error while selecting notification actors", logger.Error(err))
+		return nil, err
+	}
+
+	users, err := scanUserSummaries(rows)
+	if err != nil {
+		n.log.Error("error while scanning notification actors", logger.Error(err))
+		return nil, err
+	}
+
+	for _, user := range users {
+		actors[user.ID] = user
+	}
+
+	return actors, nil
+}
@@ -0,0 +1,214 @@
+package postgres
+
+import (
+	"context"
+	"test/api/models"
+	"test/pkg/logger"
+	"test/storage"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type pollRepo struct {
+	db  *pgxpool.Pool
+	log logger.ILogger
+}
+
+func NewPollsRepo(db *pgxpool.Pool, log logger.ILogger) storage.IPollsStorage {
+	return &pollRepo{
+		db:  db,
+		log: log,
+	}
+}
+
+// GetByTweetIDs returns the polls of the tweets with their options in order,
+// the vote of viewerID if any and every count. Polls past their end are closed
+// even before they are finalized.
+func (p *pollRepo) GetByTweetIDs(ctx context.Context, tweetIDs []string, viewerID string) ([]models.Poll, error) {
+	polls := []models.Poll{}
+	query := `
+		SELECT p.tweet_id, p.ends_at, p.closed_at IS NOT NULL OR p.ends_at <= NOW(), v.option_id,
+			o.option_id, o.position, o.label, o.vote_count
+		FROM polls p
+		JOIN poll_options o ON o.tweet_id = p.tweet_id
+		LEFT JOIN poll_votes v ON v.tweet_id = p.tweet_id AND v.user_id = NULLIF($2::text, '')::uuid
+		WHERE p.tweet_id = ANY($1::uuid[])
+		ORDER BY p.tweet_id, o.position
+	`
+	rows, err := p.db.Query(ctx, query, tweetIDs, viewerID)
+	if err != nil {
+		p.log.Error("error while selecting polls", logger.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			poll   models.Poll
+			option models.PollOption
+			votes  int
+		)
+		if err = rows.Scan(
+			&poll.TweetID, &poll.EndsAt, &poll.Closed, &poll.ViewerVote,
+			&option.ID, &option.Position, &option.Label, &votes,
+		); err != nil {
+			p.log.Error("error while scanning poll", logger.Error(err))
+			return nil, err
+		}
+		option.Votes = &votes
+
+		// options of the same poll come one after the other
+		if len(polls) == 0 || polls[len(polls)-1].TweetID != poll.TweetID {
+			total := 0
+			poll.TotalVotes = &total
+			polls = append(polls, poll)
+		}
+
+		last := &polls[len(polls)-1]
+		last.Options = append(last.Options, option)
+		*last.TotalVotes += votes
+	}
+
+	return polls, rows.Err()
+}
+
+// Vote records the vote and reports whether it was recorded, which it is not
+// when the user already voted in the poll or it is no longer open.
+func (p *pollRepo) Vote(ctx context.Context, vote models.CreatePollVote) (bool, error) {
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		p.log.Error("error while beginning poll vote transaction", logger.Error(err))
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	// keeps the poll from closing while the vote is counted
+	var open bool
+	query := `SELECT EXISTS (SELECT 1 FROM polls WHERE tweet_id = $1 AND closed_at IS NULL AND ends_at > NOW() FOR SHARE)`
+	if err = tx.QueryRow(ctx, query, vote.TweetID).Scan(&open); err != nil {
+		p.log.Error("error while locking poll", logger.Error(err))
+		return false, err
+	}
+
+	if !open {
+		return false, nil
+	}
+
+	query = `
+		INSERT INTO poll_votes (tweet_id, user_id, option_id)
+		SELECT tweet_id, $2, option_id FROM poll_options WHERE option_id = $3 AND tweet_id = $1
+		ON CONFLICT (tweet_id, user_id) DO NOTHING
+	`
+	cmdTag, err := tx.Exec(ctx, query, vote.TweetID, vote.UserID, vote.OptionID)
+	if err != nil {
+		p.log.Error("error while inserting poll vote", logger.Error(err))
+		return false, err
+	}
+
+	if cmdTag.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	query = `UPDATE poll_options SET vote_count = vote_count + 1 WHERE option_id = $1`
+	if _, err = tx.Exec(ctx, query, vote.OptionID); err != nil {
+		p.log.Error("error while counting poll vote", logger.Error(err))
+		return false, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		p.log.Error("error while committing poll vote transaction", logger.Error(err))
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Close closes up to limit polls past their end, counting their votes anew
+// for the final results, and returns the ids of their tweets. Polls being
+// closed or voted in by others are skipped.
+func (p *pollRepo) Close(ctx context.Context, limit int) ([]string, error) {
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		p.log.Error("error while beginning poll closing transaction", logger.Error(err))
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		UPDATE polls p
+		SET closed_at = NOW()
+		WHERE p.tweet_id IN (
+			SELECT tweet_id FROM polls
+			WHERE closed_at IS NULL AND ends_at <= NOW()
+			ORDER BY ends_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING p.tweet_id
+	`
+	rows, err := tx.Query(ctx, query, limit)
+	if err != nil {
+		p.log.Error("error while closing polls", logger.Error(err))
+		return nil, err
+	}
+
+	tweetIDs := []string{}
+	for rows.Next() {
+		var id string
+		if err = rows.Scan(&id); err != nil {
+			rows.Close()
+			p.log.Error("error while scanning closed poll", logger.Error(err))
+			return nil, err
+		}
+		tweetIDs = append(tweetIDs, id)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		p.log.Error("error while closing polls", logger.Error(err))
+		return nil, err
+	}
+
+	if len(tweetIDs) == 0 {
+		return tweetIDs, nil
+	}
+
+	query = `
+		UPDATE poll_options o
+		SET vote_count = (SELECT COUNT(*) FROM poll_votes v WHERE v.option_id = o.option_id)
+		WHERE o.tweet_id = ANY($1::uuid[])
+	`
+	if _, err = tx.Exec(ctx, query, tweetIDs); err != nil {
+		p.log.Error("error while finalizing poll results", logger.Error(err))
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		p.log.Error("error while committing poll closing transaction", logger.Error(err))
+		return nil, err
+	}
+
+	return tweetIDs, nil
+}
+
+// GetVoterIDs lists the users who voted in the poll of the tweet.
+func (p *pollRepo) GetVoterIDs(ctx context.Context, tweetID string) ([]string, error) {
+	ids := []string{}
+	query := `SELECT user_id FROM poll_votes WHERE tweet_id = $1`
+	rows, err := p.db.Query(ctx, query, tweetID)
+	if err != nil {
+		p.log.Error("error while selecting poll voters", logger.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+		if err = rows.Scan(&id); err != nil {
+			p.log.Error("error while scanning poll voter", logger.Error(err))
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
@@ -111,3 +111,51 @@ func (s Store) Tweets() storage.ITweetsStorage {
 func (s Store) Retweets() storage.IRetweetsStorage {
 	return NewReTweetsRepo(s.pool, s.log)
 }
+
+func (s Store) FollowRequests() storage.IFollowRequestsStorage {
+	return NewFollowRequestsRepo(s.pool, s.log)
+}
+
+func (s Store) Blocks() storage.IBlocksStorage {
+	return NewBlocksRepo(s.pool, s.log)
+}
+
+func (s Store) Mutes() storage.IMutesStorage {
+	return NewMutesRepo(s.pool, s.log)
+}
+
+func (s Store) Notifications() storage.INotificationsStorage {
+	return NewNotificationsRepo(s.pool, s.log)
+}
+
+func (s Store) Messages() storage.IMessagesStorage {
+	return NewMessagesRepo(s.pool, s.log)
+}
+
+func (s Store) Bookmarks() storage.IBookmarksStorage {
+	return NewBookmarksRepo(s.pool, s.log)
+}
+
+func (s Store) Lists() storage.IListsStorage {
+	return NewListsRepo(s.pool, s.log)
+}
+
+func (s Store) Suggestions() storage.ISuggestionsStorage {
+	return NewSuggestionsRepo(s.pool, s.log)
+}
+
+func (s Store) Media() storage.IMediaStorage {
+	return NewMediaRepo(s.pool, s.log)
+}
+
+func (s Store) Polls() storage.IPollsStorage {
+	return NewPollsRepo(s.pool, s.log)
+}
+
+func (s Store) Drafts() storage.IDraftsStorage {
+	return NewDraftsRepo(s.pool, s.log)
+}
+
+func (s Store) Reports() storage.IReportsStorage {
+	return NewReportsRepo(s.pool, s.log)
+}
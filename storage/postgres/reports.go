@@ -0,0 +1,284 @@
+package postgres
+
+import (
+	"context"
+	"test/api/models"
+	"test/pkg/logger"
+	"test/storage"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	reportColumns = `report_id, reporter_user_id, target_type, target_id, target_user_id, target_content, reason, details,
+	state, claimed_by, claimed_at, resolved_by, resolved_at, resolution, created_at, updated_at`
+	moderationActionColumns = `action_id, moderator_user_id, report_id, action, target_type, target_id, note, created_at`
+)
+
+type reportRepo struct {
+	db  *pgxpool.Pool
+	log logger.ILogger
+}
+
+func NewReportsRepo(db *pgxpool.Pool, log logger.ILogger) storage.IReportsStorage {
+	return &reportRepo{
+		db:  db,
+		log: log,
+	}
+}
+
+func scanReport(row pgx.Row, report *models.Report) error {
+	return row.Scan(
+		&report.ID, &report.ReporterUserID, &report.TargetType, &report.TargetID, &report.TargetUserID, &report.TargetContent,
+		&report.Reason, &report.Details, &report.State, &report.ClaimedBy, &report.ClaimedAt, &report.ResolvedBy,
+		&report.ResolvedAt, &report.Resolution, &report.CreatedAt, &report.UpdatedAt,
+	)
+}
+
+// Create files the report. A reporter whose report of the same target is
+// still pending gets pgx.ErrNoRows.
+func (r *reportRepo) Create(ctx context.Context, report models.CreateReport) (string, error) {
+	var id string
+	query := `
+		INSERT INTO reports (reporter_user_id, target_type, target_id, target_user_id, target_content, reason, details)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (reporter_user_id, target_type, target_id) WHERE state <> '` + models.ReportStateResolved + `' DO NOTHING
+		RETURNING report_id
+	`
+	if err := r.db.QueryRow(ctx, query,
+		report.ReporterUserID, report.TargetType, report.TargetID, report.TargetUserID, report.TargetContent, report.Reason, report.Details,
+	).Scan(&id); err != nil {
+		r.log.Error("error while inserting report", logger.Error(err))
+		return "", err
+	}
+
+	return id, nil
+}
+
+func (r *reportRepo) GetByID(ctx context.Context, key models.PrimaryKey) (models.Report, error) {
+	report := models.Report{}
+	query := `SELECT ` + reportColumns + ` FROM reports WHERE report_id = $1`
+	if err := scanReport(r.db.QueryRow(ctx, query, key.ID), &report); err != nil {
+		r.log.Error("error while selecting report", logger.Error(err))
+		return models.Report{}, err
+	}
+
+	return report, nil
+}
+
+// GetList lists the reports in req.State, or all of them, oldest first so
+// that the queue is worked in the order reports came in.
+func (r *reportRepo) GetList(ctx context.Context, req models.GetReportsRequest) (models.ReportsResponse, error) {
+	var (
+		count  = 0
+		offset = (req.Page - 1) * req.Limit
+	)
+
+	filter := ` FROM reports WHERE ($1 = '' OR state = $1)`
+
+	countQuery := `SELECT COUNT(1)` + filter
+	if err := r.db.QueryRow(ctx, countQuery, req.State).Scan(&count); err != nil {
+		r.log.Error("error while counting reports", logger.Error(err))
+		return models.ReportsResponse{}, err
+	}
+
+	query := `SELECT ` + reportColumns + filter + `
+		ORDER BY created_at, report_id
+		LIMIT $2 OFFSET $3
+	`
+	rows, err := r.db.Query(ctx, query, req.State, req.Limit, offset)
+	if err != nil {
+		r.log.Error("error while selecting reports", logger.Error(err))
+		return models.ReportsResponse{}, err
+	}
+	defer rows.Close()
+
+	reports := []models.Report{}
+	for rows.Next() {
+		report := models.Report{}
+		if err = scanReport(rows, &report); err != nil {
+			r.log.Error("error while scanning report", logger.Error(err))
+			return models.ReportsResponse{}, err
+		}
+		reports = append(reports, report)
+	}
+
+	if err = rows.Err(); err != nil {
+		r.log.Error("error while iterating reports", logger.Error(err))
+		return models.ReportsResponse{}, err
+	}
+
+	return models.ReportsResponse{
+		Reports: reports,
+		Count:   count,
+	}, nil
+}
+
+// Claim assigns the report to moderatorID if it is open, already theirs, or
+// claimed by another moderator longer than lease ago. Reports that cannot be
+// claimed are reported as pgx.ErrNoRows.
+func (r *reportRepo) Claim(ctx context.Context, reportID, moderatorID string, lease time.Duration) (models.Report, error) {
+	report := models.Report{}
+	query := `
+		UPDATE reports
+		SET state = $3, claimed_by = $2, claimed_at = NOW(), updated_at = NOW()
+		WHERE report_id = $1
+		AND (
+			state = $4
+			OR (state = $3 AND (claimed_by = $2 OR claimed_at <= NOW() - $5::float8 * INTERVAL '1 second'))
+		)
+		RETURNING ` + reportColumns
+	if err := scanReport(r.db.QueryRow(ctx, query,
+		reportID, moderatorID, models.ReportStateClaimed, models.ReportStateOpen, lease.Seconds(),
+	), &report); err != nil {
+		r.log.Error("error while claiming report", logger.Error(err))
+		return models.Report{}, err
+	}
+
+	return report, nil
+}
+
+// ClaimNext claims the oldest report nobody is working on for moderatorID, as
+// Claim does. Reports being claimed by others at the same time are skipped.
+func (r *reportRepo) ClaimNext(ctx context.Context, moderatorID string, lease time.Duration) (models.Report, error) {
+	report := models.Report{}
+	query := `
+		UPDATE reports
+		SET state = $2, claimed_by = $1, claimed_at = NOW(), updated_at = NOW()
+		WHERE report_id = (
+			SELECT report_id FROM reports
+			WHERE state = $3 OR (state = $2 AND claimed_at <= NOW() - $4::float8 * INTERVAL '1 second')
+			ORDER BY created_at, report_id
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + reportColumns
+	if err := scanReport(r.db.QueryRow(ctx, query,
+		moderatorID, models.ReportStateClaimed, models.ReportStateOpen, lease.Seconds(),
+	), &report); err != nil {
+		r.log.Error("error while claiming next report", logger.Error(err))
+		return models.Report{}, err
+	}
+
+	return report, nil
+}
+
+// Resolve closes the report claimed by action.ModeratorUserID, applies the
+// action to its target and records it in the audit log, all or nothing.
+// Reports not claimed by the moderator are reported as pgx.ErrNoRows.
+func (r *reportRepo) Resolve(ctx context.Context, action models.ModerationAction) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		r.log.Error("error while beginning report resolution transaction", logger.Error(err))
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		UPDATE reports
+		SET state = $3, resolved_by = $2, resolved_at = NOW(), resolution = $4, updated_at = NOW()
+		WHERE report_id = $1 AND state = $5 AND claimed_by = $2
+	`
+	cmdTag, err := tx.Exec(ctx, query,
+		action.ReportID, action.ModeratorUserID, models.ReportStateResolved, action.Action, models.ReportStateClaimed,
+	)
+	if err != nil {
+		r.log.Error("error while resolving report", logger.Error(err))
+		return err
+	}
+
+	if cmdTag.RowsAffected() == 0 {
+		r.log.Error("no rows affected while resolving report")
+		return pgx.ErrNoRows
+	}
+
+	var effect string
+	switch action.Action {
+	case models.ModerationActionHideTweet:
+		effect = `UPDATE tweets SET hidden_at = COALESCE(hidden_at, NOW()) WHERE tweet_id = $1`
+	case models.ModerationActionSuspendUser:
+		effect = `UPDATE users SET suspended_at = COALESCE(suspended_at, NOW()) WHERE user_id = $1`
+	}
+
+	// the target may be gone already, which leaves nothing to do
+	if effect != "" {
+		if _, err = tx.Exec(ctx, effect, action.TargetID); err != nil {
+			r.log.Error("error while applying moderation action", logger.Error(err))
+			return err
+		}
+	}
+
+	query = `
+		INSERT INTO moderation_actions (moderator_user_id, report_id, action, target_type, target_id, note)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+	if _, err = tx.Exec(ctx, query,
+		action.ModeratorUserID, action.ReportID, action.Action, action.TargetType, action.TargetID, action.Note,
+	); err != nil {
+		r.log.Error("error while inserting moderation action", logger.Error(err))
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		r.log.Error("error while committing report resolution transaction", logger.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// GetActions pages through the moderation audit log, most recent first.
+func (r *reportRepo) GetActions(ctx context.Context, req models.GetModerationActionsRequest) (models.ModerationActionsResponse, error) {
+	var (
+		count  = 0
+		offset = (req.Page - 1) * req.Limit
+	)
+
+	filter := `
+		FROM moderation_actions
+		WHERE ($1 = '' OR target_type = $1)
+		AND ($2 = '' OR target_id = NULLIF($2, '')::uuid)
+	`
+
+	countQuery := `SELECT COUNT(1)` + filter
+	if err := r.db.QueryRow(ctx, countQuery, req.TargetType, req.TargetID).Scan(&count); err != nil {
+		r.log.Error("error while counting moderation actions", logger.Error(err))
+		return models.ModerationActionsResponse{}, err
+	}
+
+	query := `SELECT ` + moderationActionColumns + filter + `
+		ORDER BY created_at DESC, action_id
+		LIMIT $3 OFFSET $4
+	`
+	rows, err := r.db.Query(ctx, query, req.TargetType, req.TargetID, req.Limit, offset)
+	if err != nil {
+		r.log.Error("error while selecting moderation actions", logger.Error(err))
+		return models.ModerationActionsResponse{}, err
+	}
+	defer rows.Close()
+
+	actions := []models.ModerationAction{}
+	for rows.Next() {
+		action := models.ModerationAction{}
+		if err = rows.Scan(
+			&action.ID, &action.ModeratorUserID, &action.ReportID, &action.Action,
+			&action.TargetType, &action.TargetID, &action.Note, &action.CreatedAt,
+		); err != nil {
+			r.log.Error("error while scanning moderation action", logger.Error(err))
+			return models.ModerationActionsResponse{}, err
+		}
+		actions = append(actions, action)
+	}
+
+	if err = rows.Err(); err != nil {
+		r.log.Error("error while iterating moderation actions", logger.Error(err))
+		return models.ModerationActionsResponse{}, err
+	}
+
+	return models.ModerationActionsResponse{
+		Actions: actions,
+		Count:   count,
+	}, nil
+}
@@ -2,12 +2,14 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"test/api/models"
 	"test/pkg/logger"
 	"test/storage"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -29,6 +31,9 @@ func (r *retweetsRepo) Create(ctx context.Context, retweet models.CreateRetweet)
 	query := `INSERT INTO retweets (retweet_id, tweet_id, user_id) VALUES ($1, $2, $3)`
 	cmdTag, err := r.db.Exec(ctx, query, id, retweet.OriginalTweetID, retweet.UserID)
 	if err != nil {
+		if isUniqueViolation(err) {
+			err = fmt.Errorf("user has already retweeted this tweet")
+		}
 		r.log.Error("Error while inserting retweet data", logger.Error(err))
 		return "", err
 	}
@@ -42,9 +47,50 @@ func (r *retweetsRepo) Create(ctx context.Context, retweet models.CreateRetweet)
 	return id.String(), nil
 }
 
+// Upsert retweets the tweet on behalf of the user and returns the id of the
+// retweet, whether it was just created or already existed.
+func (r *retweetsRepo) Upsert(ctx context.Context, retweet models.CreateRetweet) (string, error) {
+	var id string
+	query := `
+		INSERT INTO retweets (retweet_id, tweet_id, user_id) VALUES ($1, $2, $3)
+		ON CONFLICT (tweet_id, user_id) DO NOTHING
+		RETURNING retweet_id
+	`
+	err := r.db.QueryRow(ctx, query, uuid.New(), retweet.OriginalTweetID, retweet.UserID).Scan(&id)
+	if err == nil {
+		return id, nil
+	}
+
+	if !errors.Is(err, pgx.ErrNoRows) {
+		r.log.Error("Error while upserting retweet", logger.Error(err))
+		return "", err
+	}
+
+	// already retweeted, nothing was inserted
+	query = `SELECT retweet_id FROM retweets WHERE tweet_id = $1 AND user_id = $2`
+	if err = r.db.QueryRow(ctx, query, retweet.OriginalTweetID, retweet.UserID).Scan(&id); err != nil {
+		r.log.Error("Error while selecting existing retweet", logger.Error(err))
+		return "", err
+	}
+
+	return id, nil
+}
+
+func (r *retweetsRepo) GetByID(ctx context.Context, retweetID models.PrimaryKey) (models.Retweet, error) {
+	var retweet models.Retweet
+	query := `SELECT retweet_id, tweet_id, user_id, created_at FROM retweets WHERE retweet_id = $1`
+	err := r.db.QueryRow(ctx, query, retweetID.ID).Scan(&retweet.RetweetID, &retweet.OriginalTweetID, &retweet.UserID, &retweet.CreatedAt)
+	if err != nil {
+		r.log.Error("Error while selecting retweet", logger.Error(err))
+		return models.Retweet{}, err
+	}
+
+	return retweet, nil
+}
+
 func (r *retweetsRepo) Delete(ctx context.Context, retweetID models.PrimaryKey) error {
 	query := `DELETE FROM retweets WHERE retweet_id = $1`
-	cmdTag, err := r.db.Exec(ctx, query, retweetID)
+	cmdTag, err := r.db.Exec(ctx, query, retweetID.ID)
 	if err != nil {
 		r.log.Error("Error while deleting retweet", logger.Error(err))
 		return err
@@ -58,3 +104,56 @@ func (r *retweetsRepo) Delete(ctx context.Context, retweetID models.PrimaryKey)
 
 	return nil
 }
+
+// DeleteByTweetAndUser removes the user's retweet of the tweet. Removing a
+// retweet that does not exist is not an error.
+func (r *retweetsRepo) DeleteByTweetAndUser(ctx context.Context, retweet models.CreateRetweet) error {
+	query := `DELETE FROM retweets WHERE tweet_id = $1 AND user_id = $2`
+	if _, err := r.db.Exec(ctx, query, retweet.OriginalTweetID, retweet.UserID); err != nil {
+		r.log.Error("Error while deleting retweet by tweet and user", logger.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// GetRetweetingUsers lists the users who retweeted request.TweetID, most recent first.
+func (r *retweetsRepo) GetRetweetingUsers(ctx context.Context, request models.GetListRequest) (models.UserSummariesResponse, error) {
+	var (
+		count  = 0
+		offset = (request.Page - 1) * request.Limit
+	)
+
+	filter := `
+		WHERE r.tweet_id = $1
+		AND ` + notBlocked("r.user_id", "$2")
+
+	countQuery := `SELECT COUNT(1) FROM retweets r` + filter
+	if err := r.db.QueryRow(ctx, countQuery, request.TweetID, request.ViewerID).Scan(&count); err != nil {
+		r.log.Error("Error while counting retweeting users", logger.Error(err))
+		return models.UserSummariesResponse{}, err
+	}
+
+	query := `
+		SELECT ` + userSummaryColumns + `
+		FROM retweets r
+		JOIN users u ON u.user_id = r.user_id` + filter + `
+		ORDER BY r.created_at DESC LIMIT $3 OFFSET $4
+	`
+	rows, err := r.db.Query(ctx, query, request.TweetID, request.ViewerID, request.Limit, offset)
+	if err != nil {
+		r.log.Error("Error while selecting retweeting users", logger.Error(err))
+		return models.UserSummariesResponse{}, err
+	}
+
+	users, err := scanUserSummaries(rows)
+	if err != nil {
+		r.log.Error("Error while scanning retweeting users", logger.Error(err))
+		return models.UserSummariesResponse{}, err
+	}
+
+	return models.UserSummariesResponse{
+		Users: users,
+		Count: count,
+	}, nil
+}
@@ -0,0 +1,268 @@
+package postgres
+
+import (
+	"context"
+	"test/api/models"
+	"test/pkg/logger"
+	"test/storage"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type suggestionRepo struct {
+	db  *pgxpool.Pool
+	log logger.ILogger
+}
+
+func NewSuggestionsRepo(db *pgxpool.Pool, log logger.ILogger) storage.ISuggestionsStorage {
+	return &suggestionRepo{
+		db:  db,
+		log: log,
+	}
+}
+
+// suggestionAllowed holds when the user in candidateColumn may still be
+// suggested to the user bound to userParam: not the user, not followed or
+// requested to follow, not blocked either way and not muted.
+func suggestionAllowed(candidateColumn, userParam string) string {
+	return `(
+		` + candidateColumn + ` <> ` + userParam + `
+		AND NOT EXISTS (SELECT 1 FROM followers sf WHERE sf.user_id = ` + candidateColumn + ` AND sf.follower_user_id = ` + userParam + `)
+		AND NOT EXISTS (SELECT 1 FROM follow_requests sr WHERE sr.user_id = ` + candidateColumn + ` AND sr.requester_user_id = ` + userParam + `)
+		AND NOT EXISTS (SELECT 1 FROM mutes sm WHERE sm.user_id = ` + userParam + ` AND sm.muted_user_id = ` + candidateColumn + `)
+		AND ` + notBlocked(candidateColumn, userParam) + `
+	)`
+}
+
+// Refresh recomputes the suggestions of the user. Candidates are the accounts
+// followed by the accounts the user follows, the user's followers, the users
+// tweeting the same hashtags in the last 30 days and the most followed
+// accounts. They score one point per account the user follows that follows
+// them, two when they follow the user, half a point per shared hashtag and a
+// little for their follower count.
+func (s *suggestionRepo) Refresh(ctx context.Context, userID string) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		s.log.Error("error while beginning suggestions transaction", logger.Error(err))
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// concurrent refreshes of the same user would insert the same rows
+	if _, err = tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('user_suggestions:' || $1::text))`, userID); err != nil {
+		s.log.Error("error while locking user suggestions", logger.Error(err))
+		return err
+	}
+
+	if _, err = tx.Exec(ctx, `DELETE FROM user_suggestions WHERE user_id = $1`, userID); err != nil {
+		s.log.Error("error while deleting user suggestions", logger.Error(err))
+		return err
+	}
+
+	query := `
+		WITH followed AS (
+			SELECT user_id FROM followers WHERE follower_user_id = $1
+		), friends_of_friends AS (
+			SELECT f.user_id AS candidate, COUNT(1) AS followed_by_count,
+				(array_agg(f.follower_user_id ORDER BY f.created_at DESC))[1:3] AS followed_by_ids
+			FROM followers f
+			WHERE f.follower_user_id IN (SELECT user_id FROM followed)
+			GROUP BY f.user_id
+		), followers_of_user AS (
+			SELECT follower_user_id AS candidate FROM followers WHERE user_id = $1
+		), shared_hashtags AS (
+			SELECT th.user_id AS candidate, COUNT(DISTINCT th.hashtag) AS shared_count,
+				(array_agg(DISTINCT th.hashtag))[1:3] AS hashtags
+			FROM tweet_hashtags th
+			WHERE th.created_at > NOW() - INTERVAL '30 days'
+			AND th.hashtag IN (
+				SELECT hashtag FROM tweet_hashtags WHERE user_id = $1 AND created_at > NOW() - INTERVAL '30 days'
+			)
+			GROUP BY th.user_id
+		), popular AS (
+			SELECT user_id AS candidate FROM followers GROUP BY user_id ORDER BY COUNT(1) DESC LIMIT 20
+		), candidates AS (
+			SELECT candidate FROM friends_of_friends
+			UNION SELECT candidate FROM followers_of_user
+			UNION SELECT candidate FROM shared_hashtags
+			UNION SELECT candidate FROM popular
+		)
+		INSERT INTO user_suggestions (user_id, suggested_user_id, score, followed_by_count, followed_by_ids, follows_you, shared_hashtags)
+		SELECT $1::uuid, c.candidate,
+			COALESCE(fof.followed_by_count, 0)
+				+ CASE WHEN fu.candidate IS NULL THEN 0 ELSE 2 END
+				+ COALESCE(sh.shared_count, 0) * 0.5
+				+ ln(1 + (SELECT COUNT(1) FROM followers pf WHERE pf.user_id = c.candidate)) * 0.1,
+			COALESCE(fof.followed_by_count, 0),
+			COALESCE(fof.followed_by_ids, '{}'),
+			fu.candidate IS NOT NULL,
+			COALESCE(sh.hashtags, '{}')
+		FROM candidates c
+		LEFT JOIN friends_of_friends fof ON fof.candidate = c.candidate
+		LEFT JOIN followers_of_user fu ON fu.candidate = c.candidate
+		LEFT JOIN shared_hashtags sh ON sh.candidate = c.candidate
+		WHERE ` + suggestionAllowed("c.candidate", "$1") + `
+		ORDER BY 3 DESC
+		LIMIT 100
+	`
+	if _, err = tx.Exec(ctx, query, userID); err != nil {
+		s.log.Error("error while inserting user suggestions", logger.Error(err))
+		return err
+	}
+
+	query = `
+		INSERT INTO user_suggestion_runs (user_id, computed_at) VALUES ($1, NOW())
+		ON CONFLICT (user_id) DO UPDATE SET computed_at = EXCLUDED.computed_at
+	`
+	if _, err = tx.Exec(ctx, query, userID); err != nil {
+		s.log.Error("error while recording user suggestions run", logger.Error(err))
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		s.log.Error("error while committing suggestions transaction", logger.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// IsComputed reports whether the suggestions of the user were ever computed.
+func (s *suggestionRepo) IsComputed(ctx context.Context, userID string) (bool, error) {
+	var exists bool
+	query := `SELECT EXISTS (SELECT 1 FROM user_suggestion_runs WHERE user_id = $1)`
+	if err := s.db.QueryRow(ctx, query, userID).Scan(&exists); err != nil {
+		s.log.Error("error while checking user suggestions run", logger.Error(err))
+		return false, err
+	}
+
+	return exists, nil
+}
+
+// GetStaleUsers lists the users whose suggestions were computed before
+// computedBefore, or never, least recently computed first.
+func (s *suggestionRepo) GetStaleUsers(ctx context.Context, computedBefore time.Time, limit int) ([]string, error) {
+	ids := []string{}
+	query := `
+		SELECT u.user_id
+		FROM users u
+		LEFT JOIN user_suggestion_runs r ON r.user_id = u.user_id
+		WHERE r.computed_at IS NULL OR r.computed_at < $1
+		ORDER BY r.computed_at NULLS FIRST
+		LIMIT $2
+	`
+	rows, err := s.db.Query(ctx, query, computedBefore, limit)
+	if err != nil {
+		s.log.Error("error while selecting users with stale suggestions", logger.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+		if err = rows.Scan(&id); err != nil {
+			s.log.Error("error while scanning user with stale suggestions", logger.Error(err))
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// GetList lists the suggestions of req.UserID, best first, leaving out the
+// accounts followed, blocked or muted since they were computed.
+func (s *suggestionRepo) GetList(ctx context.Context, req models.GetListRequest) (models.UserSuggestionsResponse, error) {
+	var (
+		count  = 0
+		offset = (req.Page - 1) * req.Limit
+	)
+
+	filter := `
+		FROM user_suggestions s
+		JOIN users u ON u.user_id = s.suggested_user_id
+		WHERE s.user_id = $1 AND ` + suggestionAllowed("s.suggested_user_id", "$1")
+
+	if err := s.db.QueryRow(ctx, `SELECT COUNT(1)`+filter, req.UserID).Scan(&count); err != nil {
+		s.log.Error("error while counting user suggestions", logger.Error(err))
+		return models.UserSuggestionsResponse{}, err
+	}
+
+	query := `
+		SELECT ` + userSummaryColumns + `, s.followed_by_count, s.followed_by_ids, s.follows_you, s.shared_hashtags
+		` + filter + `
+		ORDER BY s.score DESC, s.suggested_user_id
+		LIMIT $2 OFFSET $3
+	`
+	rows, err := s.db.Query(ctx, query, req.UserID, req.Limit, offset)
+	if err != nil {
+		s.log.Error("error while selecting user suggestions", logger.Error(err))
+		return models.UserSuggestionsResponse{}, err
+	}
+	defer rows.Close()
+
+	var (
+		suggestions   = []models.UserSuggestion{}
+		followedByIDs = [][]string{}
+		allIDs        = []string{}
+	)
+	for rows.Next() {
+		var (
+			suggestion = models.UserSuggestion{}
+			ids        []string
+		)
+		if err = rows.Scan(
+			&suggestion.User.ID, &suggestion.User.Username, &suggestion.User.Name, &suggestion.User.ProfilePicture, &suggestion.User.Protected,
+			&suggestion.FollowedByCount, &ids, &suggestion.FollowsYou, &suggestion.SharedHashtags,
+		); err != nil {
+			s.log.Error("error while scanning user suggestion", logger.Error(err))
+			return models.UserSuggestionsResponse{}, err
+		}
+		suggestions = append(suggestions, suggestion)
+		followedByIDs = append(followedByIDs, ids)
+		allIDs = append(allIDs, ids...)
+	}
+	if err = rows.Err(); err != nil {
+		s.log.Error("error while reading user suggestions", logger.Error(err))
+		return models.UserSuggestionsResponse{}, err
+	}
+
+	// the accounts the suggestions are followed by, still followed by the user
+	query = `
+		SELECT ` + userSummaryColumns + `
+		FROM users u
+		WHERE u.user_id = ANY($1::uuid[])
+		AND EXISTS (SELECT 1 FROM followers f WHERE f.user_id = u.user_id AND f.follower_user_id = $2)
+	`
+	rows, err = s.db.Query(ctx, query, allIDs, req.UserID)
+	if err != nil {
+		s.log.Error("error while selecting suggestion followers", logger.Error(err))
+		return models.UserSuggestionsResponse{}, err
+	}
+
+	users, err := scanUserSummaries(rows)
+	if err != nil {
+		s.log.Error("error while scanning suggestion followers", logger.Error(err))
+		return models.UserSuggestionsResponse{}, err
+	}
+
+	byID := make(map[string]models.UserSummary, len(users))
+	for _, user := range users {
+		byID[user.ID] = user
+	}
+
+	for i := range suggestions {
+		suggestions[i].FollowedBy = []models.UserSummary{}
+		for _, id := range followedByIDs[i] {
+			if user, ok := byID[id]; ok {
+				suggestions[i].FollowedBy = append(suggestions[i].FollowedBy, user)
+			}
+		}
+	}
+
+	return models.UserSuggestionsResponse{
+		Suggestions: suggestions,
+		Count:       count,
+	}, nil
+}
@@ -3,11 +3,15 @@ package postgres
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"test/api/models"
 	"test/pkg/logger"
+	"test/pkg/search"
 	"test/storage"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -23,14 +27,23 @@ func NewTweetRepo(db *pgxpool.Pool, log logger.ILogger) storage.ITweetsStorage {
 	}
 }
 
+// Create inserts the tweet with its poll, mentions and hashtags and attaches
+// its media, which must be uploaded and not attached to another tweet.
 func (t *tweetRepo) Create(ctx context.Context, createTweet models.CreateTweet) (string, error) {
 	id := uuid.New()
 
+	tx, err := t.db.Begin(ctx)
+	if err != nil {
+		t.log.Error("error while beginning tweet transaction", logger.Error(err))
+		return "", err
+	}
+	defer tx.Rollback(ctx)
+
 	query := `
-		INSERT INTO tweets (tweet_id, user_id, content, image_url, video_url)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO tweets (tweet_id, user_id, content, reply_to_tweet_id, conversation_id, reply_audience, draft_id)
+		VALUES ($1, $2, $3, $4, COALESCE((SELECT conversation_id FROM tweets WHERE tweet_id = $4), $1), $5, $6)
 	`
-	cmdTag, err := t.db.Exec(ctx, query, id, createTweet.UserID, createTweet.Content, createTweet.ImageURL, createTweet.VideoURL)
+	cmdTag, err := tx.Exec(ctx, query, id, createTweet.UserID, createTweet.Content, createTweet.ReplyToTweetID, createTweet.ReplyAudience, createTweet.DraftID)
 	if err != nil {
 		t.log.Error("error while inserting tweet data", logger.Error(err))
 		return "", err
@@ -41,18 +54,64 @@ func (t *tweetRepo) Create(ctx context.Context, createTweet models.CreateTweet)
 		return "", fmt.Errorf("no rows affected")
 	}
 
+	if len(createTweet.MediaIDs) > 0 {
+		query = `
+			UPDATE media m SET tweet_id = $1, position = o.position, updated_at = NOW()
+			FROM unnest($3::uuid[]) WITH ORDINALITY AS o(media_id, position)
+			WHERE m.media_id = o.media_id AND m.user_id = $2 AND m.tweet_id IS NULL AND m.state = ANY($4::text[]) AND m.purpose = $5
+		`
+		states := []string{models.MediaStateProcessing, models.MediaStateReady}
+		cmdTag, err = tx.Exec(ctx, query, id, createTweet.UserID, createTweet.MediaIDs, states, models.MediaPurposeTweet)
+		if err != nil {
+			t.log.Error("error while attaching tweet media", logger.Error(err))
+			return "", err
+		}
+
+		if cmdTag.RowsAffected() != int64(len(createTweet.MediaIDs)) {
+			t.log.Error("media attached to tweet meanwhile", logger.Any("media_ids", createTweet.MediaIDs))
+			return "", fmt.Errorf("media is no longer available")
+		}
+	}
+
+	if poll := createTweet.Poll; poll != nil {
+		query = `INSERT INTO polls (tweet_id, ends_at) VALUES ($1, NOW() + make_interval(mins => $2))`
+		if _, err = tx.Exec(ctx, query, id, poll.DurationMinutes); err != nil {
+			t.log.Error("error while inserting tweet poll", logger.Error(err))
+			return "", err
+		}
+
+		query = `
+			INSERT INTO poll_options (tweet_id, position, label)
+			SELECT $1, o.position, o.label
+			FROM unnest($2::text[]) WITH ORDINALITY AS o(label, position)
+		`
+		if _, err = tx.Exec(ctx, query, id, poll.Options); err != nil {
+			t.log.Error("error while inserting tweet poll options", logger.Error(err))
+			return "", err
+		}
+	}
+
+	if err = t.setMentions(ctx, tx, id.String(), createTweet.MentionedUserIDs); err != nil {
+		return "", err
+	}
+
+	if err = t.setHashtags(ctx, tx, id.String(), createTweet.Hashtags); err != nil {
+		return "", err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		t.log.Error("error while committing tweet transaction", logger.Error(err))
+		return "", err
+	}
+
 	return id.String(), nil
 }
 
 func (t *tweetRepo) GetByID(ctx context.Context, tweetID models.PrimaryKey) (models.Tweet, error) {
 	tweet := models.Tweet{}
 
-	query := `
-		SELECT tweet_id, user_id, content, image_url, video_url, created_at, updated_at
-		FROM tweets
-		WHERE tweet_id = $1
-	`
-	err := t.db.QueryRow(ctx, query, tweetID).Scan(&tweet.ID, &tweet.UserID, &tweet.Content, &tweet.ImageURL, &tweet.VideoURL, &tweet.CreatedAt, &tweet.UpdatedAt)
+	query := `SELECT ` + tweetColumns + ` FROM tweets t WHERE t.tweet_id = $1`
+	err := scanTweet(t.db.QueryRow(ctx, query, tweetID.ID), &tweet)
 	if err != nil {
 		t.log.Error("error while scanning tweet", logger.Error(err))
 		return models.Tweet{}, err
@@ -61,48 +120,144 @@ func (t *tweetRepo) GetByID(ctx context.Context, tweetID models.PrimaryKey) (mod
 	return tweet, nil
 }
 
+// GetByDraftID loads the tweet published from the draft.
+func (t *tweetRepo) GetByDraftID(ctx context.Context, draftID string) (models.Tweet, error) {
+	tweet := models.Tweet{}
+
+	query := `SELECT ` + tweetColumns + ` FROM tweets t WHERE t.draft_id = $1`
+	if err := scanTweet(t.db.QueryRow(ctx, query, draftID), &tweet); err != nil {
+		t.log.Error("error while scanning tweet by draft", logger.Error(err))
+		return models.Tweet{}, err
+	}
+
+	return tweet, nil
+}
+
+// GetList lists the tweets the viewer may see and has not muted, most recent
+// first. Searching goes through Search.
 func (t *tweetRepo) GetList(ctx context.Context, request models.GetListRequest) (models.TweetsResponse, error) {
 	var (
-		tweets = []models.Tweet{}
 		count  = 0
 		page   = request.Page
 		offset = (page - 1) * request.Limit
-		search = request.Search
 	)
 
 	// Count Query
-	countQuery := `
-		SELECT COUNT(1)
-		FROM tweets
-		WHERE content ILIKE '%' || $1 || '%'
-	`
+	filter := `
+		WHERE ` + tweetVisibleToViewer("$1") + `
+		AND ` + notMuted("t.user_id", "t.content", "$1")
 
-	err := t.db.QueryRow(ctx, countQuery, search).Scan(&count)
+	countQuery := `SELECT COUNT(1) FROM tweets t` + filter
+
+	err := t.db.QueryRow(ctx, countQuery, request.ViewerID).Scan(&count)
 	if err != nil {
 		t.log.Error("error while counting tweets", logger.Error(err))
 		return models.TweetsResponse{}, err
 	}
 
 	// Main Query
-	query := `
-		SELECT tweet_id, user_id, content, image_url, video_url, created_at, updated_at
-		FROM tweets
-		WHERE content ILIKE '%' || $1 || '%'
-		ORDER BY created_at DESC LIMIT $2 OFFSET $3
+	query := `SELECT ` + tweetColumns + ` FROM tweets t` + filter + `
+		ORDER BY t.created_at DESC LIMIT $2 OFFSET $3
 	`
-	rows, err := t.db.Query(ctx, query, search, request.Limit, offset)
+	rows, err := t.db.Query(ctx, query, request.ViewerID, request.Limit, offset)
 	if err != nil {
 		t.log.Error("error while querying tweets", logger.Error(err))
 		return models.TweetsResponse{}, err
 	}
+
+	tweets, err := scanTweets(rows)
+	if err != nil {
+		t.log.Error("error while scanning tweet row", logger.Error(err))
+		return models.TweetsResponse{}, err
+	}
+
+	return models.TweetsResponse{
+		Tweets: tweets,
+		Count:  count,
+	}, nil
+}
+
+// Search lists the tweets matching the query that the viewer may see and has
+// not muted. Matches are ranked by relevance divided by one plus their age in
+// weeks, and come with a highlighted snippet of the content.
+func (t *tweetRepo) Search(ctx context.Context, q search.Query, request models.GetListRequest) (models.TweetsResponse, error) {
+	var (
+		count  = 0
+		offset = (request.Page - 1) * request.Limit
+		args   = []interface{}{request.ViewerID, q.Text}
+	)
+
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	filter := `
+		FROM tweets t, (SELECT websearch_to_tsquery('simple', $2::text) AS query) q
+		WHERE ` + tweetVisibleToViewer("$1") + `
+		AND ` + notMuted("t.user_id", "t.content", "$1")
+
+	if q.Text != "" {
+		filter += ` AND t.search_vector @@ q.query`
+	}
+	if len(q.From) > 0 {
+		filter += ` AND t.user_id IN (SELECT user_id FROM users WHERE lower(username) = ANY(` + arg(q.From) + `::text[]))`
+	}
+	if len(q.ExcludeFrom) > 0 {
+		filter += ` AND t.user_id NOT IN (SELECT user_id FROM users WHERE lower(username) = ANY(` + arg(q.ExcludeFrom) + `::text[]))`
+	}
+	for _, hashtag := range q.Hashtags {
+		filter += ` AND lower(t.content) ~ ('(^|[^[:alnum:]_])#' || ` + arg(hashtag) + `::text || '($|[^[:alnum:]_])')`
+	}
+	for _, hashtag := range q.ExcludeHashtags {
+		filter += ` AND lower(t.content) !~ ('(^|[^[:alnum:]_])#' || ` + arg(hashtag) + `::text || '($|[^[:alnum:]_])')`
+	}
+	if q.Since != nil {
+		filter += ` AND t.created_at >= ` + arg(*q.Since) + `::timestamp`
+	}
+	if q.Until != nil {
+		filter += ` AND t.created_at < ` + arg(*q.Until) + `::timestamp`
+	}
+	if q.Media {
+		filter += ` AND (COALESCE(t.image_url, '') <> '' OR COALESCE(t.video_url, '') <> '' OR EXISTS (SELECT 1 FROM media m WHERE m.tweet_id = t.tweet_id))`
+	}
+	if q.ExcludeMedia {
+		filter += ` AND COALESCE(t.image_url, '') = '' AND COALESCE(t.video_url, '') = '' AND NOT EXISTS (SELECT 1 FROM media m WHERE m.tweet_id = t.tweet_id)`
+	}
+
+	if err := t.db.QueryRow(ctx, `SELECT COUNT(1)`+filter, args...).Scan(&count); err != nil {
+		t.log.Error("error while counting searched tweets", logger.Error(err))
+		return models.TweetsResponse{}, err
+	}
+
+	headlineOptions := arg("StartSel=" + search.HighlightStart + ", StopSel=" + search.HighlightStop + ", MaxFragments=2, MaxWords=30, MinWords=10")
+	query := `
+		SELECT ` + tweetColumns + `,
+			CASE WHEN $2::text = '' THEN '' ELSE ts_headline('simple', t.content, q.query, ` + headlineOptions + `::text) END
+		` + filter + `
+		ORDER BY
+			ts_rank_cd(t.search_vector, q.query, 32) / (1 + EXTRACT(EPOCH FROM NOW() - t.created_at)::float8 / 604800) DESC,
+			t.created_at DESC
+		LIMIT ` + arg(request.Limit) + ` OFFSET ` + arg(offset)
+
+	rows, err := t.db.Query(ctx, query, args...)
+	if err != nil {
+		t.log.Error("error while searching tweets", logger.Error(err))
+		return models.TweetsResponse{}, err
+	}
 	defer rows.Close()
 
+	tweets := []models.Tweet{}
 	for rows.Next() {
 		tweet := models.Tweet{}
-		if err := rows.Scan(&tweet.ID, &tweet.UserID, &tweet.Content, &tweet.ImageURL, &tweet.VideoURL, &tweet.CreatedAt, &tweet.UpdatedAt); err != nil {
-			t.log.Error("error while scanning tweet row", logger.Error(err))
+		if err = rows.Scan(
+			&tweet.ID, &tweet.UserID, &tweet.Content, &tweet.ImageURL, &tweet.VideoURL,
+			&tweet.ReplyToTweetID, &tweet.ConversationID, &tweet.ReplyAudience, &tweet.EditCount, &tweet.Hidden, &tweet.CreatedAt, &tweet.UpdatedAt, &tweet.Highlight,
+		); err != nil {
+			t.log.Error("error while scanning searched tweet", logger.Error(err))
 			return models.TweetsResponse{}, err
 		}
+		tweet.Highlight = search.Highlight(tweet.Highlight)
 		tweets = append(tweets, tweet)
 	}
 
@@ -112,29 +267,134 @@ func (t *tweetRepo) GetList(ctx context.Context, request models.GetListRequest)
 	}, nil
 }
 
-func (t *tweetRepo) Update(ctx context.Context, updateTweet models.UpdateTweet) (string, error) {
+// Update replaces the content of the tweet if it belongs to
+// updateTweet.UserID, was posted within window and was edited less than
+// maxEdits times. The new content is recorded as a revision, along with the
+// original one on the first edit, and the mentions and hashtags of the tweet
+// are replaced with those of updateTweet, all or nothing. Tweets that cannot be
+// edited are reported as pgx.ErrNoRows.
+func (t *tweetRepo) Update(ctx context.Context, updateTweet models.UpdateTweet, window time.Duration, maxEdits int) (string, error) {
+	tx, err := t.db.Begin(ctx)
+	if err != nil {
+		t.log.Error("error while beginning tweet update transaction", logger.Error(err))
+		return "", err
+	}
+	defer tx.Rollback(ctx)
+
 	query := `
-		UPDATE tweets
-		SET content = $1, image_url = $2, video_url = $3, updated_at = NOW()
-		WHERE tweet_id = $4
+		WITH previous AS (
+			SELECT tweet_id, content, edit_count, created_at
+			FROM tweets
+			WHERE tweet_id = $1 AND user_id = $2 AND edit_count < $3 AND created_at > NOW() - $4::float8 * INTERVAL '1 second'
+			FOR UPDATE
+		), updated AS (
+			UPDATE tweets t
+			SET content = $5, edit_count = t.edit_count + 1, updated_at = NOW()
+			FROM previous p
+			WHERE t.tweet_id = p.tweet_id
+			RETURNING t.tweet_id, t.edit_count
+		)
+		INSERT INTO tweet_edits (tweet_id, revision, content, created_at)
+		SELECT p.tweet_id, 0, p.content, p.created_at FROM previous p WHERE p.edit_count = 0 AND EXISTS (SELECT 1 FROM updated)
+		UNION ALL
+		SELECT u.tweet_id, u.edit_count, $5::text, NOW() FROM updated u
 	`
-	cmdTag, err := t.db.Exec(ctx, query, updateTweet.Content, updateTweet.ImageURL, updateTweet.VideoURL, updateTweet.ID)
+	cmdTag, err := tx.Exec(ctx, query, updateTweet.ID, updateTweet.UserID, maxEdits, window.Seconds(), updateTweet.Content)
 	if err != nil {
 		t.log.Error("error while updating tweet data", logger.Error(err))
 		return "", err
 	}
 
 	if cmdTag.RowsAffected() == 0 {
-		t.log.Error("no rows affected while updating tweet", logger.Error(err))
-		return "", fmt.Errorf("no rows affected")
+		t.log.Error("no rows affected while updating tweet")
+		return "", pgx.ErrNoRows
+	}
+
+	if err = t.setMentions(ctx, tx, updateTweet.ID, updateTweet.MentionedUserIDs); err != nil {
+		return "", err
+	}
+
+	if err = t.setHashtags(ctx, tx, updateTweet.ID, updateTweet.Hashtags); err != nil {
+		return "", err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		t.log.Error("error while committing tweet update transaction", logger.Error(err))
+		return "", err
 	}
 
 	return updateTweet.ID, nil
 }
 
+// UpdateReplyAudience changes who can reply to the tweet if it belongs to
+// request.UserID, reported as pgx.ErrNoRows otherwise.
+func (t *tweetRepo) UpdateReplyAudience(ctx context.Context, request models.UpdateReplyAudience) error {
+	query := `UPDATE tweets SET reply_audience = $3, updated_at = NOW() WHERE tweet_id = $1 AND user_id = $2`
+	cmdTag, err := t.db.Exec(ctx, query, request.TweetID, request.UserID, request.ReplyAudience)
+	if err != nil {
+		t.log.Error("error while updating tweet reply audience", logger.Error(err))
+		return err
+	}
+
+	if cmdTag.RowsAffected() == 0 {
+		t.log.Error("no rows affected while updating tweet reply audience")
+		return pgx.ErrNoRows
+	}
+
+	return nil
+}
+
+// GetRepliableIDs returns which of the tweets the user may reply to, as their
+// reply audience allows and neither side blocked the other.
+func (t *tweetRepo) GetRepliableIDs(ctx context.Context, tweetIDs []string, userID string) ([]string, error) {
+	ids := []string{}
+	query := `SELECT t.tweet_id FROM tweets t WHERE t.tweet_id = ANY($1::uuid[]) AND ` + replyAllowed("$2") + ` AND ` + notBlocked("t.user_id", "$2")
+	rows, err := t.db.Query(ctx, query, tweetIDs, userID)
+	if err != nil {
+		t.log.Error("error while selecting repliable tweets", logger.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+		if err = rows.Scan(&id); err != nil {
+			t.log.Error("error while scanning repliable tweet", logger.Error(err))
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// GetRevisions lists the revisions of an edited tweet, the original first.
+// Tweets never edited have none.
+func (t *tweetRepo) GetRevisions(ctx context.Context, tweetID string) ([]models.TweetRevision, error) {
+	revisions := []models.TweetRevision{}
+	query := `SELECT revision, content, created_at FROM tweet_edits WHERE tweet_id = $1 ORDER BY revision`
+	rows, err := t.db.Query(ctx, query, tweetID)
+	if err != nil {
+		t.log.Error("error while selecting tweet revisions", logger.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		revision := models.TweetRevision{}
+		if err = rows.Scan(&revision.Revision, &revision.Content, &revision.CreatedAt); err != nil {
+			t.log.Error("error while scanning tweet revision", logger.Error(err))
+			return nil, err
+		}
+		revisions = append(revisions, revision)
+	}
+
+	return revisions, rows.Err()
+}
+
 func (t *tweetRepo) Delete(ctx context.Context, tweetID models.PrimaryKey) error {
 	query := `DELETE FROM tweets WHERE tweet_id = $1`
-	cmdTag, err := t.db.Exec(ctx, query, tweetID)
+	cmdTag, err := t.db.Exec(ctx, query, tweetID.ID)
 	if err != nil {
 		t.log.Error("error while deleting tweet", logger.Error(err))
 		return err
@@ -147,3 +407,136 @@ func (t *tweetRepo) Delete(ctx context.Context, tweetID models.PrimaryKey) error
 
 	return nil
 }
+
+// setMentions replaces the users recorded as mentioned by the tweet within tx.
+func (t *tweetRepo) setMentions(ctx context.Context, tx pgx.Tx, tweetID string, userIDs []string) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM tweet_mentions WHERE tweet_id = $1`, tweetID); err != nil {
+		t.log.Error("error while deleting tweet mentions", logger.Error(err))
+		return err
+	}
+
+	query := `
+		INSERT INTO tweet_mentions (tweet_id, user_id)
+		SELECT $1, unnest($2::uuid[])
+		ON CONFLICT DO NOTHING
+	`
+	if _, err := tx.Exec(ctx, query, tweetID, userIDs); err != nil {
+		t.log.Error("error while inserting tweet mentions", logger.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// setHashtags replaces the hashtags recorded for the tweet within tx.
+func (t *tweetRepo) setHashtags(ctx context.Context, tx pgx.Tx, tweetID string, hashtags []string) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM tweet_hashtags WHERE tweet_id = $1`, tweetID); err != nil {
+		t.log.Error("error while deleting tweet hashtags", logger.Error(err))
+		return err
+	}
+
+	query := `
+		INSERT INTO tweet_hashtags (tweet_id, user_id, hashtag, created_at)
+		SELECT t.tweet_id, t.user_id, h.hashtag, t.created_at
+		FROM tweets t, unnest($2::text[]) AS h(hashtag)
+		WHERE t.tweet_id = $1
+		ON CONFLICT DO NOTHING
+	`
+	if _, err := tx.Exec(ctx, query, tweetID, hashtags); err != nil {
+		t.log.Error("error while inserting tweet hashtags", logger.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// GetMentions lists the tweets mentioning request.UserID, leaving out tweets
+// the user may not see, has muted or belonging to muted conversations.
+func (t *tweetRepo) GetMentions(ctx context.Context, request models.GetListRequest) (models.TweetsResponse, error) {
+	var (
+		count  = 0
+		offset = (request.Page - 1) * request.Limit
+	)
+
+	filter := `
+		WHERE m.user_id = $1
+		AND ` + tweetVisibleToViewer("$1") + `
+		AND ` + notMuted("t.user_id", "t.content", "$1") + `
+		AND ` + notMutedConversation("t.conversation_id", "$1")
+
+	countQuery := `SELECT COUNT(1) FROM tweet_mentions m JOIN tweets t ON t.tweet_id = m.tweet_id` + filter
+	if err := t.db.QueryRow(ctx, countQuery, request.UserID).Scan(&count); err != nil {
+		t.log.Error("error while counting mentions", logger.Error(err))
+		return models.TweetsResponse{}, err
+	}
+
+	query := `
+		SELECT ` + tweetColumns + `
+		FROM tweet_mentions m
+		JOIN tweets t ON t.tweet_id = m.tweet_id` + filter + `
+		ORDER BY t.created_at DESC LIMIT $2 OFFSET $3
+	`
+	rows, err := t.db.Query(ctx, query, request.UserID, request.Limit, offset)
+	if err != nil {
+		t.log.Error("error while querying mentions", logger.Error(err))
+		return models.TweetsResponse{}, err
+	}
+
+	tweets, err := scanTweets(rows)
+	if err != nil {
+		t.log.Error("error while scanning mention row", logger.Error(err))
+		return models.TweetsResponse{}, err
+	}
+
+	return models.TweetsResponse{
+		Tweets: tweets,
+		Count:  count,
+	}, nil
+}
+
+// GetAudience lists the users whose home timeline shows the tweet: its author
+// and the followers who muted neither the author nor a keyword of the tweet.
+func (t *tweetRepo) GetAudience(ctx context.Context, tweetID string) ([]string, error) {
+	userIDs := []string{}
+	query := `
+		SELECT t.user_id FROM tweets t WHERE t.tweet_id = $1
+		UNION
+		SELECT f.follower_user_id
+		FROM tweets t
+		JOIN followers f ON f.user_id = t.user_id
+		WHERE t.tweet_id = $1
+		AND ` + notMuted("t.user_id", "t.content", "f.follower_user_id")
+	rows, err := t.db.Query(ctx, query, tweetID)
+	if err != nil {
+		t.log.Error("error while selecting tweet audience", logger.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var userID string
+		if err = rows.Scan(&userID); err != nil {
+			t.log.Error("error while scanning tweet audience", logger.Error(err))
+			return nil, err
+		}
+		userIDs = append(userIDs, userID)
+	}
+
+	return userIDs, nil
+}
+
+func (t *tweetRepo) GetCounters(ctx context.Context, tweetID string) (models.TweetCounters, error) {
+	counters := models.TweetCounters{TweetID: tweetID}
+	query := `
+		SELECT
+			(SELECT COUNT(1) FROM likes WHERE tweet_id = $1),
+			(SELECT COUNT(1) FROM retweets WHERE tweet_id = $1),
+			(SELECT COUNT(1) FROM tweets WHERE reply_to_tweet_id = $1)
+	`
+	if err := t.db.QueryRow(ctx, query, tweetID).Scan(&counters.LikeCount, &counters.RetweetCount, &counters.ReplyCount); err != nil {
+		t.log.Error("error while counting tweet interactions", logger.Error(err))
+		return models.TweetCounters{}, err
+	}
+
+	return counters, nil
+}
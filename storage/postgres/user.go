@@ -5,9 +5,11 @@ import (
 	"fmt"
 	"test/api/models"
 	"test/pkg/logger"
+	"test/pkg/search"
 	"test/storage"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -27,10 +29,10 @@ func (u *userRepo) Create(ctx context.Context, createUser models.CreateUser) (st
 	uid := uuid.New()
 
 	query := `
-		INSERT INTO users (user_id, username, password_hash, name, bio, profile_picture)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO users (user_id, username, password_hash, name, bio)
+		VALUES ($1, $2, $3, $4, $5)
 	`
-	cmdTag, err := u.db.Exec(ctx, query, uid, createUser.Username, createUser.Password, createUser.Name, createUser.Bio, createUser.ProfilePicture)
+	cmdTag, err := u.db.Exec(ctx, query, uid, createUser.Username, createUser.Password, createUser.Name, createUser.Bio)
 	if err != nil {
 		u.log.Error("error while inserting user data", logger.Error(err))
 		return "", err
@@ -48,11 +50,11 @@ func (u *userRepo) GetByID(ctx context.Context, pKey models.PrimaryKey) (models.
 	user := models.User{}
 
 	query := `
-		SELECT user_id, username, password_hash, name, bio, profile_picture, created_at, updated_at
-		FROM users
-		WHERE user_id = $1
+		SELECT u.user_id, u.username, u.password_hash, u.name, u.bio, ` + avatarURLColumn + `, ` + bannerURLColumn + `, u.protected, u.pinned_tweet_id, u.created_at, u.updated_at
+		FROM users u
+		WHERE u.user_id = $1
 	`
-	err := u.db.QueryRow(ctx, query, pKey.ID).Scan(&user.ID, &user.Username, &user.PasswordHash, &user.Name, &user.Bio, &user.ProfilePicture, &user.CreatedAt, &user.UpdatedAt)
+	err := u.db.QueryRow(ctx, query, pKey.ID).Scan(&user.ID, &user.Username, &user.PasswordHash, &user.Name, &user.Bio, &user.ProfilePicture, &user.Banner, &user.Protected, &user.PinnedTweetID, &user.CreatedAt, &user.UpdatedAt)
 	if err != nil {
 		u.log.Error("error while scanning user", logger.Error(err))
 		return models.User{}, err
@@ -67,42 +69,28 @@ func (u *userRepo) GetList(ctx context.Context, request models.GetListRequest) (
 		count  = 0
 		page   = request.Page
 		offset = (page - 1) * request.Limit
-		search = request.Search
+		search = search.EscapeLike(request.Search)
 	)
 
-	countQuery := `
-		SELECT COUNT(1)
-		FROM users
-	`
-
-	if search != "" {
-		countQuery += ` WHERE username ILIKE $1 OR name ILIKE $2`
-	}
+	filter := `
+		WHERE ($1 = '' OR lower(u.username) LIKE '%' || lower($1) || '%' OR lower(u.name) LIKE '%' || lower($1) || '%')
+		AND ` + notBlocked("u.user_id", "$2")
 
-	args := []interface{}{}
-	if search != "" {
-		args = append(args, search, search)
-	}
+	countQuery := `SELECT COUNT(1) FROM users u` + filter
 
-	err := u.db.QueryRow(ctx, countQuery, args...).Scan(&count)
+	err := u.db.QueryRow(ctx, countQuery, search, request.ViewerID).Scan(&count)
 	if err != nil {
 		u.log.Error("error while counting users", logger.Error(err))
 		return models.UsersResponse{}, err
 	}
 
 	query := `
-		SELECT user_id, username, password_hash, name, bio, profile_picture, created_at, updated_at
-		FROM users
+		SELECT u.user_id, u.username, u.password_hash, u.name, u.bio, ` + avatarURLColumn + `, ` + bannerURLColumn + `, u.protected, u.created_at, u.updated_at
+		FROM users u` + filter + `
+		ORDER BY u.created_at DESC LIMIT $3 OFFSET $4
 	`
 
-	if search != "" {
-		query += ` WHERE username ILIKE $1 OR name ILIKE $2`
-	}
-
-	query += ` ORDER BY created_at DESC LIMIT $3 OFFSET $4`
-	args = append(args, request.Limit, offset)
-
-	rows, err := u.db.Query(ctx, query, args...)
+	rows, err := u.db.Query(ctx, query, search, request.ViewerID, request.Limit, offset)
 	if err != nil {
 		u.log.Error("error while querying users", logger.Error(err))
 		return models.UsersResponse{}, err
@@ -111,7 +99,7 @@ func (u *userRepo) GetList(ctx context.Context, request models.GetListRequest) (
 
 	for rows.Next() {
 		user := models.User{}
-		if err := rows.Scan(&user.ID, &user.Username, &user.PasswordHash, &user.Name, &user.Bio, &user.ProfilePicture, &user.CreatedAt, &user.UpdatedAt); err != nil {
+		if err := rows.Scan(&user.ID, &user.Username, &user.PasswordHash, &user.Name, &user.Bio, &user.ProfilePicture, &user.Banner, &user.Protected, &user.CreatedAt, &user.UpdatedAt); err != nil {
 			u.log.Error("error while scanning user row", logger.Error(err))
 			return models.UsersResponse{}, err
 		}
@@ -127,10 +115,10 @@ func (u *userRepo) GetList(ctx context.Context, request models.GetListRequest) (
 func (u *userRepo) Update(ctx context.Context, request models.UpdateUser) (models.User, error) {
 	query := `
 		UPDATE users
-		SET  name = $1, bio = $2, profile_picture = $3, updated_at = NOW()
-		WHERE user_id = $4
+		SET  name = $1, bio = $2, updated_at = NOW()
+		WHERE user_id = $3
 	`
-	cmdTag, err := u.db.Exec(ctx, query, request.Name, request.Bio, request.ProfilePicture, request.ID)
+	cmdTag, err := u.db.Exec(ctx, query, request.Name, request.Bio, request.ID)
 	if err != nil {
 		u.log.Error("error while updating user data", logger.Error(err))
 		return models.User{}, err
@@ -191,8 +179,8 @@ func (u *userRepo) UpdatePassword(ctx context.Context, request models.UpdateUser
 
 func (u *userRepo) GetUserCredentialsByLogin(ctx context.Context, login string) (models.User, error) {
 	user := models.User{}
-	query := `SELECT user_id, password_hash FROM users WHERE username = $1`
-	if err := u.db.QueryRow(ctx, query, login).Scan(&user.ID, &user.PasswordHash); err != nil {
+	query := `SELECT user_id, password_hash, role, suspended_at FROM users WHERE username = $1`
+	if err := u.db.QueryRow(ctx, query, login).Scan(&user.ID, &user.PasswordHash, &user.Role, &user.SuspendedAt); err != nil {
 		u.log.Error("error while retrieving admin credentials by login", logger.Error(err))
 		return models.User{}, err
 	}
@@ -200,3 +188,206 @@ func (u *userRepo) GetUserCredentialsByLogin(ctx context.Context, login string)
 	return user, nil
 }
 
+func (u *userRepo) GetSettings(ctx context.Context, id models.PrimaryKey) (models.UserSettings, error) {
+	settings := models.UserSettings{}
+	query := `SELECT protected, dm_from_following_only, alt_text_reminder FROM users WHERE user_id = $1`
+	if err := u.db.QueryRow(ctx, query, id.ID).Scan(&settings.Protected, &settings.DMFromFollowingOnly, &settings.AltTextReminder); err != nil {
+		u.log.Error("error while retrieving user settings", logger.Error(err))
+		return models.UserSettings{}, err
+	}
+
+	return settings, nil
+}
+
+// SetPinnedTweet pins the tweet to the profile of the user, or unpins it when
+// tweetID is nil. Tweets of others are not pinned, reported as pgx.ErrNoRows.
+func (u *userRepo) SetPinnedTweet(ctx context.Context, userID string, tweetID *string) error {
+	query := `
+		UPDATE users SET pinned_tweet_id = $2, updated_at = NOW()
+		WHERE user_id = $1
+		AND ($2::uuid IS NULL OR EXISTS (SELECT 1 FROM tweets t WHERE t.tweet_id = $2::uuid AND t.user_id = $1))
+	`
+	cmdTag, err := u.db.Exec(ctx, query, userID, tweetID)
+	if err != nil {
+		u.log.Error("error while setting pinned tweet", logger.Error(err))
+		return err
+	}
+
+	if cmdTag.RowsAffected() == 0 {
+		u.log.Error("no rows affected while setting pinned tweet")
+		return pgx.ErrNoRows
+	}
+
+	return nil
+}
+
+func (u *userRepo) UpdateSettings(ctx context.Context, request models.UpdateUserSettings) error {
+	query := `
+		UPDATE users SET
+			protected = COALESCE($1, protected),
+			dm_from_following_only = COALESCE($2, dm_from_following_only),
+			alt_text_reminder = COALESCE($3, alt_text_reminder),
+			updated_at = NOW()
+		WHERE user_id = $4
+	`
+	cmdTag, err := u.db.Exec(ctx, query, request.Protected, request.DMFromFollowingOnly, request.AltTextReminder, request.ID)
+	if err != nil {
+		u.log.Error("error while updating user settings", logger.Error(err))
+		return err
+	}
+
+	if cmdTag.RowsAffected() == 0 {
+		u.log.Error("no rows affected while updating user settings")
+		return fmt.Errorf("no rows affected")
+	}
+
+	return nil
+}
+
+// CanView reports whether viewerID may see the content of userID.
+func (u *userRepo) CanView(ctx context.Context, userID, viewerID string) (bool, error) {
+	var visible bool
+	query := `SELECT ` + visibleToViewer("$1::uuid", "$2")
+	if err := u.db.QueryRow(ctx, query, userID, viewerID).Scan(&visible); err != nil {
+		u.log.Error("error while checking user visibility", logger.Error(err))
+		return false, err
+	}
+
+	return visible, nil
+}
+
+// GetByUsernames finds the users with the given usernames, ignoring case.
+func (u *userRepo) GetByUsernames(ctx context.Context, usernames []string) ([]models.UserSummary, error) {
+	query := `SELECT ` + userSummaryColumns + ` FROM users u WHERE lower(u.username) = ANY($1)`
+	rows, err := u.db.Query(ctx, query, usernames)
+	if err != nil {
+		u.log.Error("error while selecting users by usernames", logger.Error(err))
+		return nil, err
+	}
+
+	users, err := scanUserSummaries(rows)
+	if err != nil {
+		u.log.Error("error while scanning users by usernames", logger.Error(err))
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// typeaheadPrefixMatch holds when the username, the name or a word of the name
+// of the user aliased u starts with the LIKE escaped prefix bound to param.
+func typeaheadPrefixMatch(param string) string {
+	return `(
+		lower(u.username) LIKE ` + param + ` || '%'
+		OR lower(u.name) LIKE ` + param + ` || '%'
+		OR lower(u.name) LIKE '% ' || ` + param + ` || '%'
+	)`
+}
+
+// GetTypeaheadCandidates finds the users best matching the lowercased prefix
+// for anyone: prefix matches first, then trigram similarity and follower count.
+func (u *userRepo) GetTypeaheadCandidates(ctx context.Context, prefix string, limit int) ([]string, error) {
+	ids := []string{}
+	query := `
+		SELECT u.user_id
+		FROM users u
+		WHERE ` + typeaheadPrefixMatch("$2") + `
+		OR lower(u.username) % $1
+		OR lower(u.name) % $1
+		ORDER BY
+			` + typeaheadPrefixMatch("$2") + ` DESC,
+			GREATEST(similarity(lower(u.username), $1), similarity(COALESCE(lower(u.name), ''), $1)) DESC,
+			(SELECT COUNT(1) FROM followers f WHERE f.user_id = u.user_id) DESC
+		LIMIT $3
+	`
+	rows, err := u.db.Query(ctx, query, prefix, search.EscapeLike(prefix), limit)
+	if err != nil {
+		u.log.Error("error while selecting typeahead candidates", logger.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+		if err = rows.Scan(&id); err != nil {
+			u.log.Error("error while scanning typeahead candidate", logger.Error(err))
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// Typeahead ranks the candidates together with the accounts the viewer follows
+// matching the prefix: exact username first, then prefix matches, followed
+// accounts, trigram similarity and follower count. Users blocked either way
+// are left out.
+func (u *userRepo) Typeahead(ctx context.Context, request models.TypeaheadRequest, candidateIDs []string) ([]models.TypeaheadUser, error) {
+	users := []models.TypeaheadUser{}
+	query := `
+		SELECT ` + userSummaryColumns + `,
+			EXISTS (SELECT 1 FROM followers f WHERE f.user_id = u.user_id AND f.follower_user_id = NULLIF($2::text, '')::uuid) AS following,
+			(SELECT COUNT(1) FROM followers f WHERE f.user_id = u.user_id) AS follower_count
+		FROM users u
+		WHERE (
+			u.user_id = ANY($1::uuid[])
+			OR (
+				u.user_id IN (SELECT f.user_id FROM followers f WHERE f.follower_user_id = NULLIF($2::text, '')::uuid)
+				AND ` + typeaheadPrefixMatch("$3") + `
+			)
+		)
+		AND ` + notBlocked("u.user_id", "$2") + `
+		ORDER BY
+			lower(u.username) = $4 DESC,
+			` + typeaheadPrefixMatch("$3") + ` DESC,
+			following DESC,
+			GREATEST(similarity(lower(u.username), $4), similarity(COALESCE(lower(u.name), ''), $4)) DESC,
+			follower_count DESC,
+			u.username
+		LIMIT $5
+	`
+	rows, err := u.db.Query(ctx, query, candidateIDs, request.ViewerID, search.EscapeLike(request.Query), request.Query, request.Limit)
+	if err != nil {
+		u.log.Error("error while selecting typeahead users", logger.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		user := models.TypeaheadUser{}
+		if err = rows.Scan(
+			&user.ID, &user.Username, &user.Name, &user.ProfilePicture, &user.Protected, &user.Following, &user.FollowerCount,
+		); err != nil {
+			u.log.Error("error while scanning typeahead user", logger.Error(err))
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	return users, rows.Err()
+}
+
+// GetRole returns the role of the user, which decides who moderates.
+func (u *userRepo) GetRole(ctx context.Context, userID string) (string, error) {
+	var role string
+	query := `SELECT role FROM users WHERE user_id = $1`
+	if err := u.db.QueryRow(ctx, query, userID).Scan(&role); err != nil {
+		u.log.Error("error while retrieving user role", logger.Error(err))
+		return "", err
+	}
+
+	return role, nil
+}
+
+// IsSuspended reports whether moderators suspended the user.
+func (u *userRepo) IsSuspended(ctx context.Context, userID string) (bool, error) {
+	var suspended bool
+	query := `SELECT suspended_at IS NOT NULL FROM users WHERE user_id = $1`
+	if err := u.db.QueryRow(ctx, query, userID).Scan(&suspended); err != nil {
+		u.log.Error("error while retrieving user suspension", logger.Error(err))
+		return false, err
+	}
+
+	return suspended, nil
+}
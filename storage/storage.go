@@ -3,6 +3,8 @@ package storage
 import (
 	"context"
 	"test/api/models"
+	"test/pkg/search"
+	"time"
 )
 
 type IStorage interface {
@@ -13,7 +15,18 @@ type IStorage interface {
 	Followers() IFollowersStorage
 	Likes() ILikesStorage
 	Retweets() IRetweetsStorage
-	
+	FollowRequests() IFollowRequestsStorage
+	Blocks() IBlocksStorage
+	Mutes() IMutesStorage
+	Notifications() INotificationsStorage
+	Messages() IMessagesStorage
+	Bookmarks() IBookmarksStorage
+	Lists() IListsStorage
+	Suggestions() ISuggestionsStorage
+	Media() IMediaStorage
+	Polls() IPollsStorage
+	Drafts() IDraftsStorage
+	Reports() IReportsStorage
 }
 
 type IUserStorage interface {
@@ -25,14 +38,31 @@ type IUserStorage interface {
 	UpdatePassword(ctx context.Context, request models.UpdateUserPassword) error
 	GetUserCredentialsByLogin(ctx context.Context, login string) (models.User, error)
 	GetPassword(ctx context.Context, id models.PrimaryKey) (string, error)
+	GetSettings(ctx context.Context, id models.PrimaryKey) (models.UserSettings, error)
+	UpdateSettings(ctx context.Context, request models.UpdateUserSettings) error
+	SetPinnedTweet(ctx context.Context, userID string, tweetID *string) error
+	CanView(ctx context.Context, userID, viewerID string) (bool, error)
+	GetByUsernames(ctx context.Context, usernames []string) ([]models.UserSummary, error)
+	GetTypeaheadCandidates(ctx context.Context, prefix string, limit int) ([]string, error)
+	Typeahead(ctx context.Context, request models.TypeaheadRequest, candidateIDs []string) ([]models.TypeaheadUser, error)
+	GetRole(ctx context.Context, userID string) (string, error)
+	IsSuspended(ctx context.Context, userID string) (bool, error)
 }
 
 type ITweetsStorage interface {
 	Create(context.Context, models.CreateTweet) (string, error)
 	GetByID(context.Context, models.PrimaryKey) (models.Tweet, error)
+	GetByDraftID(ctx context.Context, draftID string) (models.Tweet, error)
 	GetList(context.Context, models.GetListRequest) (models.TweetsResponse, error)
-	Update(context.Context, models.UpdateTweet) (string, error)
+	Search(context.Context, search.Query, models.GetListRequest) (models.TweetsResponse, error)
+	Update(ctx context.Context, tweet models.UpdateTweet, window time.Duration, maxEdits int) (string, error)
+	GetRevisions(ctx context.Context, tweetID string) ([]models.TweetRevision, error)
+	UpdateReplyAudience(context.Context, models.UpdateReplyAudience) error
+	GetRepliableIDs(ctx context.Context, tweetIDs []string, userID string) ([]string, error)
 	Delete(context.Context, models.PrimaryKey) error
+	GetMentions(context.Context, models.GetListRequest) (models.TweetsResponse, error)
+	GetAudience(ctx context.Context, tweetID string) ([]string, error)
+	GetCounters(ctx context.Context, tweetID string) (models.TweetCounters, error)
 }
 
 type IFollowersStorage interface {
@@ -40,15 +70,173 @@ type IFollowersStorage interface {
 	GetByID(context.Context, models.PrimaryKey) (models.Follower, error)
 	GetList(context.Context, models.GetListRequest) (models.FollowersResponse, error)
 	Delete(context.Context, models.PrimaryKey) error
+	DeleteByUsers(context.Context, models.CreateFollower) error
+	GetFollowers(context.Context, models.GetListRequest) (models.UserSummariesResponse, error)
+	GetFollowing(context.Context, models.GetListRequest) (models.UserSummariesResponse, error)
+	GetMutuals(context.Context, models.GetListRequest) (models.UserSummariesResponse, error)
+	GetRelationship(context.Context, models.RelationshipRequest) (models.Relationship, error)
 }
 
 type ILikesStorage interface {
 	Create(context.Context, models.CreateLike) (string, error)
+	Upsert(context.Context, models.CreateLike) (string, error)
 	GetByID(context.Context, models.PrimaryKey) (models.Like, error)
 	Delete(context.Context, models.PrimaryKey) error
+	DeleteByTweetAndUser(context.Context, models.CreateLike) error
+	GetLikingUsers(context.Context, models.GetListRequest) (models.UserSummariesResponse, error)
+	GetLikedTweets(context.Context, models.GetListRequest) (models.TweetsResponse, error)
 }
 
 type IRetweetsStorage interface {
 	Create(context.Context, models.CreateRetweet) (string, error)
+	Upsert(context.Context, models.CreateRetweet) (string, error)
+	GetByID(context.Context, models.PrimaryKey) (models.Retweet, error)
 	Delete(context.Context, models.PrimaryKey) error
-}
\ No newline at end of file
+	DeleteByTweetAndUser(context.Context, models.CreateRetweet) error
+	GetRetweetingUsers(context.Context, models.GetListRequest) (models.UserSummariesResponse, error)
+}
+
+type IFollowRequestsStorage interface {
+	Create(context.Context, models.CreateFollower) (string, error)
+	GetByID(context.Context, models.PrimaryKey) (models.FollowRequest, error)
+	GetIncoming(context.Context, models.GetListRequest) (models.FollowRequestsResponse, error)
+	GetOutgoing(context.Context, models.GetListRequest) (models.FollowRequestsResponse, error)
+	Accept(context.Context, models.PrimaryKey) error
+	AcceptAll(context.Context, models.PrimaryKey) error
+	Delete(context.Context, models.PrimaryKey) error
+}
+
+type IBlocksStorage interface {
+	Create(context.Context, models.CreateBlock) (string, error)
+	GetByID(context.Context, models.PrimaryKey) (models.Block, error)
+	GetList(context.Context, models.GetListRequest) (models.UserSummariesResponse, error)
+	Delete(context.Context, models.CreateBlock) error
+	Exists(ctx context.Context, userID, otherUserID string) (bool, error)
+}
+
+type IMutesStorage interface {
+	CreateUserMute(context.Context, models.CreateMute) (string, error)
+	GetUserMuteByID(context.Context, models.PrimaryKey) (models.Mute, error)
+	GetMutedUsers(context.Context, models.GetListRequest) (models.UserSummariesResponse, error)
+	DeleteUserMute(context.Context, models.CreateMute) error
+	IsUserMuted(ctx context.Context, userID, mutedUserID string) (bool, error)
+
+	CreateKeyword(context.Context, models.CreateMutedKeyword) (string, error)
+	GetKeywordByID(context.Context, models.PrimaryKey) (models.MutedKeyword, error)
+	GetKeywords(context.Context, models.GetListRequest) (models.MutedKeywordsResponse, error)
+	DeleteKeyword(ctx context.Context, key models.PrimaryKey, userID string) error
+
+	CreateConversationMute(context.Context, models.CreateMutedConversation) (string, error)
+	GetConversationMuteByID(context.Context, models.PrimaryKey) (models.MutedConversation, error)
+	DeleteConversationMute(context.Context, models.CreateMutedConversation) error
+	IsConversationMuted(ctx context.Context, userID, conversationID string) (bool, error)
+}
+
+type INotificationsStorage interface {
+	Create(context.Context, models.CreateNotification) (bool, error)
+	Delete(context.Context, models.CreateNotification) error
+	GetList(context.Context, models.GetListRequest) (models.NotificationsResponse, error)
+	MarkRead(context.Context, models.MarkNotificationsRead) error
+	CountUnread(ctx context.Context, userID string) (int, error)
+}
+
+type IMessagesStorage interface {
+	CreateConversation(context.Context, models.CreateConversation) (string, error)
+	GetConversation(ctx context.Context, conversationID, userID string) (models.Conversation, error)
+	GetConversations(context.Context, models.GetListRequest) (models.ConversationsResponse, error)
+	GetMemberIDs(ctx context.Context, conversationID string) ([]string, error)
+	CanMessage(ctx context.Context, userID, senderID string) (bool, error)
+	CreateMessage(context.Context, models.CreateMessage) (string, error)
+	GetMessageByID(context.Context, models.PrimaryKey) (models.Message, error)
+	GetMessages(context.Context, models.GetMessagesRequest) (models.MessagesResponse, error)
+	MarkRead(ctx context.Context, conversationID, userID string) error
+	Leave(ctx context.Context, conversationID, userID string) error
+}
+
+type IBookmarksStorage interface {
+	Upsert(context.Context, models.CreateBookmark) (string, error)
+	GetByID(context.Context, models.PrimaryKey) (models.Bookmark, error)
+	Delete(context.Context, models.CreateBookmark) error
+	GetList(context.Context, models.GetBookmarksRequest) (models.BookmarksResponse, error)
+	GetBookmarkedTweetIDs(ctx context.Context, userID string, tweetIDs []string) ([]string, error)
+
+	CreateFolder(context.Context, models.CreateBookmarkFolder) (string, error)
+	GetFolderByID(context.Context, models.PrimaryKey) (models.BookmarkFolder, error)
+	GetFolders(ctx context.Context, userID string) (models.BookmarkFoldersResponse, error)
+	UpdateFolder(context.Context, models.UpdateBookmarkFolder) error
+	DeleteFolder(ctx context.Context, key models.PrimaryKey, userID string) error
+	ReorderFolders(context.Context, models.ReorderBookmarkFolders) error
+}
+
+type IListsStorage interface {
+	Create(context.Context, models.CreateList) (string, error)
+	GetByID(ctx context.Context, listID, viewerID string) (models.List, error)
+	Update(context.Context, models.UpdateList) error
+	Delete(ctx context.Context, key models.PrimaryKey, ownerID string) error
+	GetUserLists(context.Context, models.GetListRequest) (models.ListsResponse, error)
+	GetSubscribedLists(context.Context, models.GetListRequest) (models.ListsResponse, error)
+
+	AddMember(context.Context, models.ListMember) error
+	RemoveMember(context.Context, models.ListMember) error
+	GetMembers(context.Context, models.GetListRequest) (models.UserSummariesResponse, error)
+
+	Subscribe(context.Context, models.ListMember) error
+	Unsubscribe(context.Context, models.ListMember) error
+
+	GetTimeline(context.Context, models.GetListRequest) (models.TweetsResponse, error)
+}
+
+type ISuggestionsStorage interface {
+	Refresh(ctx context.Context, userID string) error
+	IsComputed(ctx context.Context, userID string) (bool, error)
+	GetStaleUsers(ctx context.Context, computedBefore time.Time, limit int) ([]string, error)
+	GetList(context.Context, models.GetListRequest) (models.UserSuggestionsResponse, error)
+}
+
+type IMediaStorage interface {
+	Create(context.Context, models.CreateMedia) (string, error)
+	GetByID(context.Context, models.PrimaryKey) (models.Media, error)
+	GetByIDs(ctx context.Context, ids []string) ([]models.Media, error)
+	GetByTweetIDs(ctx context.Context, tweetIDs []string) ([]models.Media, error)
+	AddChunk(context.Context, models.MediaChunk) error
+	GetChunks(ctx context.Context, mediaID string) ([]models.MediaChunk, error)
+	Complete(context.Context, models.CompleteMedia) error
+	UpdateAltText(context.Context, models.UpdateMediaAltText) error
+	ClaimProcessing(ctx context.Context, limit int, lease time.Duration) ([]models.Media, error)
+	FinishProcessing(context.Context, models.ProcessedMedia) (string, error)
+	RetryProcessing(ctx context.Context, mediaID, reason string, retryAt time.Time) error
+	Fail(ctx context.Context, mediaID, reason string) error
+	SetProfileMedia(ctx context.Context, userID, purpose string, mediaID *string) (string, error)
+	GetProfileMedia(ctx context.Context, userID, purpose string) (models.Media, error)
+	Delete(context.Context, models.PrimaryKey) error
+}
+
+type IPollsStorage interface {
+	GetByTweetIDs(ctx context.Context, tweetIDs []string, viewerID string) ([]models.Poll, error)
+	Vote(context.Context, models.CreatePollVote) (bool, error)
+	Close(ctx context.Context, limit int) ([]string, error)
+	GetVoterIDs(ctx context.Context, tweetID string) ([]string, error)
+}
+
+type IDraftsStorage interface {
+	Create(context.Context, models.CreateDraft) (string, error)
+	GetByID(context.Context, models.PrimaryKey) (models.Draft, error)
+	GetList(context.Context, models.GetDraftsRequest) (models.DraftsResponse, error)
+	Update(context.Context, models.UpdateDraft) error
+	Delete(ctx context.Context, key models.PrimaryKey, userID string) error
+	Claim(ctx context.Context, key models.PrimaryKey, userID string, lease time.Duration) (models.Draft, error)
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]models.Draft, error)
+	RetryPublishing(ctx context.Context, draftID, reason string, retryAt time.Time) error
+	Fail(ctx context.Context, draftID, reason string) error
+	Published(ctx context.Context, draftID string) error
+}
+
+type IReportsStorage interface {
+	Create(context.Context, models.CreateReport) (string, error)
+	GetByID(context.Context, models.PrimaryKey) (models.Report, error)
+	GetList(context.Context, models.GetReportsRequest) (models.ReportsResponse, error)
+	Claim(ctx context.Context, reportID, moderatorID string, lease time.Duration) (models.Report, error)
+	ClaimNext(ctx context.Context, moderatorID string, lease time.Duration) (models.Report, error)
+	Resolve(context.Context, models.ModerationAction) error
+	GetActions(context.Context, models.GetModerationActionsRequest) (models.ModerationActionsResponse, error)
+}